@@ -1,7 +1,13 @@
 package cleanup
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/bmf/yagwt/internal/config"
+	"github.com/bmf/yagwt/internal/errors"
 )
 
 // Workspace represents a workspace for cleanup evaluation
@@ -30,6 +36,7 @@ type EphemeralInfo struct {
 
 // Activity represents workspace activity
 type Activity interface {
+	GetLastOpenedAt() *time.Time
 	GetLastGitActivityAt() *time.Time
 }
 
@@ -52,88 +59,94 @@ type RemovalReason struct {
 	Message string
 }
 
-// GetPolicy returns a named policy
-func GetPolicy(name string) Policy {
-	switch name {
-	case "conservative":
-		return &ConservativePolicy{}
-	case "aggressive":
-		return &AggressivePolicy{}
-	default:
-		return &DefaultPolicy{}
+// GetPolicy returns the named policy from the configured cleanup policies.
+// An empty name selects "default"; unknown names are a config error.
+func GetPolicy(name string, policies map[string]config.CleanupPolicy) (*ConfigurablePolicy, error) {
+	if name == "" {
+		name = "default"
 	}
-}
 
-// DefaultPolicy is the balanced default cleanup policy
-type DefaultPolicy struct{}
+	cfg, ok := policies[name]
+	if !ok {
+		names := make([]string, 0, len(policies))
+		for n := range policies {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+
+		return nil, errors.NewError(errors.ErrConfig, "unknown cleanup policy").
+			WithDetail("policy", name).
+			WithDetail("available", strings.Join(names, ", ")).
+			WithHint("Define it under [cleanup.policies."+name+"] in .yagwt/config.toml", "")
+	}
 
-func (p *DefaultPolicy) Name() string {
-	return "default"
+	return NewConfigurablePolicy(name, cfg), nil
 }
 
-func (p *DefaultPolicy) Evaluate(ws Workspace) (RemovalReason, bool) {
-	flags := ws.GetFlags()
+// ConfigurablePolicy evaluates workspaces using rules from a
+// [cleanup.policies.<name>] config section
+type ConfigurablePolicy struct {
+	name string
+	cfg  config.CleanupPolicy
+}
 
-	// Skip pinned workspaces
-	if flags.IsPinned() {
-		return RemovalReason{}, false
-	}
+// NewConfigurablePolicy creates a policy from config
+func NewConfigurablePolicy(name string, cfg config.CleanupPolicy) *ConfigurablePolicy {
+	return &ConfigurablePolicy{name: name, cfg: cfg}
+}
 
-	// Skip locked workspaces
-	if flags.IsLocked() {
-		return RemovalReason{}, false
-	}
+func (p *ConfigurablePolicy) Name() string {
+	return p.name
+}
 
-	// Remove expired ephemeral workspaces
-	if flags.IsEphemeral() {
-		ephemeral := ws.GetEphemeral()
-		if ephemeral != nil && time.Now().After(ephemeral.ExpiresAt) {
-			return RemovalReason{
-				Code:    "expired_ephemeral",
-				Message: "Ephemeral workspace has expired",
-			}, true
-		}
+// OnDirty returns the policy's strategy for dirty workspaces
+func (p *ConfigurablePolicy) OnDirty() string {
+	if p.cfg.OnDirty == "" {
+		return "fail"
 	}
+	return p.cfg.OnDirty
+}
 
-	// Remove workspaces idle for more than 30 days (not dirty)
-	activity := ws.GetActivity()
-	lastActivity := activity.GetLastGitActivityAt()
-	if lastActivity != nil {
-		idleTime := time.Since(*lastActivity)
-		status := ws.GetStatus()
-		if idleTime > 30*24*time.Hour && !status.IsDirty() {
-			return RemovalReason{
-				Code:    "idle_30d",
-				Message: "Workspace idle for more than 30 days",
-			}, true
-		}
-	}
+// DeleteBranch reports whether removals should also delete merged branches
+func (p *ConfigurablePolicy) DeleteBranch() bool {
+	return p.cfg.DeleteBranch
+}
 
-	return RemovalReason{}, false
+// RespectPinned reports whether pinned workspaces are kept out of plans.
+// Policies that leave respectPinned out keep them; only an explicit false
+// lets a policy remove them.
+func (p *ConfigurablePolicy) RespectPinned() bool {
+	return p.cfg.RespectPinned == nil || *p.cfg.RespectPinned
 }
 
-// ConservativePolicy only removes expired ephemeral workspaces
-type ConservativePolicy struct{}
+// Match returns the filter expression limiting which workspaces the
+// policy considers; empty means all of them
+func (p *ConfigurablePolicy) Match() string {
+	return p.cfg.Match
+}
 
-func (p *ConservativePolicy) Name() string {
-	return "conservative"
+// Trash reports whether removals should move workspaces to the trash,
+// falling back to the repository setting when the policy does not say
+func (p *ConfigurablePolicy) Trash(repoDefault bool) bool {
+	if p.cfg.Trash == nil {
+		return repoDefault
+	}
+	return *p.cfg.Trash
 }
 
-func (p *ConservativePolicy) Evaluate(ws Workspace) (RemovalReason, bool) {
+func (p *ConfigurablePolicy) Evaluate(ws Workspace) (RemovalReason, bool) {
 	flags := ws.GetFlags()
 
-	// Skip pinned workspaces
-	if flags.IsPinned() {
+	if p.RespectPinned() && flags.IsPinned() {
 		return RemovalReason{}, false
 	}
 
-	// Skip locked workspaces
+	// Locked workspaces are never removed, regardless of policy
 	if flags.IsLocked() {
 		return RemovalReason{}, false
 	}
 
-	// Only remove expired ephemeral workspaces
-	if flags.IsEphemeral() {
+	if p.cfg.RemoveEphemeral && flags.IsEphemeral() {
 		ephemeral := ws.GetEphemeral()
 		if ephemeral != nil && time.Now().After(ephemeral.ExpiresAt) {
 			return RemovalReason{
@@ -143,52 +156,66 @@ func (p *ConservativePolicy) Evaluate(ws Workspace) (RemovalReason, bool) {
 		}
 	}
 
-	return RemovalReason{}, false
-}
-
-// AggressivePolicy removes stale workspaces more aggressively
-type AggressivePolicy struct{}
-
-func (p *AggressivePolicy) Name() string {
-	return "aggressive"
-}
-
-func (p *AggressivePolicy) Evaluate(ws Workspace) (RemovalReason, bool) {
-	flags := ws.GetFlags()
+	threshold := time.Duration(p.cfg.IdleThreshold)
+	if threshold <= 0 {
+		return RemovalReason{}, false
+	}
 
-	// Skip pinned workspaces
-	if flags.IsPinned() {
+	lastActivity := latestActivity(ws.GetActivity())
+	if lastActivity == nil || time.Since(*lastActivity) <= threshold {
 		return RemovalReason{}, false
 	}
 
-	// Skip locked workspaces (even in aggressive mode)
-	if flags.IsLocked() {
+	// A dirty workspace could only be removed by failing, so leave it out
+	// of the plan unless the policy has a strategy for preserving changes
+	if p.OnDirty() == "fail" && ws.GetStatus().IsDirty() {
 		return RemovalReason{}, false
 	}
 
-	// Remove expired ephemeral workspaces
-	if flags.IsEphemeral() {
-		ephemeral := ws.GetEphemeral()
-		if ephemeral != nil && time.Now().After(ephemeral.ExpiresAt) {
-			return RemovalReason{
-				Code:    "expired_ephemeral",
-				Message: "Ephemeral workspace has expired",
-			}, true
-		}
+	return RemovalReason{
+		Code:    "idle_" + formatThreshold(threshold),
+		Message: "Workspace idle for more than " + describeThreshold(threshold),
+	}, true
+}
+
+// latestActivity returns the most recent of the opened and git activity times
+func latestActivity(a Activity) *time.Time {
+	opened, git := a.GetLastOpenedAt(), a.GetLastGitActivityAt()
+	if opened != nil && (git == nil || opened.After(*git)) {
+		return opened
 	}
+	return git
+}
 
-	// Remove workspaces idle for more than 7 days
-	activity := ws.GetActivity()
-	lastActivity := activity.GetLastGitActivityAt()
-	if lastActivity != nil {
-		idleTime := time.Since(*lastActivity)
-		if idleTime > 7*24*time.Hour {
-			return RemovalReason{
-				Code:    "idle_7d",
-				Message: "Workspace idle for more than 7 days",
-			}, true
-		}
+// formatThreshold renders a threshold compactly, e.g. "30d" or "12h"
+func formatThreshold(d time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case d%day == 0:
+		return fmt.Sprintf("%dd", d/day)
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return d.String()
 	}
+}
 
-	return RemovalReason{}, false
+// describeThreshold renders a threshold for human-readable messages
+func describeThreshold(d time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case d%day == 0:
+		return pluralize(int(d/day), "day")
+	case d%time.Hour == 0:
+		return pluralize(int(d/time.Hour), "hour")
+	default:
+		return d.String()
+	}
+}
+
+func pluralize(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
 }
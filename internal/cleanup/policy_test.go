@@ -3,6 +3,9 @@ package cleanup
 import (
 	"testing"
 	"time"
+
+	"github.com/bmf/yagwt/internal/config"
+	"github.com/bmf/yagwt/internal/errors"
 )
 
 // mockFlags implements Flags interface for testing
@@ -18,9 +21,14 @@ func (f *mockFlags) IsEphemeral() bool { return f.ephemeral }
 
 // mockActivity implements Activity interface for testing
 type mockActivity struct {
+	lastOpenedAt      *time.Time
 	lastGitActivityAt *time.Time
 }
 
+func (a *mockActivity) GetLastOpenedAt() *time.Time {
+	return a.lastOpenedAt
+}
+
 func (a *mockActivity) GetLastGitActivityAt() *time.Time {
 	return a.lastGitActivityAt
 }
@@ -57,45 +65,96 @@ func timePtr(t time.Time) *time.Time {
 	return &t
 }
 
+func defaultPolicies() map[string]config.CleanupPolicy {
+	return config.DefaultConfig().Cleanup.Policies
+}
+
+func mustGetPolicy(t *testing.T, name string) *ConfigurablePolicy {
+	t.Helper()
+	policy, err := GetPolicy(name, defaultPolicies())
+	if err != nil {
+		t.Fatalf("GetPolicy(%q) failed: %v", name, err)
+	}
+	return policy
+}
+
 func TestGetPolicy(t *testing.T) {
+	policies := defaultPolicies()
+	policies["ci"] = config.CleanupPolicy{
+		RemoveEphemeral: true,
+		IdleThreshold:   config.Duration(2 * 24 * time.Hour),
+		OnDirty:         "wip-commit",
+	}
+
 	tests := []struct {
-		name       string
-		policyName string
-		wantName   string
+		name        string
+		policyName  string
+		wantName    string
+		wantOnDirty string
+		wantErr     bool
 	}{
 		{
-			name:       "default policy",
-			policyName: "default",
-			wantName:   "default",
+			name:        "default policy",
+			policyName:  "default",
+			wantName:    "default",
+			wantOnDirty: "fail",
 		},
 		{
-			name:       "conservative policy",
-			policyName: "conservative",
-			wantName:   "conservative",
+			name:        "conservative policy",
+			policyName:  "conservative",
+			wantName:    "conservative",
+			wantOnDirty: "fail",
 		},
 		{
-			name:       "aggressive policy",
-			policyName: "aggressive",
-			wantName:   "aggressive",
+			name:        "aggressive policy",
+			policyName:  "aggressive",
+			wantName:    "aggressive",
+			wantOnDirty: "stash",
 		},
 		{
-			name:       "unknown returns default",
-			policyName: "unknown",
-			wantName:   "default",
+			name:        "custom configured policy",
+			policyName:  "ci",
+			wantName:    "ci",
+			wantOnDirty: "wip-commit",
+		},
+		{
+			name:        "empty returns default",
+			policyName:  "",
+			wantName:    "default",
+			wantOnDirty: "fail",
 		},
 		{
-			name:       "empty returns default",
-			policyName: "",
-			wantName:   "default",
+			name:       "unknown is an error",
+			policyName: "unknown",
+			wantErr:    true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			policy := GetPolicy(tt.policyName)
+			policy, err := GetPolicy(tt.policyName, policies)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("GetPolicy(%q) expected error", tt.policyName)
+				}
+				coreErr, ok := err.(*errors.Error)
+				if !ok {
+					t.Fatalf("Expected *errors.Error, got %T", err)
+				}
+				if coreErr.Code != errors.ErrConfig {
+					t.Errorf("Expected error code %s, got %s", errors.ErrConfig, coreErr.Code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPolicy(%q) failed: %v", tt.policyName, err)
+			}
 			if policy.Name() != tt.wantName {
 				t.Errorf("GetPolicy(%q).Name() = %q, want %q", tt.policyName, policy.Name(), tt.wantName)
 			}
+			if policy.OnDirty() != tt.wantOnDirty {
+				t.Errorf("GetPolicy(%q).OnDirty() = %q, want %q", tt.policyName, policy.OnDirty(), tt.wantOnDirty)
+			}
 		})
 	}
 }
@@ -216,7 +275,7 @@ func TestDefaultPolicy(t *testing.T) {
 		},
 	}
 
-	policy := &DefaultPolicy{}
+	policy := mustGetPolicy(t, "default")
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			reason, remove := policy.Evaluate(tt.workspace)
@@ -270,21 +329,35 @@ func TestConservativePolicy(t *testing.T) {
 			wantCode:   "expired_ephemeral",
 		},
 		{
-			name: "keep idle workspace (conservative never removes non-ephemeral)",
+			name: "keep workspace idle less than 90 days",
 			workspace: &mockWorkspace{
 				id:    "ws-3",
 				name:  "idle-ws",
 				flags: &mockFlags{},
 				activity: &mockActivity{
-					lastGitActivityAt: timePtr(now.Add(-90 * 24 * time.Hour)), // 90 days ago
+					lastGitActivityAt: timePtr(now.Add(-60 * 24 * time.Hour)), // 60 days ago
 				},
 				status: &mockStatus{},
 			},
 			wantRemove: false,
 		},
+		{
+			name: "remove workspace idle more than 90 days",
+			workspace: &mockWorkspace{
+				id:    "ws-4",
+				name:  "stale-ws",
+				flags: &mockFlags{},
+				activity: &mockActivity{
+					lastGitActivityAt: timePtr(now.Add(-100 * 24 * time.Hour)), // 100 days ago
+				},
+				status: &mockStatus{},
+			},
+			wantRemove: true,
+			wantCode:   "idle_90d",
+		},
 	}
 
-	policy := &ConservativePolicy{}
+	policy := mustGetPolicy(t, "conservative")
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			reason, remove := policy.Evaluate(tt.workspace)
@@ -395,9 +468,118 @@ func TestAggressivePolicy(t *testing.T) {
 		},
 	}
 
-	policy := &AggressivePolicy{}
+	policy := mustGetPolicy(t, "aggressive")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, remove := policy.Evaluate(tt.workspace)
+			if remove != tt.wantRemove {
+				t.Errorf("Evaluate() remove = %v, want %v", remove, tt.wantRemove)
+			}
+			if tt.wantRemove && reason.Code != tt.wantCode {
+				t.Errorf("Evaluate() reason.Code = %q, want %q", reason.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestConfigurablePolicy(t *testing.T) {
+	now := time.Now()
+	idle := &mockActivity{lastGitActivityAt: timePtr(now.Add(-20 * time.Hour))}
+	off := false
+
+	tests := []struct {
+		name       string
+		cfg        config.CleanupPolicy
+		workspace  *mockWorkspace
+		wantRemove bool
+		wantCode   string
+	}{
+		{
+			name: "pinned kept when respectPinned is unset",
+			cfg:  config.CleanupPolicy{IdleThreshold: config.Duration(12 * time.Hour)},
+			workspace: &mockWorkspace{
+				flags:    &mockFlags{pinned: true},
+				activity: idle,
+				status:   &mockStatus{},
+			},
+			wantRemove: false,
+		},
+		{
+			name: "pinned removed when respectPinned is off",
+			cfg:  config.CleanupPolicy{IdleThreshold: config.Duration(12 * time.Hour), RespectPinned: &off},
+			workspace: &mockWorkspace{
+				flags:    &mockFlags{pinned: true},
+				activity: idle,
+				status:   &mockStatus{},
+			},
+			wantRemove: true,
+			wantCode:   "idle_12h",
+		},
+		{
+			name: "locked kept even when respectPinned is off",
+			cfg:  config.CleanupPolicy{IdleThreshold: config.Duration(12 * time.Hour), RespectPinned: &off},
+			workspace: &mockWorkspace{
+				flags:    &mockFlags{locked: true},
+				activity: idle,
+				status:   &mockStatus{},
+			},
+			wantRemove: false,
+		},
+		{
+			name: "zero idle threshold disables idle removal",
+			cfg:  config.CleanupPolicy{RemoveEphemeral: true},
+			workspace: &mockWorkspace{
+				flags: &mockFlags{},
+				activity: &mockActivity{
+					lastGitActivityAt: timePtr(now.Add(-365 * 24 * time.Hour)),
+				},
+				status: &mockStatus{},
+			},
+			wantRemove: false,
+		},
+		{
+			name: "recently opened workspace is not idle",
+			cfg:  config.CleanupPolicy{IdleThreshold: config.Duration(12 * time.Hour)},
+			workspace: &mockWorkspace{
+				flags: &mockFlags{},
+				activity: &mockActivity{
+					lastOpenedAt:      timePtr(now.Add(-time.Hour)),
+					lastGitActivityAt: timePtr(now.Add(-20 * time.Hour)),
+				},
+				status: &mockStatus{},
+			},
+			wantRemove: false,
+		},
+		{
+			name: "expired ephemeral kept when removeEphemeral is off",
+			cfg:  config.CleanupPolicy{},
+			workspace: &mockWorkspace{
+				flags:     &mockFlags{ephemeral: true},
+				ephemeral: &EphemeralInfo{ExpiresAt: now.Add(-time.Hour)},
+				activity:  &mockActivity{},
+				status:    &mockStatus{},
+			},
+			wantRemove: false,
+		},
+		{
+			name: "dirty idle workspace included when onDirty preserves changes",
+			cfg: config.CleanupPolicy{
+				IdleThreshold: config.Duration(12 * time.Hour),
+				OnDirty:       "patch",
+			},
+			workspace: &mockWorkspace{
+				flags:    &mockFlags{},
+				activity: idle,
+				status:   &mockStatus{dirty: true},
+			},
+			wantRemove: true,
+			wantCode:   "idle_12h",
+		},
+	}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			policy := NewConfigurablePolicy("custom", tt.cfg)
 			reason, remove := policy.Evaluate(tt.workspace)
 			if remove != tt.wantRemove {
 				t.Errorf("Evaluate() remove = %v, want %v", remove, tt.wantRemove)
@@ -408,3 +590,28 @@ func TestAggressivePolicy(t *testing.T) {
 		})
 	}
 }
+
+func TestPolicyTrash(t *testing.T) {
+	enabled, disabled := true, false
+
+	tests := []struct {
+		name        string
+		trash       *bool
+		repoDefault bool
+		want        bool
+	}{
+		{"inherits enabled repo setting", nil, true, true},
+		{"inherits disabled repo setting", nil, false, false},
+		{"policy enables trash", &enabled, false, true},
+		{"policy disables trash", &disabled, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := NewConfigurablePolicy("test", config.CleanupPolicy{Trash: tt.trash})
+			if got := policy.Trash(tt.repoDefault); got != tt.want {
+				t.Errorf("Trash(%v) = %v, want %v", tt.repoDefault, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"github.com/bmf/yagwt/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bootstrapFrom  string
+	bootstrapForce bool
+)
+
+var bootstrapCmd = &cobra.Command{
+	Use:   "bootstrap <selector>",
+	Short: "Copy untracked files into a worktree",
+	Long: `Copy, symlink or hardlink the untracked files listed under
+[workspace.bootstrap] in config (.env, local certs, IDE settings, ...)
+into a worktree. New worktrees are bootstrapped automatically; use this
+to re-run it after changing the config or the source files.
+
+Files are taken from the primary worktree unless workspace.bootstrap.from
+or --from names another workspace. Paths that already exist in the
+worktree are skipped unless --force is given.
+
+Examples:
+  yagwt bootstrap auth
+  yagwt bootstrap auth --from template
+  yagwt bootstrap auth --force`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		selector := core.ParseSelector(args[0])
+
+		result, err := engine.Bootstrap(selector, core.BootstrapOptions{
+			From:  bootstrapFrom,
+			Force: bootstrapForce,
+		})
+		if err != nil {
+			handleError(err)
+		}
+
+		printOutput(formatter.FormatBootstrapResult(result))
+	},
+}
+
+func init() {
+	bootstrapCmd.Flags().StringVar(&bootstrapFrom, "from", "", "workspace to take files from (default: workspace.bootstrap.from or the primary worktree)")
+	bootstrapCmd.Flags().BoolVar(&bootstrapForce, "force", false, "replace paths that already exist in the worktree")
+}
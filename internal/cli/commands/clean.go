@@ -21,10 +21,13 @@ var cleanCmd = &cobra.Command{
 By default, this shows a plan without executing (dry-run mode).
 Use --apply to actually remove worktrees.
 
-Cleanup policies:
+Cleanup policies are defined under [cleanup.policies.<name>] in config.
+Built-in policies:
   - default: Remove expired ephemeral worktrees and idle worktrees (>30 days)
-  - conservative: Only remove expired ephemeral worktrees
-  - aggressive: Remove expired ephemeral and idle worktrees (>7 days)
+  - conservative: Remove expired ephemeral worktrees and idle worktrees (>90 days)
+  - aggressive: Remove expired ephemeral and idle worktrees (>7 days), stashing dirty ones
+
+The policy's onDirty setting is used unless --on-dirty is given.
 
 Examples:
   yagwt clean
@@ -72,9 +75,9 @@ Examples:
 }
 
 func init() {
-	cleanCmd.Flags().StringVar(&cleanPolicy, "policy", "default", "cleanup policy: default, conservative, aggressive")
+	cleanCmd.Flags().StringVar(&cleanPolicy, "policy", "default", "cleanup policy name from config (default, conservative, aggressive, ...)")
 	cleanCmd.Flags().BoolVar(&cleanDryRun, "dry-run", false, "show plan without executing (default)")
 	cleanCmd.Flags().BoolVar(&cleanApply, "apply", false, "execute the cleanup plan")
-	cleanCmd.Flags().StringVar(&cleanOnDirty, "on-dirty", "", "strategy for dirty worktrees: fail, stash, patch, wip-commit, force")
+	cleanCmd.Flags().StringVar(&cleanOnDirty, "on-dirty", "", "strategy for dirty worktrees: fail, stash, patch, wip-commit, force (default: policy onDirty)")
 	cleanCmd.Flags().IntVar(&cleanMax, "max", 0, "maximum worktrees to remove (0 = unlimited)")
 }
@@ -8,6 +8,7 @@ import (
 var (
 	doctorDryRun        bool
 	doctorForgetMissing bool
+	doctorMigrate       bool
 )
 
 var doctorCmd = &cobra.Command{
@@ -19,13 +20,19 @@ This command checks for:
   - Orphaned metadata (metadata without git worktree)
   - Untracked worktrees (git worktree without metadata)
   - Stale index entries
+  - Metadata written with an older schema
 
 By default, this shows issues without fixing them (dry-run mode).
 
+Older metadata is upgraded in place the first time it is written, and the
+original is kept next to it as meta.json.v<N>.bak. Use --migrate to only
+show the planned upgrade without changing anything.
+
 Examples:
   yagwt doctor
   yagwt doctor --forget-missing
-  yagwt doctor --dry-run`,
+  yagwt doctor --dry-run
+  yagwt doctor --migrate`,
 	Run: func(cmd *cobra.Command, args []string) {
 		initFormatter()
 
@@ -38,6 +45,7 @@ Examples:
 		opts := core.DoctorOptions{
 			DryRun:        doctorDryRun,
 			ForgetMissing: doctorForgetMissing,
+			Migrate:       doctorMigrate,
 		}
 
 		// If --forget-missing is set without explicit --dry-run, apply repairs
@@ -51,12 +59,19 @@ Examples:
 			handleError(err)
 		}
 
+		if doctorMigrate && report.Migration == nil {
+			if !quiet {
+				printOutput(formatter.FormatSuccess("Metadata schema is up to date"))
+			}
+			return
+		}
+
 		// Format and print output
 		output := formatter.FormatDoctorReport(report)
 		printOutput(output)
 
 		// Print success message if repairs were applied
-		if !opts.DryRun && !quiet {
+		if !opts.DryRun && !opts.Migrate && !quiet {
 			printOutput(formatter.FormatSuccess("Doctor completed"))
 		}
 	},
@@ -65,4 +80,5 @@ Examples:
 func init() {
 	doctorCmd.Flags().BoolVar(&doctorDryRun, "dry-run", false, "show issues without fixing (default)")
 	doctorCmd.Flags().BoolVar(&doctorForgetMissing, "forget-missing", false, "remove metadata for missing worktrees")
+	doctorCmd.Flags().BoolVar(&doctorMigrate, "migrate", false, "show the planned metadata schema upgrade without applying it")
 }
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"time"
+
+	"github.com/bmf/yagwt/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ensureName      string
+	ensureDir       string
+	ensureBase      string
+	ensureNewBranch bool
+	ensureEphemeral bool
+	ensureTTL       string
+	ensurePin       bool
+)
+
+var ensureCmd = &cobra.Command{
+	Use:   "ensure <branch>",
+	Short: "Return the worktree for a branch, creating it if needed",
+	Long: `Idempotently get or create a worktree.
+
+If a worktree with the given --name, or with the branch checked out,
+already exists it is returned unchanged. Otherwise a new worktree is
+created exactly like 'yagwt new'. Concurrent calls for the same branch
+are serialized, so only one worktree is ever created.
+
+With --new-branch, the branch is created from --base if it does not exist.
+
+Examples:
+  yagwt ensure feature/auth
+  yagwt ensure feature/auth --json
+  yagwt ensure ci-job-42 --new-branch -b main --ephemeral --ttl 1d`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		var ttl time.Duration
+		if ensureTTL != "" {
+			var err error
+			ttl, err = parseDuration(ensureTTL)
+			if err != nil {
+				handleError(err)
+			}
+		}
+
+		opts := core.CreateOptions{
+			Target:    args[0],
+			Name:      ensureName,
+			Dir:       ensureDir,
+			Base:      ensureBase,
+			NewBranch: ensureNewBranch,
+			Ephemeral: ensureEphemeral,
+			TTL:       ttl,
+			Pin:       ensurePin,
+		}
+
+		result, err := engine.Ensure(opts)
+		if err != nil {
+			handleError(err)
+		}
+
+		printOutput(formatter.FormatEnsureResult(result))
+	},
+}
+
+func init() {
+	ensureCmd.Flags().StringVarP(&ensureName, "name", "n", "", "worktree name (default: rendered from workspace.nameTemplate)")
+	ensureCmd.Flags().StringVarP(&ensureDir, "dir", "d", "", "directory path used if the worktree is created")
+	ensureCmd.Flags().StringVarP(&ensureBase, "base", "b", "", "base branch for a new branch")
+	ensureCmd.Flags().BoolVar(&ensureNewBranch, "new-branch", false, "create the branch if it does not exist")
+	ensureCmd.Flags().BoolVar(&ensureEphemeral, "ephemeral", false, "mark as ephemeral if created")
+	ensureCmd.Flags().StringVar(&ensureTTL, "ttl", "", "time-to-live for ephemeral (e.g., '7d', '24h')")
+	ensureCmd.Flags().BoolVar(&ensurePin, "pin", false, "pin if created")
+}
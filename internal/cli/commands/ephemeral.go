@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"time"
+
+	"github.com/bmf/yagwt/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ephemeralTTL     string
+	ephemeralSliding bool
+	renewTTL         string
+)
+
+var ephemeralCmd = &cobra.Command{
+	Use:   "ephemeral <selector>",
+	Short: "Mark a worktree as ephemeral",
+	Long: `Mark a worktree as ephemeral so cleanup removes it once its TTL expires.
+
+Without --ttl, workspace.defaultTTL from config is used (7 days by default).
+With --sliding, any recorded activity pushes the expiry forward by the TTL.
+
+Examples:
+  yagwt ephemeral auth
+  yagwt ephemeral auth --ttl 3d
+  yagwt ephemeral scratch --ttl 12h --sliding`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		var ttl time.Duration
+		if ephemeralTTL != "" {
+			var err error
+			ttl, err = parseDuration(ephemeralTTL)
+			if err != nil {
+				handleError(err)
+			}
+		}
+
+		// Parse selector
+		selector := core.ParseSelector(args[0])
+
+		opts := core.EphemeralOptions{
+			TTL:     ttl,
+			Sliding: ephemeralSliding,
+		}
+		if err := engine.SetEphemeral(selector, opts); err != nil {
+			handleError(err)
+		}
+
+		if !quiet {
+			printOutput(formatter.FormatSuccess("Worktree marked as ephemeral"))
+		}
+	},
+}
+
+var permanentCmd = &cobra.Command{
+	Use:   "permanent <selector>",
+	Short: "Make an ephemeral worktree permanent",
+	Long: `Clear the ephemeral flag and TTL from a worktree.
+
+Examples:
+  yagwt permanent auth`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		// Parse selector
+		selector := core.ParseSelector(args[0])
+
+		if err := engine.SetPermanent(selector); err != nil {
+			handleError(err)
+		}
+
+		if !quiet {
+			printOutput(formatter.FormatSuccess("Worktree is now permanent"))
+		}
+	},
+}
+
+var renewCmd = &cobra.Command{
+	Use:   "renew <selector>",
+	Short: "Restart an ephemeral worktree's TTL",
+	Long: `Restart the TTL of an ephemeral worktree from now.
+
+Without --ttl, the worktree keeps its current TTL. With --ttl, the new
+TTL replaces it.
+
+Examples:
+  yagwt renew scratch
+  yagwt renew scratch --ttl 14d`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		var ttl time.Duration
+		if renewTTL != "" {
+			var err error
+			ttl, err = parseDuration(renewTTL)
+			if err != nil {
+				handleError(err)
+			}
+		}
+
+		// Parse selector
+		selector := core.ParseSelector(args[0])
+
+		if err := engine.Renew(selector, ttl); err != nil {
+			handleError(err)
+		}
+
+		if !quiet {
+			printOutput(formatter.FormatSuccess("Worktree TTL renewed"))
+		}
+	},
+}
+
+func init() {
+	ephemeralCmd.Flags().StringVar(&ephemeralTTL, "ttl", "", "time-to-live (e.g., '7d', '24h'; default: workspace.defaultTTL)")
+	ephemeralCmd.Flags().BoolVar(&ephemeralSliding, "sliding", false, "extend expiry whenever activity is recorded")
+	renewCmd.Flags().StringVar(&renewTTL, "ttl", "", "new time-to-live (default: keep current TTL)")
+}
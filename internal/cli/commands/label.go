@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"github.com/bmf/yagwt/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var labelCmd = &cobra.Command{
+	Use:   "label",
+	Short: "Add or remove worktree labels",
+	Long: `Tag worktrees with free-form labels.
+
+Labels are stored in yagwt metadata and can be matched in filters with
+label:<glob>, e.g. 'yagwt ls --filter label:review'.
+
+Examples:
+  yagwt label add auth review urgent
+  yagwt label rm auth urgent`,
+}
+
+var labelAddCmd = &cobra.Command{
+	Use:   "add <selector> <label>...",
+	Short: "Add labels to a worktree",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		selector := core.ParseSelector(args[0])
+		if err := engine.AddLabels(selector, args[1:]); err != nil {
+			handleError(err)
+		}
+
+		if !quiet {
+			printOutput(formatter.FormatSuccess("Labels added"))
+		}
+	},
+}
+
+var labelRmCmd = &cobra.Command{
+	Use:   "rm <selector> <label>...",
+	Short: "Remove labels from a worktree",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		selector := core.ParseSelector(args[0])
+		if err := engine.RemoveLabels(selector, args[1:]); err != nil {
+			handleError(err)
+		}
+
+		if !quiet {
+			printOutput(formatter.FormatSuccess("Labels removed"))
+		}
+	},
+}
+
+func init() {
+	labelCmd.AddCommand(labelAddCmd)
+	labelCmd.AddCommand(labelRmCmd)
+}
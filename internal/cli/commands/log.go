@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"github.com/bmf/yagwt/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var logLimit int
+
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show the operation journal",
+	Long: `List recorded destructive operations, newest first.
+
+Removals (rm and clean --apply), renames and doctor --forget-missing are
+journaled with the previous metadata, the HEAD commit and any stash, patch
+or WIP commit created for uncommitted changes. Use the SEQ column with
+yagwt undo.
+
+Examples:
+  yagwt log
+  yagwt log -n 5
+  yagwt log --json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		entries, err := engine.Log(core.LogOptions{Limit: logLimit})
+		if err != nil {
+			handleError(err)
+		}
+
+		printOutput(formatter.FormatJournal(entries))
+	},
+}
+
+func init() {
+	logCmd.Flags().IntVarP(&logLimit, "limit", "n", 0, "show only the newest N entries")
+}
@@ -8,6 +8,7 @@ import (
 var (
 	lsFilter string
 	lsAll    bool
+	lsFields []string
 )
 
 var lsCmd = &cobra.Command{
@@ -19,12 +20,23 @@ Supports filtering with expressions like:
   flag:pinned          - Show only pinned worktrees
   flag:ephemeral       - Show only ephemeral worktrees
   status:dirty         - Show only dirty worktrees
+  name:feature-*       - Match names against a glob
+  name~^feat-          - Match names against a regular expression
+  ahead>3, age>14d     - Compare commit counts and durations
+
+Combine terms with and/or/not and parentheses, e.g.
+  not flag:pinned and (status:dirty or ahead>0)
+
+--fields limits output to the given fields (e.g. name,path,status.dirty).
+A group such as "status" selects all of its fields. Git status is only
+collected when a requested field or the filter needs it.
 
 Examples:
   yagwt ls
   yagwt ls --json
   yagwt ls --filter "flag:pinned"
-  yagwt ls flag:ephemeral`,
+  yagwt ls flag:ephemeral
+  yagwt ls --fields name,path --porcelain`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		initFormatter()
@@ -44,12 +56,22 @@ Examples:
 		workspaces, err := engine.List(core.ListOptions{
 			Filter: filter,
 			All:    lsAll,
+			Fields: lsFields,
 		})
 		if err != nil {
 			handleError(err)
 		}
 
 		// Format and print output
+		if len(lsFields) > 0 {
+			fields, err := core.ExpandFields(lsFields)
+			if err != nil {
+				handleError(err)
+			}
+			printOutput(formatter.FormatWorkspaceFields(workspaces, fields))
+			return
+		}
+
 		output := formatter.FormatWorkspaces(workspaces)
 		printOutput(output)
 	},
@@ -58,4 +80,5 @@ Examples:
 func init() {
 	lsCmd.Flags().StringVarP(&lsFilter, "filter", "f", "", "filter expression")
 	lsCmd.Flags().BoolVarP(&lsAll, "all", "a", false, "show all worktrees including broken")
+	lsCmd.Flags().StringSliceVar(&lsFields, "fields", nil, "comma-separated fields to output (e.g. name,path,status.dirty)")
 }
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/bmf/yagwt/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var metaSetString bool
+
+var metaCmd = &cobra.Command{
+	Use:   "meta",
+	Short: "Set or unset custom worktree fields",
+	Long: `Attach free-form key=value fields to worktrees, such as a ticket id
+or an owner.
+
+Values "true" and "false" are stored as booleans and plain numbers as
+numbers, unless --string is given. Fields can be matched in filters with
+meta.<key>, e.g. 'meta.ticket=ABC-123' or 'meta.estimate>3'.
+
+Examples:
+  yagwt meta set auth ticket=ABC-123 estimate=5
+  yagwt meta set auth --string build=0042
+  yagwt meta unset auth estimate`,
+}
+
+var metaSetCmd = &cobra.Command{
+	Use:   "set <selector> <key=value>...",
+	Short: "Set custom fields on a worktree",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		fields := make(map[string]interface{}, len(args)-1)
+		for _, arg := range args[1:] {
+			key, value, ok := strings.Cut(arg, "=")
+			if !ok {
+				handleError(core.NewError(core.ErrConfig, "invalid field assignment").
+					WithDetail("arg", arg).
+					WithHint("Use key=value", ""))
+			}
+			if metaSetString {
+				fields[key] = value
+			} else {
+				fields[key] = core.ParseFieldValue(value)
+			}
+		}
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		selector := core.ParseSelector(args[0])
+		if err := engine.SetFields(selector, fields); err != nil {
+			handleError(err)
+		}
+
+		if !quiet {
+			printOutput(formatter.FormatSuccess("Fields updated"))
+		}
+	},
+}
+
+var metaUnsetCmd = &cobra.Command{
+	Use:   "unset <selector> <key>...",
+	Short: "Remove custom fields from a worktree",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		selector := core.ParseSelector(args[0])
+		if err := engine.UnsetFields(selector, args[1:]); err != nil {
+			handleError(err)
+		}
+
+		if !quiet {
+			printOutput(formatter.FormatSuccess("Fields removed"))
+		}
+	},
+}
+
+func init() {
+	metaSetCmd.Flags().BoolVar(&metaSetString, "string", false, "store all values as strings")
+
+	metaCmd.AddCommand(metaSetCmd)
+	metaCmd.AddCommand(metaUnsetCmd)
+}
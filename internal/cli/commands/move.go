@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"github.com/bmf/yagwt/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var (
+	moveDir string
+)
+
+var moveCmd = &cobra.Command{
+	Use:   "move <selector> --dir=<path>",
+	Short: "Move a worktree to a new directory",
+	Long: `Move a worktree to a different directory.
+
+The git worktree is relocated with 'git worktree move' and the workspace
+metadata is updated to the new path. ID, name, flags and activity are kept.
+
+Locked worktrees and worktrees with unresolved merge conflicts cannot be moved.
+
+Examples:
+  yagwt move auth --dir=../worktrees/auth
+  yagwt move name:feature-x --dir=/tmp/feature-x`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		// Parse selector
+		selector := core.ParseSelector(args[0])
+
+		// Move worktree
+		if err := engine.Move(selector, moveDir); err != nil {
+			handleError(err)
+		}
+
+		// Print success message
+		if !quiet {
+			printOutput(formatter.FormatSuccess("Worktree moved successfully"))
+		}
+	},
+}
+
+func init() {
+	moveCmd.Flags().StringVarP(&moveDir, "dir", "d", "", "destination directory")
+	moveCmd.MarkFlagRequired("dir")
+}
@@ -25,6 +25,10 @@ var (
 	newPin         bool
 	newNoCheckout  bool
 	newInteractive bool
+
+	newSparse        []string
+	newSparseProfile string
+	newNoCone        bool
 )
 
 var newCmd = &cobra.Command{
@@ -44,13 +48,15 @@ Examples:
   yagwt new feature/auth                 # Checkout existing branch
   yagwt new feature/auth --name auth     # With custom name
   yagwt new my-feature --new-branch -b main  # Create new branch from main
-  yagwt new abc123 --detach              # Detached HEAD at commit`,
+  yagwt new abc123 --detach              # Detached HEAD at commit
+  yagwt new feature/ui --sparse-profile frontend  # Sparse checkout from a config profile
+  yagwt new feature/ui --sparse apps/web,libs/ui  # Sparse checkout of two directories`,
 	Args: cobra.MaximumNArgs(1),
 	Run:  runNew,
 }
 
 func init() {
-	newCmd.Flags().StringVarP(&newName, "name", "n", "", "worktree name (default: derived from target)")
+	newCmd.Flags().StringVarP(&newName, "name", "n", "", "worktree name (default: rendered from workspace.nameTemplate)")
 	newCmd.Flags().StringVarP(&newDir, "dir", "d", "", "directory path (default: sibling to repo)")
 	newCmd.Flags().StringVarP(&newBase, "base", "b", "", "base branch for new branch")
 	newCmd.Flags().BoolVar(&newNewBranch, "new-branch", false, "create new branch")
@@ -59,6 +65,9 @@ func init() {
 	newCmd.Flags().StringVar(&newTTL, "ttl", "", "time-to-live for ephemeral (e.g., '7d', '24h')")
 	newCmd.Flags().BoolVar(&newPin, "pin", false, "pin to prevent cleanup")
 	newCmd.Flags().BoolVar(&newNoCheckout, "no-checkout", false, "don't checkout after creation")
+	newCmd.Flags().StringSliceVar(&newSparse, "sparse", nil, "sparse-checkout pattern (repeatable or comma-separated)")
+	newCmd.Flags().StringVar(&newSparseProfile, "sparse-profile", "", "sparse-checkout profile from [sparse.profiles] in config")
+	newCmd.Flags().BoolVar(&newNoCone, "no-cone", false, "treat sparse patterns as gitignore-style patterns instead of directories")
 	newCmd.Flags().BoolVarP(&newInteractive, "interactive", "i", false, "run in interactive mode")
 }
 
@@ -86,24 +95,31 @@ func runNew(cmd *cobra.Command, args []string) {
 	}
 
 	opts := core.CreateOptions{
-		Target:    args[0],
-		Name:      newName,
-		Dir:       newDir,
-		Base:      newBase,
-		NewBranch: newNewBranch,
-		Detached:  newDetach,
-		Ephemeral: newEphemeral,
-		TTL:       ttl,
-		Pin:       newPin,
-		Checkout:  !newNoCheckout,
+		Target:        args[0],
+		Name:          newName,
+		Dir:           newDir,
+		Base:          newBase,
+		NewBranch:     newNewBranch,
+		Detached:      newDetach,
+		Ephemeral:     newEphemeral,
+		TTL:           ttl,
+		Pin:           newPin,
+		NoCheckout:    newNoCheckout,
+		SparseProfile: newSparseProfile,
+		Sparse:        newSparse,
+		NoCone:        newNoCone,
 	}
 
-	worktree, err := engine.Create(opts)
+	progress := newSeedProgress()
+	opts.SeedProgress = progress.report
+
+	result, err := engine.Create(opts)
+	progress.done()
 	if err != nil {
 		handleError(err)
 	}
 
-	output := formatter.FormatWorkspace(worktree)
+	output := formatter.FormatCreateResult(result)
 	printOutput(output)
 
 	if !quiet && !jsonOutput && !porcelain {
@@ -236,13 +252,13 @@ func runNewInteractive() {
 		Detached:  isDetached,
 		Ephemeral: ephemeral,
 		TTL:       ttl,
-		Checkout:  true,
 	}
 
-	worktree, err := engine.Create(opts)
+	result, err := engine.Create(opts)
 	if err != nil {
 		handleError(err)
 	}
+	worktree := result.Workspace
 
 	fmt.Printf("Created worktree: %s\n", worktree.Path)
 	fmt.Println()
@@ -28,8 +28,8 @@ Examples:
 		// Parse selector
 		selector := core.ParseSelector(args[0])
 
-		// Get worktree
-		worktree, err := engine.Get(selector)
+		// Look up worktree (git status isn't needed for the path)
+		worktree, err := engine.Locate(selector)
 		if err != nil {
 			handleError(err)
 		}
@@ -6,12 +6,15 @@ import (
 )
 
 var (
-	rmDeleteBranch bool
-	rmKeepBranch   bool
-	rmOnDirty      string
-	rmPatchDir     string
-	rmWipMessage   string
-	rmForce        bool
+	rmDeleteBranch      bool
+	rmForceDeleteBranch bool
+	rmKeepBranch        bool
+	rmOnDirty           string
+	rmPatchDir          string
+	rmWipMessage        string
+	rmForce             bool
+	rmTrash             bool
+	rmNoTrash           bool
 )
 
 var rmCmd = &cobra.Command{
@@ -27,10 +30,21 @@ Use --on-dirty to specify how to handle dirty worktrees:
   - wip-commit: Create a WIP commit
   - force: Discard changes (dangerous!)
 
+With --delete-branch, the branch is only deleted if it is merged into its
+upstream or the base branch (workspace.baseBranch, default: the primary
+worktree's branch). Use --force-delete-branch to delete unmerged branches.
+A branch checked out in another worktree is never deleted.
+
+With --trash (or trash.enabled = true in config), the worktree is moved to
+the trash with all its files instead of being deleted; --on-dirty is not
+needed. Use --no-trash to delete permanently. See yagwt trash.
+
 Examples:
   yagwt rm auth
   yagwt rm name:temp --force
   yagwt rm auth --on-dirty=stash
+  yagwt rm auth --delete-branch
+  yagwt rm auth --trash
   yagwt rm auth --on-dirty=patch --patch-dir=/tmp/patches`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
@@ -52,31 +66,36 @@ Examples:
 
 		// Build remove options
 		opts := core.RemoveOptions{
-			DeleteBranch: rmDeleteBranch,
-			KeepBranch:   rmKeepBranch,
-			OnDirty:      onDirty,
-			PatchDir:     rmPatchDir,
-			WipMessage:   rmWipMessage,
-			NoPrompt:     noPrompt || autoYes,
+			DeleteBranch:      rmDeleteBranch || rmForceDeleteBranch,
+			ForceDeleteBranch: rmForceDeleteBranch,
+			KeepBranch:        rmKeepBranch,
+			OnDirty:           onDirty,
+			PatchDir:          rmPatchDir,
+			WipMessage:        rmWipMessage,
+			NoPrompt:          noPrompt || autoYes,
+			Trash:             rmTrash,
+			NoTrash:           rmNoTrash,
 		}
 
 		// Remove workspace
-		if err := engine.Remove(selector, opts); err != nil {
+		result, err := engine.Remove(selector, opts)
+		if err != nil {
 			handleError(err)
 		}
 
-		// Print success message
-		if !quiet {
-			printOutput(formatter.FormatSuccess("Worktree removed successfully"))
-		}
+		// Print result (hook output, warnings and success message)
+		printOutput(formatter.FormatRemoveResult(result))
 	},
 }
 
 func init() {
-	rmCmd.Flags().BoolVar(&rmDeleteBranch, "delete-branch", false, "also delete the branch")
+	rmCmd.Flags().BoolVar(&rmDeleteBranch, "delete-branch", false, "also delete the branch if it is merged")
+	rmCmd.Flags().BoolVar(&rmForceDeleteBranch, "force-delete-branch", false, "delete the branch even if it has unmerged commits")
 	rmCmd.Flags().BoolVar(&rmKeepBranch, "keep-branch", false, "keep the branch (default)")
 	rmCmd.Flags().StringVar(&rmOnDirty, "on-dirty", "", "strategy: fail, stash, patch, wip-commit, force")
 	rmCmd.Flags().StringVar(&rmPatchDir, "patch-dir", "", "directory for patches (with --on-dirty=patch)")
 	rmCmd.Flags().StringVar(&rmWipMessage, "wip-message", "", "WIP commit message (with --on-dirty=wip-commit)")
+	rmCmd.Flags().BoolVar(&rmTrash, "trash", false, "move the worktree to the trash instead of deleting it")
+	rmCmd.Flags().BoolVar(&rmNoTrash, "no-trash", false, "delete permanently even if trash.enabled is set")
 	rmCmd.Flags().BoolVarP(&rmForce, "force", "f", false, "shortcut for --on-dirty=force")
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/bmf/yagwt/internal/cli/output"
 	"github.com/bmf/yagwt/internal/core"
 	"github.com/bmf/yagwt/internal/errors"
+	"github.com/bmf/yagwt/internal/metadata"
 	"github.com/spf13/cobra"
 )
 
@@ -58,6 +59,11 @@ Use 'yagwt <command> --help' for information on a specific command.`,
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() error {
+	// Record which build wrote the metadata so older builds can name it
+	metadata.WriterVersion = Version
+	metadata.Warn = func(message string) {
+		fmt.Fprintln(os.Stderr, "Warning: "+message)
+	}
 	return rootCmd.Execute()
 }
 
@@ -78,16 +84,31 @@ func init() {
 	rootCmd.AddCommand(lsCmd)
 	rootCmd.AddCommand(showCmd)
 	rootCmd.AddCommand(pathCmd)
+	rootCmd.AddCommand(touchCmd)
 	rootCmd.AddCommand(resolveCmd)
+	rootCmd.AddCommand(statusCmd)
 	rootCmd.AddCommand(newCmd)
+	rootCmd.AddCommand(ensureCmd)
 	rootCmd.AddCommand(rmCmd)
 	rootCmd.AddCommand(renameCmd)
+	rootCmd.AddCommand(moveCmd)
+	rootCmd.AddCommand(bootstrapCmd)
+	rootCmd.AddCommand(seedCmd)
+	rootCmd.AddCommand(sparseCmd)
 	rootCmd.AddCommand(pinCmd)
 	rootCmd.AddCommand(unpinCmd)
 	rootCmd.AddCommand(lockCmd)
 	rootCmd.AddCommand(unlockCmd)
+	rootCmd.AddCommand(ephemeralCmd)
+	rootCmd.AddCommand(permanentCmd)
+	rootCmd.AddCommand(renewCmd)
+	rootCmd.AddCommand(labelCmd)
+	rootCmd.AddCommand(metaCmd)
 	rootCmd.AddCommand(cleanCmd)
 	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(logCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(trashCmd)
 	rootCmd.AddCommand(mcpCmd)
 
 	// Store the default usage function before we override it
@@ -196,7 +217,7 @@ func handleError(err error) {
 			exitCode = ExitNotFound
 		case errors.ErrAmbiguous:
 			exitCode = ExitInvalidUsage
-		case errors.ErrDirty, errors.ErrLocked:
+		case errors.ErrDirty, errors.ErrLocked, errors.ErrHook:
 			exitCode = ExitSafetyRefusal
 		default:
 			exitCode = ExitFailure
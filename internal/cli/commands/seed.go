@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bmf/yagwt/internal/cli/output"
+	"github.com/bmf/yagwt/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var (
+	seedFrom  string
+	seedDirs  []string
+	seedForce bool
+)
+
+var seedCmd = &cobra.Command{
+	Use:   "seed <selector>",
+	Short: "Clone build caches into a worktree from another",
+	Long: `Clone heavy directories such as node_modules, target/ or .venv from
+another worktree so a new one does not start with a cold build.
+
+Files are reflinked (copy-on-write) when the filesystem supports it, so
+seeding is near-instant and uses no extra space. Elsewhere they are
+copied, or hardlinked with workspace.seed.fallback = "hardlink".
+
+Directories come from workspace.seed.dirs in config unless --dir is
+given, and are taken from the primary worktree unless workspace.seed.from
+or --from names another. Directories that already exist are skipped
+unless --force is given. New worktrees are seeded automatically.
+
+Examples:
+  yagwt seed auth
+  yagwt seed auth --from main --dir node_modules --dir .venv
+  yagwt seed auth --force`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		selector := core.ParseSelector(args[0])
+
+		progress := newSeedProgress()
+		result, err := engine.Seed(selector, core.SeedOptions{
+			From:     seedFrom,
+			Dirs:     seedDirs,
+			Force:    seedForce,
+			Progress: progress.report,
+		})
+		progress.done()
+		if err != nil {
+			handleError(err)
+		}
+
+		printOutput(formatter.FormatSeedResult(result))
+	},
+}
+
+// seedProgress prints seeding progress to stderr on a single updating line
+type seedProgress struct {
+	enabled bool
+	printed bool
+	dir     string
+	last    time.Time
+}
+
+// newSeedProgress reports progress only for humans watching a terminal
+func newSeedProgress() *seedProgress {
+	enabled := !quiet && !jsonOutput && !porcelain
+	if info, err := os.Stderr.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		enabled = false
+	}
+	return &seedProgress{enabled: enabled}
+}
+
+func (p *seedProgress) report(update core.SeedProgress) {
+	if !p.enabled {
+		return
+	}
+
+	// Finish the previous directory's line before starting a new one
+	if p.printed && update.Dir != p.dir {
+		fmt.Fprintln(os.Stderr)
+	}
+	finished := update.Bytes == update.Total
+	if update.Dir == p.dir && !finished && time.Since(p.last) < 100*time.Millisecond {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "\r\033[K%s", output.FormatSeedProgress(update))
+	p.dir = update.Dir
+	p.last = time.Now()
+	p.printed = true
+}
+
+func (p *seedProgress) done() {
+	if p.printed {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+func init() {
+	seedCmd.Flags().StringVar(&seedFrom, "from", "", "workspace to seed from (default: workspace.seed.from or the primary worktree)")
+	seedCmd.Flags().StringArrayVar(&seedDirs, "dir", nil, "directory or glob to seed (repeatable; default: workspace.seed.dirs)")
+	seedCmd.Flags().BoolVar(&seedForce, "force", false, "replace directories that already exist in the worktree")
+}
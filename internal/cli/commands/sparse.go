@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"github.com/bmf/yagwt/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var sparseCmd = &cobra.Command{
+	Use:   "sparse <selector> <add|remove|list> [pattern]...",
+	Short: "Adjust the sparse checkout of a worktree",
+	Long: `List, add or remove the sparse-checkout patterns of a worktree.
+
+In cone mode (the default) patterns are directories; worktrees created
+with --no-cone or a profile with cone = false use gitignore-style
+patterns. Adding patterns to a full checkout makes it sparse in cone mode.
+
+Examples:
+  yagwt sparse ui list
+  yagwt sparse ui add libs/charts
+  yagwt sparse ui remove apps/legacy`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		action := args[1]
+		patterns := args[2:]
+		switch action {
+		case "list":
+			if len(patterns) != 0 {
+				handleError(core.NewError(core.ErrConfig, "sparse list takes no patterns"))
+			}
+		case "add", "remove":
+			if len(patterns) == 0 {
+				handleError(core.NewError(core.ErrConfig, "no patterns given").
+					WithHint("Name at least one pattern", "yagwt sparse "+args[0]+" "+action+" <pattern>..."))
+			}
+		default:
+			handleError(core.NewError(core.ErrConfig, "unknown sparse action").
+				WithDetail("action", action).
+				WithDetail("valid", "add, remove, list"))
+		}
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		selector := core.ParseSelector(args[0])
+
+		var info *core.SparseInfo
+		var err error
+		switch action {
+		case "add":
+			info, err = engine.AddSparsePatterns(selector, patterns)
+		case "remove":
+			info, err = engine.RemoveSparsePatterns(selector, patterns)
+		default:
+			info, err = engine.SparsePatterns(selector)
+		}
+		if err != nil {
+			handleError(err)
+		}
+
+		printOutput(formatter.FormatSparseInfo(info))
+	},
+}
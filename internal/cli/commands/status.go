@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"time"
+
+	"github.com/bmf/yagwt/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var statusExpiring string
+
+var statusCmd = &cobra.Command{
+	Use:   "status [<selector>]",
+	Short: "Show a summary of all worktrees",
+	Long: `Show a dashboard of worktrees that need attention.
+
+Reports counts by lifecycle state, worktrees with uncommitted changes or
+merge conflicts, commits ahead/behind upstream, ephemeral worktrees that
+expire soon, broken entries that 'yagwt doctor' can repair, and cleanup
+candidates under the default policy.
+
+With a selector, only matching worktrees are summarized.
+
+Examples:
+  yagwt status
+  yagwt status --expiring 3d
+  yagwt status branch:feature/auth
+  yagwt status --json`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		var window time.Duration
+		if statusExpiring != "" {
+			var err error
+			window, err = parseDuration(statusExpiring)
+			if err != nil {
+				handleError(err)
+			}
+		}
+
+		opts := core.StatusOptions{ExpiringWithin: window}
+		if len(args) == 1 {
+			opts.Selector = args[0]
+		}
+
+		report, err := engine.Status(opts)
+		if err != nil {
+			handleError(err)
+		}
+
+		printOutput(formatter.FormatStatusReport(report))
+	},
+}
+
+func init() {
+	statusCmd.Flags().StringVar(&statusExpiring, "expiring", "24h", "window for ephemeral worktrees expiring soon (e.g., '24h', '3d')")
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bmf/yagwt/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var touchCmd = &cobra.Command{
+	Use:     "touch <selector>",
+	Aliases: []string{"open"},
+	Short:   "Record that a worktree was opened",
+	Long: `Record the current time as the worktree's last-opened time, run the
+post-open hook and print its path.
+
+Opened worktrees count as active for idle cleanup policies and
+activity filters. Call it from shell or editor integrations.
+
+Examples:
+  cd $(yagwt open auth)
+  yagwt touch feature-x`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		// Parse selector
+		selector := core.ParseSelector(args[0])
+
+		result, err := engine.Touch(selector)
+		if err != nil {
+			handleError(err)
+		}
+
+		// Hook failures go to stderr so the path stays usable in $(...)
+		for _, warning := range result.Warnings {
+			fmt.Fprintln(os.Stderr, "Warning: "+warning.Message)
+		}
+
+		// Print just the path (for scripting)
+		output := formatter.FormatWorkspacePath(result.Workspace)
+		printOutput(output)
+	},
+}
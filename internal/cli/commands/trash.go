@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"time"
+
+	"github.com/bmf/yagwt/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var (
+	trashEmptyOlderThan string
+	trashEmptyDryRun    bool
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage removed worktrees kept in the trash",
+	Long: `Inspect, restore and purge worktrees moved to the trash.
+
+With trash.enabled = true in config, or rm --trash, removal moves the
+worktree directory into the trash instead of deleting it. Uncommitted and
+untracked files are kept as they were, and the HEAD commit is kept alive
+by a ref, so the branch can be deleted safely.
+
+Examples:
+  yagwt trash ls
+  yagwt trash restore auth
+  yagwt trash empty --older-than 30d`,
+}
+
+var trashLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List trashed worktrees",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		workspaces, err := engine.ListTrash()
+		if err != nil {
+			handleError(err)
+		}
+
+		printOutput(formatter.FormatTrash(workspaces))
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <selector>",
+	Short: "Move a trashed worktree back to its original path",
+	Long: `Move a trashed worktree back to its original path with its name,
+flags and uncommitted changes. The branch is recreated from the trashed
+HEAD if it was deleted.
+
+Examples:
+  yagwt trash restore auth
+  yagwt trash restore id:3f2a...`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		// Parse selector
+		selector := core.ParseSelector(args[0])
+
+		ws, err := engine.Restore(selector)
+		if err != nil {
+			handleError(err)
+		}
+
+		printOutput(formatter.FormatWorkspace(ws))
+		if !quiet {
+			printOutput(formatter.FormatSuccess("Worktree restored from trash"))
+		}
+	},
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently delete trashed worktrees",
+	Long: `Permanently delete trashed worktrees, their files and trash refs.
+
+Without --older-than, everything in the trash is purged.
+
+Examples:
+  yagwt trash empty
+  yagwt trash empty --older-than 14d
+  yagwt trash empty --dry-run`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		var olderThan time.Duration
+		if trashEmptyOlderThan != "" {
+			var err error
+			olderThan, err = parseDuration(trashEmptyOlderThan)
+			if err != nil {
+				handleError(err)
+			}
+		}
+
+		result, err := engine.EmptyTrash(core.EmptyTrashOptions{
+			OlderThan: olderThan,
+			DryRun:    trashEmptyDryRun,
+		})
+		if err != nil {
+			handleError(err)
+		}
+
+		printOutput(formatter.FormatEmptyTrashResult(result))
+	},
+}
+
+func init() {
+	trashEmptyCmd.Flags().StringVar(&trashEmptyOlderThan, "older-than", "", "only purge worktrees trashed longer ago than this (e.g., '14d')")
+	trashEmptyCmd.Flags().BoolVar(&trashEmptyDryRun, "dry-run", false, "show what would be purged")
+
+	trashCmd.AddCommand(trashLsCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"strconv"
+
+	"github.com/bmf/yagwt/internal/core"
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [<entry>]",
+	Short: "Reverse a journaled operation",
+	Long: `Reverse an entry from the operation journal (see yagwt log).
+
+Without an entry, the newest operation that has not been undone is
+reversed. Undoing a removal recreates the worktree at its old path,
+recreating its branch if it was deleted, restores its metadata and
+reapplies changes saved by --on-dirty=stash, patch or wip-commit.
+
+Examples:
+  yagwt undo
+  yagwt undo 12`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		initFormatter()
+
+		var seq int
+		if len(args) == 1 {
+			var err error
+			seq, err = strconv.Atoi(args[0])
+			if err != nil || seq <= 0 {
+				handleError(core.NewError(core.ErrConfig, "invalid journal entry").
+					WithDetail("entry", args[0]).
+					WithHint("List journal entries", "yagwt log"))
+			}
+		}
+
+		// Initialize engine
+		if err := initEngine(); err != nil {
+			handleError(err)
+		}
+
+		result, err := engine.Undo(seq)
+		if err != nil {
+			handleError(err)
+		}
+
+		printOutput(formatter.FormatUndoResult(result))
+	},
+}
@@ -0,0 +1,64 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bmf/yagwt/internal/core"
+)
+
+// projectWorkspace returns the values of the given fields (as expanded by
+// core.ExpandFields). Values are read from the workspace's JSON form so
+// field names match the JSON output; missing values are nil.
+func projectWorkspace(ws core.Workspace, fields []string) []interface{} {
+	var doc map[string]interface{}
+	data, err := json.Marshal(convertWorkspace(ws))
+	if err == nil {
+		err = json.Unmarshal(data, &doc)
+	}
+
+	values := make([]interface{}, len(fields))
+	if err != nil {
+		return values
+	}
+
+	for i, field := range fields {
+		// Custom field names may themselves contain dots
+		keys := strings.Split(field, ".")
+		if rest, ok := strings.CutPrefix(field, "fields."); ok {
+			keys = []string{"fields", rest}
+		}
+
+		var value interface{} = doc
+		for _, key := range keys {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				value = nil
+				break
+			}
+			value = m[key]
+		}
+		values[i] = value
+	}
+
+	return values
+}
+
+// formatFieldValue renders a projected value for text output
+func formatFieldValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = formatFieldValue(item)
+		}
+		return strings.Join(parts, ",")
+	case map[string]interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
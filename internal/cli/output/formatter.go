@@ -8,15 +8,37 @@ import (
 type Formatter interface {
 	// Workspace formatting
 	FormatWorkspaces(workspaces []core.Workspace) string
+	FormatWorkspaceFields(workspaces []core.Workspace, fields []string) string
 	FormatWorkspace(workspace core.Workspace) string
 	FormatWorkspacePath(workspace core.Workspace) string
 
+	// Operation result formatting
+	FormatCreateResult(result core.CreateResult) string
+	FormatEnsureResult(result core.EnsureResult) string
+	FormatRemoveResult(result core.RemoveResult) string
+	FormatBootstrapResult(result core.BootstrapResult) string
+	FormatSeedResult(result core.SeedResult) string
+
+	// Sparse-checkout formatting; info is nil for a full checkout
+	FormatSparseInfo(info *core.SparseInfo) string
+
+	// Status dashboard formatting
+	FormatStatusReport(report core.StatusReport) string
+
 	// Cleanup formatting
 	FormatCleanupPlan(plan core.CleanupPlan) string
 
 	// Doctor formatting
 	FormatDoctorReport(report core.DoctorReport) string
 
+	// Journal formatting
+	FormatJournal(entries []core.JournalEntry) string
+	FormatUndoResult(result core.UndoResult) string
+
+	// Trash formatting
+	FormatTrash(workspaces []core.Workspace) string
+	FormatEmptyTrashResult(result core.EmptyTrashResult) string
+
 	// Error formatting
 	FormatError(err error) string
 
@@ -2,11 +2,14 @@ package output
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/bmf/yagwt/internal/core"
 	"github.com/bmf/yagwt/internal/errors"
+	"github.com/bmf/yagwt/internal/filter"
 )
 
 type humanFormatter struct {
@@ -20,8 +23,21 @@ func (f *humanFormatter) FormatWorkspaces(workspaces []core.Workspace) string {
 
 	var b strings.Builder
 
+	// Only show the labels column when something is labeled
+	showLabels := false
+	for _, ws := range workspaces {
+		if len(ws.Labels) > 0 {
+			showLabels = true
+			break
+		}
+	}
+
 	// Header
-	b.WriteString(formatTableHeader([]string{"NAME", "BRANCH/COMMIT", "PATH", "STATUS"}))
+	headers := []string{"NAME", "BRANCH/COMMIT", "PATH", "STATUS"}
+	if showLabels {
+		headers = []string{"NAME", "BRANCH/COMMIT", "PATH", "STATUS", "LABELS"}
+	}
+	b.WriteString(formatTableHeader(headers))
 	b.WriteString("\n")
 
 	// Rows
@@ -50,6 +66,17 @@ func (f *humanFormatter) FormatWorkspaces(workspaces []core.Workspace) string {
 
 		status := formatStatus(ws.Status)
 
+		if showLabels {
+			b.WriteString(fmt.Sprintf("%-30s %-30s %-40s %-20s %s\n",
+				truncate(name, 30),
+				truncate(target, 30),
+				truncate(ws.Path, 40),
+				status,
+				strings.Join(ws.Labels, ","),
+			))
+			continue
+		}
+
 		b.WriteString(fmt.Sprintf("%-30s %-30s %-40s %s\n",
 			truncate(name, 30),
 			truncate(target, 30),
@@ -66,6 +93,33 @@ func (f *humanFormatter) FormatWorkspaces(workspaces []core.Workspace) string {
 	return b.String()
 }
 
+func (f *humanFormatter) FormatWorkspaceFields(workspaces []core.Workspace, fields []string) string {
+	if len(workspaces) == 0 {
+		return "No workspaces found."
+	}
+
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	headers := make([]string, len(fields))
+	for i, field := range fields {
+		headers[i] = strings.ToUpper(field)
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, ws := range workspaces {
+		values := projectWorkspace(ws, fields)
+		columns := make([]string, len(values))
+		for i, value := range values {
+			columns[i] = formatFieldValue(value)
+		}
+		fmt.Fprintln(tw, strings.Join(columns, "\t"))
+	}
+	tw.Flush()
+
+	return b.String()
+}
+
 func (f *humanFormatter) FormatWorkspace(workspace core.Workspace) string {
 	var b strings.Builder
 
@@ -78,6 +132,12 @@ func (f *humanFormatter) FormatWorkspace(workspace core.Workspace) string {
 	}
 	b.WriteString(fmt.Sprintf("  HEAD:       %s\n", workspace.Target.HeadSHA[:7]))
 	b.WriteString(fmt.Sprintf("  Status:     %s\n", formatStatus(workspace.Status)))
+	if len(workspace.Status.Submodules) > 0 {
+		b.WriteString("  Submodules:\n")
+		for _, sub := range workspace.Status.Submodules {
+			b.WriteString(fmt.Sprintf("    %s (%s)\n", sub.Path, submoduleChanges(sub)))
+		}
+	}
 
 	// Flags
 	var flags []string
@@ -99,12 +159,47 @@ func (f *humanFormatter) FormatWorkspace(workspace core.Workspace) string {
 	if len(flags) > 0 {
 		b.WriteString(fmt.Sprintf("  Flags:      %s\n", strings.Join(flags, ", ")))
 	}
+	if len(workspace.Labels) > 0 {
+		b.WriteString(fmt.Sprintf("  Labels:     %s\n", strings.Join(workspace.Labels, ", ")))
+	}
+	if len(workspace.Fields) > 0 {
+		keys := make([]string, 0, len(workspace.Fields))
+		for key := range workspace.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		b.WriteString("  Fields:\n")
+		for _, key := range keys {
+			b.WriteString(fmt.Sprintf("    %s = %s\n", key, filter.FieldString(workspace.Fields[key])))
+		}
+	}
 
 	// Ephemeral info
 	if workspace.Ephemeral != nil {
-		b.WriteString(fmt.Sprintf("  Expires:    %s (%s)\n",
+		sliding := ""
+		if workspace.Ephemeral.Sliding {
+			sliding = ", sliding"
+		}
+		b.WriteString(fmt.Sprintf("  Expires:    %s (%s%s)\n",
 			workspace.Ephemeral.ExpiresAt.Format("2006-01-02 15:04"),
 			formatDuration(time.Until(workspace.Ephemeral.ExpiresAt)),
+			sliding,
+		))
+	}
+
+	// Sparse checkout
+	if workspace.Sparse != nil {
+		b.WriteString(fmt.Sprintf("  Sparse:     %s\n", sparseSummary(workspace.Sparse)))
+	}
+
+	// Seed source
+	if workspace.Seed != nil {
+		b.WriteString(fmt.Sprintf("  Seeded:     from %s, %s by %s (%s)\n",
+			workspace.Seed.FromName,
+			FormatBytes(workspace.Seed.Bytes),
+			workspace.Seed.Method,
+			workspace.Seed.SeededAt.Format("2006-01-02 15:04"),
 		))
 	}
 
@@ -124,6 +219,125 @@ func (f *humanFormatter) FormatWorkspacePath(workspace core.Workspace) string {
 	return workspace.Path
 }
 
+func (f *humanFormatter) FormatCreateResult(result core.CreateResult) string {
+	var b strings.Builder
+
+	b.WriteString(f.FormatWorkspace(result.Workspace))
+	b.WriteString(formatBootstrapFiles(result.Bootstrap))
+	b.WriteString(formatSeededDirs(result.Seed))
+	b.WriteString(formatHookResults(result.Hooks))
+	b.WriteString(formatWarnings(result.Warnings))
+
+	return b.String()
+}
+
+func (f *humanFormatter) FormatEnsureResult(result core.EnsureResult) string {
+	var b strings.Builder
+
+	b.WriteString(f.FormatWorkspace(result.Workspace))
+	b.WriteString(formatBootstrapFiles(result.Bootstrap))
+	b.WriteString(formatSeededDirs(result.Seed))
+	b.WriteString(formatHookResults(result.Hooks))
+	b.WriteString(formatWarnings(result.Warnings))
+	if result.Created {
+		b.WriteString(f.FormatSuccess("Worktree created successfully"))
+	} else {
+		b.WriteString(f.FormatSuccess("Worktree already exists"))
+	}
+
+	return b.String()
+}
+
+func (f *humanFormatter) FormatRemoveResult(result core.RemoveResult) string {
+	var b strings.Builder
+
+	b.WriteString(formatHookResults(result.Hooks))
+	b.WriteString(formatWarnings(result.Warnings))
+	if result.Trashed {
+		b.WriteString(f.FormatSuccess("Worktree moved to trash"))
+	} else {
+		b.WriteString(f.FormatSuccess("Worktree removed successfully"))
+	}
+	if result.DeletedBranch != "" {
+		b.WriteString(f.FormatSuccess("Deleted branch " + result.DeletedBranch))
+	}
+
+	return b.String()
+}
+
+func (f *humanFormatter) FormatStatusReport(report core.StatusReport) string {
+	var b strings.Builder
+
+	// Summary line: total and non-zero lifecycle states
+	var states []string
+	for _, state := range core.LifecycleStates {
+		if n := report.States[state]; n > 0 {
+			states = append(states, fmt.Sprintf("%d %s", n, state))
+		}
+	}
+	b.WriteString(fmt.Sprintf("Workspaces: %d", report.Total))
+	if len(states) > 0 {
+		b.WriteString(" (" + strings.Join(states, ", ") + ")")
+	}
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("Commits:    %d ahead, %d behind upstream\n", report.Ahead, report.Behind))
+
+	attention := len(report.Dirty) + len(report.Conflicted) + len(report.ExpiringSoon) +
+		len(report.Broken) + len(report.CleanupCandidates)
+	if attention == 0 {
+		b.WriteString("\nNothing needs attention.\n")
+		return b.String()
+	}
+
+	if len(report.Dirty) > 0 {
+		b.WriteString(fmt.Sprintf("\nUncommitted changes (%d):\n", len(report.Dirty)))
+		for _, ws := range report.Dirty {
+			b.WriteString(fmt.Sprintf("  - %s (%s)\n", ws.Name, formatStatus(ws.Status)))
+		}
+	}
+
+	if len(report.Conflicted) > 0 {
+		b.WriteString(fmt.Sprintf("\nMerge conflicts (%d):\n", len(report.Conflicted)))
+		for _, ws := range report.Conflicted {
+			b.WriteString(fmt.Sprintf("  - %s\n", ws.Name))
+		}
+	}
+
+	if len(report.ExpiringSoon) > 0 {
+		b.WriteString(fmt.Sprintf("\nEphemeral, expiring soon (%d):\n", len(report.ExpiringSoon)))
+		for _, ws := range report.ExpiringSoon {
+			remaining := time.Until(ws.Ephemeral.ExpiresAt)
+			when := "expires in " + formatDuration(remaining)
+			if remaining <= 0 {
+				when = "expired " + formatDuration(remaining) + " ago"
+			}
+			b.WriteString(fmt.Sprintf("  - %s (%s)\n", ws.Name, when))
+		}
+	}
+
+	if len(report.Broken) > 0 {
+		b.WriteString(fmt.Sprintf("\nBroken (%d):\n", len(report.Broken)))
+		for _, ws := range report.Broken {
+			b.WriteString(fmt.Sprintf("  - %s (%s)\n", ws.Name, ws.Path))
+		}
+		if !f.quiet {
+			b.WriteString("  Run 'yagwt doctor' to repair.\n")
+		}
+	}
+
+	if len(report.CleanupCandidates) > 0 {
+		b.WriteString(fmt.Sprintf("\nCleanup candidates (%d):\n", len(report.CleanupCandidates)))
+		for _, action := range report.CleanupCandidates {
+			b.WriteString(fmt.Sprintf("  - %s (%s)\n", action.Workspace.Name, action.Reason))
+		}
+		if !f.quiet {
+			b.WriteString("  Run 'yagwt clean' to review.\n")
+		}
+	}
+
+	return b.String()
+}
+
 func (f *humanFormatter) FormatCleanupPlan(plan core.CleanupPlan) string {
 	var b strings.Builder
 
@@ -139,7 +353,9 @@ func (f *humanFormatter) FormatCleanupPlan(plan core.CleanupPlan) string {
 		// Rows
 		for _, action := range plan.Actions {
 			status := "clean"
-			if action.Workspace.Status.Dirty {
+			if action.Trashed {
+				status = "trashed"
+			} else if action.Workspace.Status.Dirty {
 				status = fmt.Sprintf("dirty (will %s)", action.OnDirty)
 			}
 
@@ -201,6 +417,14 @@ func (f *humanFormatter) FormatDoctorReport(report core.DoctorReport) string {
 		}
 	}
 
+	// Metadata migration steps
+	if report.Migration != nil {
+		b.WriteString("\nMetadata migration:\n")
+		for _, step := range report.Migration.Steps {
+			b.WriteString(fmt.Sprintf("  - %s\n", step))
+		}
+	}
+
 	// Warnings
 	if len(report.Warnings) > 0 {
 		b.WriteString("\nWarnings:\n")
@@ -212,6 +436,121 @@ func (f *humanFormatter) FormatDoctorReport(report core.DoctorReport) string {
 	return b.String()
 }
 
+func (f *humanFormatter) FormatJournal(entries []core.JournalEntry) string {
+	if len(entries) == 0 {
+		return "Journal is empty."
+	}
+
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "SEQ\tTIME\tOPERATION\tWORKSPACE\tDETAILS")
+	for _, entry := range entries {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n",
+			entry.Seq,
+			entry.Time.Local().Format("2006-01-02 15:04:05"),
+			entry.Operation,
+			entry.Name,
+			journalDetails(entry),
+		)
+	}
+	tw.Flush()
+
+	return b.String()
+}
+
+func (f *humanFormatter) FormatUndoResult(result core.UndoResult) string {
+	var b strings.Builder
+
+	b.WriteString(formatWarnings(result.Warnings))
+	b.WriteString(f.FormatSuccess(fmt.Sprintf("Undid %s of '%s' (entry %d)",
+		result.Entry.Operation, result.Entry.Name, result.Entry.Seq)))
+	if result.Workspace.Path != "" && !f.quiet {
+		b.WriteString(fmt.Sprintf("Path: %s\n", result.Workspace.Path))
+	}
+
+	return b.String()
+}
+
+func (f *humanFormatter) FormatTrash(workspaces []core.Workspace) string {
+	if len(workspaces) == 0 {
+		return "Trash is empty."
+	}
+
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "NAME\tBRANCH/COMMIT\tTRASHED\tPATH")
+	for _, ws := range workspaces {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+			ws.Name,
+			ws.Target.Short,
+			ws.Trash.TrashedAt.Local().Format("2006-01-02 15:04:05"),
+			ws.Path,
+		)
+	}
+	tw.Flush()
+
+	return b.String()
+}
+
+func (f *humanFormatter) FormatBootstrapResult(result core.BootstrapResult) string {
+	var b strings.Builder
+
+	if result.Source != "" {
+		b.WriteString(fmt.Sprintf("Bootstrapped %s from %s\n", result.Workspace.Name, result.Source))
+	}
+	b.WriteString(formatBootstrapFiles(result.Files))
+	b.WriteString(formatWarnings(result.Warnings))
+	if len(result.Files) == 0 && len(result.Warnings) == 0 {
+		b.WriteString(f.FormatSuccess("Nothing to bootstrap"))
+	}
+
+	return b.String()
+}
+
+func (f *humanFormatter) FormatSeedResult(result core.SeedResult) string {
+	var b strings.Builder
+
+	if result.Source.Name != "" {
+		b.WriteString(fmt.Sprintf("Seeded %s from %s (%s)\n", result.Workspace.Name, result.Source.Name, FormatBytes(result.Bytes)))
+	}
+	b.WriteString(formatSeededDirs(result.Dirs))
+	b.WriteString(formatWarnings(result.Warnings))
+	if len(result.Dirs) == 0 && len(result.Warnings) == 0 {
+		b.WriteString(f.FormatSuccess("Nothing to seed"))
+	}
+
+	return b.String()
+}
+
+func (f *humanFormatter) FormatSparseInfo(info *core.SparseInfo) string {
+	if info == nil {
+		return "Worktree is a full checkout (not sparse).\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Sparse checkout: %s\n", sparseSummary(info)))
+	for _, pattern := range info.Patterns {
+		b.WriteString("  " + pattern + "\n")
+	}
+	return b.String()
+}
+
+func (f *humanFormatter) FormatEmptyTrashResult(result core.EmptyTrashResult) string {
+	var b strings.Builder
+
+	for _, ws := range result.Purged {
+		b.WriteString(fmt.Sprintf("Purged %s (%s)\n", ws.Name, ws.Path))
+	}
+	b.WriteString(formatWarnings(result.Warnings))
+	if len(result.Purged) == 0 {
+		b.WriteString(f.FormatSuccess("Nothing to purge"))
+	}
+
+	return b.String()
+}
+
 func (f *humanFormatter) FormatError(err error) string {
 	var b strings.Builder
 
@@ -267,14 +606,73 @@ func (f *humanFormatter) FormatSuccess(message string) string {
 
 // Helper functions
 
+// journalDetails summarizes what a journal entry saved or changed
+func journalDetails(entry core.JournalEntry) string {
+	var parts []string
+
+	switch {
+	case entry.NewName != "":
+		parts = append(parts, "renamed to "+entry.NewName)
+	case entry.Undoes != 0:
+		parts = append(parts, fmt.Sprintf("undoes %d", entry.Undoes))
+	case entry.Branch != "":
+		parts = append(parts, "branch "+entry.Branch)
+	}
+
+	if entry.DeletedBranch {
+		parts = append(parts, "branch deleted")
+	}
+	if entry.StashRef != "" {
+		parts = append(parts, "stash "+shortSHA(entry.StashRef))
+	}
+	if entry.PatchFile != "" {
+		parts = append(parts, "patch "+entry.PatchFile)
+	}
+	if entry.WIPCommit != "" {
+		parts = append(parts, "wip "+shortSHA(entry.WIPCommit))
+	}
+	if entry.TrashRef != "" {
+		parts = append(parts, "trashed")
+	}
+	if entry.UndoneBy != 0 {
+		parts = append(parts, fmt.Sprintf("[undone by %d]", entry.UndoneBy))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
 func formatTableHeader(headers []string) string {
 	return strings.Join(headers, " | ")
 }
 
+// submoduleChanges describes what changed in a submodule
+func submoduleChanges(sub core.SubmoduleStatus) string {
+	var parts []string
+	if sub.CommitChanged {
+		parts = append(parts, "new commits")
+	}
+	if sub.Modified {
+		parts = append(parts, "modified")
+	}
+	if sub.Untracked {
+		parts = append(parts, "untracked")
+	}
+	return strings.Join(parts, ", ")
+}
+
 func formatStatus(status core.StatusInfo) string {
 	var parts []string
 
-	if status.Dirty {
+	if status.SubmoduleOnly {
+		parts = append(parts, "dirty (submodules)")
+	} else if status.Dirty {
 		parts = append(parts, "dirty")
 	}
 	if status.Conflicts {
@@ -294,6 +692,120 @@ func formatStatus(status core.StatusInfo) string {
 	return strings.Join(parts, ", ")
 }
 
+func formatBootstrapFiles(files []core.BootstrapFile) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\nBootstrap:\n")
+	for _, file := range files {
+		if file.Skipped {
+			b.WriteString(fmt.Sprintf("  %-10s %s (exists, skipped)\n", file.Mode, file.Path))
+			continue
+		}
+		b.WriteString(fmt.Sprintf("  %-10s %s\n", file.Mode, file.Path))
+	}
+	return b.String()
+}
+
+func formatSeededDirs(dirs []core.SeededDir) string {
+	if len(dirs) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\nSeed:\n")
+	for _, dir := range dirs {
+		if dir.Skipped {
+			b.WriteString(fmt.Sprintf("  %-30s (exists, skipped)\n", dir.Path))
+			continue
+		}
+		b.WriteString(fmt.Sprintf("  %-30s %10s  %d files  %s\n", dir.Path, FormatBytes(dir.Bytes), dir.Files, dir.Method))
+	}
+	return b.String()
+}
+
+// FormatSeedProgress renders a one-line progress report for seeding
+func FormatSeedProgress(p core.SeedProgress) string {
+	percent := 100
+	if p.Total > 0 {
+		percent = int(p.Bytes * 100 / p.Total)
+	}
+	return fmt.Sprintf("Seeding %s: %s / %s (%d%%)", p.Dir, FormatBytes(p.Bytes), FormatBytes(p.Total), percent)
+}
+
+// FormatBytes renders a byte count with a binary unit, e.g. "1.5 GiB"
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// sparseSummary describes a sparse checkout in one line, e.g.
+// "frontend profile, cone mode, 3 patterns"
+func sparseSummary(info *core.SparseInfo) string {
+	var parts []string
+	if info.Profile != "" {
+		parts = append(parts, info.Profile+" profile")
+	}
+	if info.Cone {
+		parts = append(parts, "cone mode")
+	} else {
+		parts = append(parts, "non-cone mode")
+	}
+	if len(info.Patterns) == 1 {
+		parts = append(parts, "1 pattern")
+	} else {
+		parts = append(parts, fmt.Sprintf("%d patterns", len(info.Patterns)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatHookResults(results []core.HookResult) string {
+	if len(results) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\nHooks:\n")
+	for _, r := range results {
+		outcome := "ok"
+		if r.TimedOut {
+			outcome = "timed out"
+		} else if r.ExitCode != 0 {
+			outcome = fmt.Sprintf("exit %d", r.ExitCode)
+		}
+		b.WriteString(fmt.Sprintf("  %-12s %s (%s)\n", r.Hook, outcome, r.Duration.Round(time.Millisecond)))
+		if r.ExitCode != 0 && strings.TrimSpace(r.Stderr) != "" {
+			for _, line := range strings.Split(strings.TrimSpace(r.Stderr), "\n") {
+				b.WriteString(fmt.Sprintf("    %s\n", line))
+			}
+		}
+	}
+	return b.String()
+}
+
+func formatWarnings(warnings []core.Warning) string {
+	if len(warnings) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\nWarnings:\n")
+	for _, warning := range warnings {
+		b.WriteString(fmt.Sprintf("  - %s\n", warning.Message))
+	}
+	return b.String()
+}
+
 func truncate(s string, max int) string {
 	if len(s) <= max {
 		return s
@@ -3,6 +3,7 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/bmf/yagwt/internal/core"
@@ -21,15 +22,48 @@ type jsonOutput struct {
 }
 
 type jsonWorkspace struct {
-	ID        string         `json:"id"`
-	Name      string         `json:"name"`
-	Path      string         `json:"path"`
-	IsPrimary bool           `json:"isPrimary"`
-	Target    jsonTarget     `json:"target"`
-	Flags     jsonFlags      `json:"flags"`
-	Ephemeral *jsonEphemeral `json:"ephemeral,omitempty"`
-	Activity  jsonActivity   `json:"activity"`
-	Status    jsonStatus     `json:"status"`
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	Path      string                 `json:"path"`
+	IsPrimary bool                   `json:"isPrimary"`
+	Target    jsonTarget             `json:"target"`
+	Flags     jsonFlags              `json:"flags"`
+	Labels    []string               `json:"labels"`
+	Fields    map[string]interface{} `json:"fields"`
+	Ephemeral *jsonEphemeral         `json:"ephemeral,omitempty"`
+	Seed      *jsonSeed              `json:"seed,omitempty"`
+	Sparse    *jsonSparse            `json:"sparse,omitempty"`
+	Activity  jsonActivity           `json:"activity"`
+	Status    jsonStatus             `json:"status"`
+	CreatedAt *string                `json:"createdAt,omitempty"`
+	Trash     *jsonTrash             `json:"trash,omitempty"`
+}
+
+type jsonSeed struct {
+	FromID   string   `json:"fromId"`
+	FromName string   `json:"fromName"`
+	Dirs     []string `json:"dirs"`
+	Bytes    int64    `json:"bytes"`
+	Method   string   `json:"method"`
+	SeededAt string   `json:"seededAt"`
+}
+
+type jsonSparse struct {
+	Profile  string   `json:"profile,omitempty"`
+	Patterns []string `json:"patterns"`
+	Cone     bool     `json:"cone"`
+}
+
+type jsonSparseResult struct {
+	Sparse   bool     `json:"sparse"`
+	Profile  string   `json:"profile,omitempty"`
+	Patterns []string `json:"patterns"`
+	Cone     bool     `json:"cone"`
+}
+
+type jsonTrash struct {
+	TrashedAt string `json:"trashedAt"`
+	Dir       string `json:"dir"`
 }
 
 type jsonTarget struct {
@@ -50,6 +84,7 @@ type jsonFlags struct {
 type jsonEphemeral struct {
 	TTLSeconds int    `json:"ttlSeconds"`
 	ExpiresAt  string `json:"expiresAt"`
+	Sliding    bool   `json:"sliding"`
 }
 
 type jsonActivity struct {
@@ -58,12 +93,21 @@ type jsonActivity struct {
 }
 
 type jsonStatus struct {
-	Dirty     bool   `json:"dirty"`
-	Conflicts bool   `json:"conflicts"`
-	Ahead     int    `json:"ahead"`
-	Behind    int    `json:"behind"`
-	Branch    string `json:"branch"`
-	Detached  bool   `json:"detached"`
+	Dirty         bool                  `json:"dirty"`
+	Conflicts     bool                  `json:"conflicts"`
+	Ahead         int                   `json:"ahead"`
+	Behind        int                   `json:"behind"`
+	Branch        string                `json:"branch"`
+	Detached      bool                  `json:"detached"`
+	SubmoduleOnly bool                  `json:"submoduleOnly"`
+	Submodules    []jsonSubmoduleStatus `json:"submodules,omitempty"`
+}
+
+type jsonSubmoduleStatus struct {
+	Path          string `json:"path"`
+	CommitChanged bool   `json:"commitChanged"`
+	Modified      bool   `json:"modified"`
+	Untracked     bool   `json:"untracked"`
 }
 
 type jsonCleanupPlan struct {
@@ -72,9 +116,50 @@ type jsonCleanupPlan struct {
 }
 
 type jsonRemovalAction struct {
-	Workspace jsonWorkspace `json:"workspace"`
-	Reason    string        `json:"reason"`
-	OnDirty   string        `json:"onDirty"`
+	Workspace     jsonWorkspace    `json:"workspace"`
+	Reason        string           `json:"reason"`
+	OnDirty       string           `json:"onDirty"`
+	DeletedBranch string           `json:"deletedBranch,omitempty"`
+	Trashed       bool             `json:"trashed,omitempty"`
+	Hooks         []jsonHookResult `json:"hooks,omitempty"`
+}
+
+// jsonCreateResult keeps the workspace fields at the top level so existing
+// consumers of `new --json` keep working
+type jsonCreateResult struct {
+	jsonWorkspace
+	Bootstrap []jsonBootstrapFile `json:"bootstrap"`
+	Seed      []jsonSeededDir     `json:"seed"`
+	Hooks     []jsonHookResult    `json:"hooks"`
+	Warnings  []jsonWarning       `json:"warnings"`
+}
+
+type jsonEnsureResult struct {
+	jsonWorkspace
+	Created   bool                `json:"created"`
+	Bootstrap []jsonBootstrapFile `json:"bootstrap"`
+	Seed      []jsonSeededDir     `json:"seed"`
+	Hooks     []jsonHookResult    `json:"hooks"`
+	Warnings  []jsonWarning       `json:"warnings"`
+}
+
+type jsonRemoveResult struct {
+	Workspace     jsonWorkspace    `json:"workspace"`
+	Removed       bool             `json:"removed"`
+	Trashed       bool             `json:"trashed"`
+	DeletedBranch string           `json:"deletedBranch,omitempty"`
+	Hooks         []jsonHookResult `json:"hooks"`
+	Warnings      []jsonWarning    `json:"warnings"`
+}
+
+type jsonHookResult struct {
+	Hook       string `json:"hook"`
+	Command    string `json:"command"`
+	ExitCode   int    `json:"exitCode"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	DurationMs int64  `json:"durationMs"`
+	TimedOut   bool   `json:"timedOut"`
 }
 
 type jsonWarning struct {
@@ -82,10 +167,31 @@ type jsonWarning struct {
 	Message string `json:"message"`
 }
 
+type jsonStatusReport struct {
+	Total             int                 `json:"total"`
+	States            map[string]int      `json:"states"`
+	Ahead             int                 `json:"ahead"`
+	Behind            int                 `json:"behind"`
+	Dirty             []jsonWorkspace     `json:"dirty"`
+	Conflicted        []jsonWorkspace     `json:"conflicted"`
+	ExpiringSoon      []jsonWorkspace     `json:"expiringSoon"`
+	Broken            []jsonWorkspace     `json:"broken"`
+	CleanupCandidates []jsonRemovalAction `json:"cleanupCandidates"`
+}
+
 type jsonDoctorReport struct {
 	BrokenWorkspaces []jsonWorkspace `json:"brokenWorkspaces"`
 	Repairs          []jsonRepair    `json:"repairs"`
 	Warnings         []jsonWarning   `json:"warnings"`
+	Migration        *jsonMigration  `json:"migration,omitempty"`
+}
+
+type jsonMigration struct {
+	Path        string   `json:"path"`
+	FromVersion int      `json:"fromVersion"`
+	ToVersion   int      `json:"toVersion"`
+	Steps       []string `json:"steps"`
+	BackupPath  string   `json:"backupPath"`
 }
 
 type jsonRepair struct {
@@ -95,6 +201,66 @@ type jsonRepair struct {
 	Applied     bool   `json:"applied"`
 }
 
+type jsonJournalEntry struct {
+	Seq           int    `json:"seq"`
+	Time          string `json:"time"`
+	Operation     string `json:"operation"`
+	WorkspaceID   string `json:"workspaceId,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Path          string `json:"path,omitempty"`
+	Branch        string `json:"branch,omitempty"`
+	HeadSHA       string `json:"headSHA,omitempty"`
+	DeletedBranch bool   `json:"deletedBranch,omitempty"`
+	OnDirty       string `json:"onDirty,omitempty"`
+	StashRef      string `json:"stashRef,omitempty"`
+	PatchFile     string `json:"patchFile,omitempty"`
+	WIPCommit     string `json:"wipCommit,omitempty"`
+	TrashRef      string `json:"trashRef,omitempty"`
+	NewName       string `json:"newName,omitempty"`
+	Undoes        int    `json:"undoes,omitempty"`
+	UndoneBy      int    `json:"undoneBy,omitempty"`
+}
+
+type jsonUndoResult struct {
+	Entry     jsonJournalEntry `json:"entry"`
+	Workspace *jsonWorkspace   `json:"workspace,omitempty"`
+	Warnings  []jsonWarning    `json:"warnings"`
+}
+
+type jsonBootstrapFile struct {
+	Path    string `json:"path"`
+	Mode    string `json:"mode"`
+	Skipped bool   `json:"skipped"`
+}
+
+type jsonBootstrapResult struct {
+	Workspace jsonWorkspace       `json:"workspace"`
+	Source    string              `json:"source"`
+	Files     []jsonBootstrapFile `json:"files"`
+	Warnings  []jsonWarning       `json:"warnings"`
+}
+
+type jsonSeededDir struct {
+	Path    string `json:"path"`
+	Files   int    `json:"files"`
+	Bytes   int64  `json:"bytes"`
+	Method  string `json:"method,omitempty"`
+	Skipped bool   `json:"skipped"`
+}
+
+type jsonSeedResult struct {
+	Workspace jsonWorkspace   `json:"workspace"`
+	Source    jsonWorkspace   `json:"source"`
+	Dirs      []jsonSeededDir `json:"dirs"`
+	Bytes     int64           `json:"bytes"`
+	Warnings  []jsonWarning   `json:"warnings"`
+}
+
+type jsonEmptyTrashResult struct {
+	Purged   []jsonWorkspace `json:"purged"`
+	Warnings []jsonWarning   `json:"warnings"`
+}
+
 type jsonVersion struct {
 	Version string `json:"version"`
 	Commit  string `json:"commit"`
@@ -153,6 +319,107 @@ func (f *jsonFormatter) FormatWorkspacePath(workspace core.Workspace) string {
 	return workspace.Path
 }
 
+func (f *jsonFormatter) FormatCreateResult(result core.CreateResult) string {
+	jsonResult := jsonCreateResult{
+		jsonWorkspace: convertWorkspace(result.Workspace),
+		Bootstrap:     convertBootstrapFiles(result.Bootstrap),
+		Seed:          convertSeededDirs(result.Seed),
+		Hooks:         convertHookResults(result.Hooks),
+		Warnings:      convertWarnings(result.Warnings),
+	}
+
+	output := jsonOutput{
+		SchemaVersion: schemaVersion,
+		Data:          jsonResult,
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Sprintf(`{"schemaVersion": %d, "error": "failed to marshal JSON: %s"}`, schemaVersion, err)
+	}
+
+	return string(data)
+}
+
+func (f *jsonFormatter) FormatEnsureResult(result core.EnsureResult) string {
+	jsonResult := jsonEnsureResult{
+		jsonWorkspace: convertWorkspace(result.Workspace),
+		Created:       result.Created,
+		Bootstrap:     convertBootstrapFiles(result.Bootstrap),
+		Seed:          convertSeededDirs(result.Seed),
+		Hooks:         convertHookResults(result.Hooks),
+		Warnings:      convertWarnings(result.Warnings),
+	}
+
+	output := jsonOutput{
+		SchemaVersion: schemaVersion,
+		Data:          jsonResult,
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Sprintf(`{"schemaVersion": %d, "error": "failed to marshal JSON: %s"}`, schemaVersion, err)
+	}
+
+	return string(data)
+}
+
+func (f *jsonFormatter) FormatRemoveResult(result core.RemoveResult) string {
+	jsonResult := jsonRemoveResult{
+		Workspace:     convertWorkspace(result.Workspace),
+		Removed:       true,
+		Trashed:       result.Trashed,
+		DeletedBranch: result.DeletedBranch,
+		Hooks:         convertHookResults(result.Hooks),
+		Warnings:      convertWarnings(result.Warnings),
+	}
+
+	output := jsonOutput{
+		SchemaVersion: schemaVersion,
+		Data:          jsonResult,
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Sprintf(`{"schemaVersion": %d, "error": "failed to marshal JSON: %s"}`, schemaVersion, err)
+	}
+
+	return string(data)
+}
+
+func (f *jsonFormatter) FormatStatusReport(report core.StatusReport) string {
+	jsonReport := jsonStatusReport{
+		Total:             report.Total,
+		States:            report.States,
+		Ahead:             report.Ahead,
+		Behind:            report.Behind,
+		Dirty:             convertWorkspaces(report.Dirty),
+		Conflicted:        convertWorkspaces(report.Conflicted),
+		ExpiringSoon:      convertWorkspaces(report.ExpiringSoon),
+		Broken:            convertWorkspaces(report.Broken),
+		CleanupCandidates: make([]jsonRemovalAction, len(report.CleanupCandidates)),
+	}
+
+	for i, action := range report.CleanupCandidates {
+		jsonReport.CleanupCandidates[i] = jsonRemovalAction{
+			Workspace: convertWorkspace(action.Workspace),
+			Reason:    action.Reason,
+		}
+	}
+
+	output := jsonOutput{
+		SchemaVersion: schemaVersion,
+		Data:          jsonReport,
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Sprintf(`{"schemaVersion": %d, "error": "failed to marshal JSON: %s"}`, schemaVersion, err)
+	}
+
+	return string(data)
+}
+
 func (f *jsonFormatter) FormatCleanupPlan(plan core.CleanupPlan) string {
 	jsonPlan := jsonCleanupPlan{
 		Actions:  make([]jsonRemovalAction, len(plan.Actions)),
@@ -161,9 +428,12 @@ func (f *jsonFormatter) FormatCleanupPlan(plan core.CleanupPlan) string {
 
 	for i, action := range plan.Actions {
 		jsonPlan.Actions[i] = jsonRemovalAction{
-			Workspace: convertWorkspace(action.Workspace),
-			Reason:    action.Reason,
-			OnDirty:   action.OnDirty,
+			Workspace:     convertWorkspace(action.Workspace),
+			Reason:        action.Reason,
+			OnDirty:       action.OnDirty,
+			DeletedBranch: action.DeletedBranch,
+			Trashed:       action.Trashed,
+			Hooks:         convertHookResults(action.Hooks),
 		}
 	}
 
@@ -214,6 +484,16 @@ func (f *jsonFormatter) FormatDoctorReport(report core.DoctorReport) string {
 		}
 	}
 
+	if m := report.Migration; m != nil {
+		jsonReport.Migration = &jsonMigration{
+			Path:        m.Path,
+			FromVersion: m.FromVersion,
+			ToVersion:   m.ToVersion,
+			Steps:       m.Steps,
+			BackupPath:  m.BackupPath,
+		}
+	}
+
 	output := jsonOutput{
 		SchemaVersion: schemaVersion,
 		Data:          jsonReport,
@@ -268,6 +548,148 @@ func (f *jsonFormatter) FormatError(err error) string {
 	return string(data)
 }
 
+func (f *jsonFormatter) FormatJournal(entries []core.JournalEntry) string {
+	jsonEntries := make([]jsonJournalEntry, len(entries))
+	for i, entry := range entries {
+		jsonEntries[i] = convertJournalEntry(entry)
+	}
+
+	output := jsonOutput{
+		SchemaVersion: schemaVersion,
+		Data:          jsonEntries,
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Sprintf(`{"schemaVersion": %d, "error": "failed to marshal JSON: %s"}`, schemaVersion, err)
+	}
+
+	return string(data)
+}
+
+func (f *jsonFormatter) FormatUndoResult(result core.UndoResult) string {
+	jsonResult := jsonUndoResult{
+		Entry:    convertJournalEntry(result.Entry),
+		Warnings: convertWarnings(result.Warnings),
+	}
+	if result.Workspace.ID != "" || result.Workspace.Path != "" {
+		ws := convertWorkspace(result.Workspace)
+		jsonResult.Workspace = &ws
+	}
+
+	output := jsonOutput{
+		SchemaVersion: schemaVersion,
+		Data:          jsonResult,
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Sprintf(`{"schemaVersion": %d, "error": "failed to marshal JSON: %s"}`, schemaVersion, err)
+	}
+
+	return string(data)
+}
+
+func (f *jsonFormatter) FormatTrash(workspaces []core.Workspace) string {
+	output := jsonOutput{
+		SchemaVersion: schemaVersion,
+		Data:          convertWorkspaces(workspaces),
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Sprintf(`{"schemaVersion": %d, "error": "failed to marshal JSON: %s"}`, schemaVersion, err)
+	}
+
+	return string(data)
+}
+
+func (f *jsonFormatter) FormatBootstrapResult(result core.BootstrapResult) string {
+	jsonResult := jsonBootstrapResult{
+		Workspace: convertWorkspace(result.Workspace),
+		Source:    result.Source,
+		Files:     convertBootstrapFiles(result.Files),
+		Warnings:  convertWarnings(result.Warnings),
+	}
+
+	output := jsonOutput{
+		SchemaVersion: schemaVersion,
+		Data:          jsonResult,
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Sprintf(`{"schemaVersion": %d, "error": "failed to marshal JSON: %s"}`, schemaVersion, err)
+	}
+
+	return string(data)
+}
+
+func (f *jsonFormatter) FormatSeedResult(result core.SeedResult) string {
+	jsonResult := jsonSeedResult{
+		Workspace: convertWorkspace(result.Workspace),
+		Source:    convertWorkspace(result.Source),
+		Dirs:      convertSeededDirs(result.Dirs),
+		Bytes:     result.Bytes,
+		Warnings:  convertWarnings(result.Warnings),
+	}
+
+	output := jsonOutput{
+		SchemaVersion: schemaVersion,
+		Data:          jsonResult,
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Sprintf(`{"schemaVersion": %d, "error": "failed to marshal JSON: %s"}`, schemaVersion, err)
+	}
+
+	return string(data)
+}
+
+func (f *jsonFormatter) FormatSparseInfo(info *core.SparseInfo) string {
+	jsonResult := jsonSparseResult{Patterns: []string{}}
+	if info != nil {
+		jsonResult.Sparse = true
+		jsonResult.Profile = info.Profile
+		jsonResult.Cone = info.Cone
+		if info.Patterns != nil {
+			jsonResult.Patterns = info.Patterns
+		}
+	}
+
+	output := jsonOutput{
+		SchemaVersion: schemaVersion,
+		Data:          jsonResult,
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Sprintf(`{"schemaVersion": %d, "error": "failed to marshal JSON: %s"}`, schemaVersion, err)
+	}
+
+	return string(data)
+}
+
+func (f *jsonFormatter) FormatEmptyTrashResult(result core.EmptyTrashResult) string {
+	jsonResult := jsonEmptyTrashResult{
+		Purged:   convertWorkspaces(result.Purged),
+		Warnings: convertWarnings(result.Warnings),
+	}
+
+	output := jsonOutput{
+		SchemaVersion: schemaVersion,
+		Data:          jsonResult,
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Sprintf(`{"schemaVersion": %d, "error": "failed to marshal JSON: %s"}`, schemaVersion, err)
+	}
+
+	return string(data)
+}
+
 func (f *jsonFormatter) FormatVersion(version, commit, date string) string {
 	ver := jsonVersion{
 		Version: version,
@@ -304,6 +726,40 @@ func (f *jsonFormatter) FormatSuccess(message string) string {
 	return string(data)
 }
 
+func (f *jsonFormatter) FormatWorkspaceFields(workspaces []core.Workspace, fields []string) string {
+	projected := make([]map[string]interface{}, len(workspaces))
+	for i, ws := range workspaces {
+		doc := make(map[string]interface{})
+		for j, value := range projectWorkspace(ws, fields) {
+			// Rebuild nesting, e.g. "status.dirty" → {"status": {"dirty": ...}}
+			keys := strings.Split(fields[j], ".")
+			m := doc
+			for _, key := range keys[:len(keys)-1] {
+				child, ok := m[key].(map[string]interface{})
+				if !ok {
+					child = make(map[string]interface{})
+					m[key] = child
+				}
+				m = child
+			}
+			m[keys[len(keys)-1]] = value
+		}
+		projected[i] = doc
+	}
+
+	output := jsonOutput{
+		SchemaVersion: schemaVersion,
+		Data:          projected,
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Sprintf(`{"schemaVersion": %d, "error": "failed to marshal JSON: %s"}`, schemaVersion, err)
+	}
+
+	return string(data)
+}
+
 // Helper to convert core.Workspace to jsonWorkspace
 func convertWorkspace(ws core.Workspace) jsonWorkspace {
 	jsonWs := jsonWorkspace{
@@ -329,25 +785,170 @@ func convertWorkspace(ws core.Workspace) jsonWorkspace {
 			LastGitActivityAt: formatTimePtr(ws.Activity.LastGitActivityAt),
 		},
 		Status: jsonStatus{
-			Dirty:     ws.Status.Dirty,
-			Conflicts: ws.Status.Conflicts,
-			Ahead:     ws.Status.Ahead,
-			Behind:    ws.Status.Behind,
-			Branch:    ws.Status.Branch,
-			Detached:  ws.Status.Detached,
+			Dirty:         ws.Status.Dirty,
+			Conflicts:     ws.Status.Conflicts,
+			Ahead:         ws.Status.Ahead,
+			Behind:        ws.Status.Behind,
+			Branch:        ws.Status.Branch,
+			Detached:      ws.Status.Detached,
+			SubmoduleOnly: ws.Status.SubmoduleOnly,
+			Submodules:    convertSubmodules(ws.Status.Submodules),
 		},
+		Labels:    ws.Labels,
+		Fields:    ws.Fields,
+		CreatedAt: formatTimePtr(ws.CreatedAt),
+	}
+	if jsonWs.Labels == nil {
+		jsonWs.Labels = []string{}
+	}
+	if jsonWs.Fields == nil {
+		jsonWs.Fields = map[string]interface{}{}
 	}
 
 	if ws.Ephemeral != nil {
 		jsonWs.Ephemeral = &jsonEphemeral{
 			TTLSeconds: ws.Ephemeral.TTLSeconds,
 			ExpiresAt:  ws.Ephemeral.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
+			Sliding:    ws.Ephemeral.Sliding,
+		}
+	}
+
+	if ws.Seed != nil {
+		jsonWs.Seed = &jsonSeed{
+			FromID:   ws.Seed.FromID,
+			FromName: ws.Seed.FromName,
+			Dirs:     ws.Seed.Dirs,
+			Bytes:    ws.Seed.Bytes,
+			Method:   ws.Seed.Method,
+			SeededAt: ws.Seed.SeededAt.Format("2006-01-02T15:04:05Z07:00"),
+		}
+	}
+
+	if ws.Sparse != nil {
+		jsonWs.Sparse = &jsonSparse{
+			Profile:  ws.Sparse.Profile,
+			Patterns: ws.Sparse.Patterns,
+			Cone:     ws.Sparse.Cone,
+		}
+	}
+
+	if ws.Trash != nil {
+		jsonWs.Trash = &jsonTrash{
+			TrashedAt: ws.Trash.TrashedAt.Format("2006-01-02T15:04:05Z07:00"),
+			Dir:       ws.Trash.Dir,
 		}
 	}
 
 	return jsonWs
 }
 
+// Helper to convert workspaces to their JSON form
+func convertWorkspaces(workspaces []core.Workspace) []jsonWorkspace {
+	converted := make([]jsonWorkspace, len(workspaces))
+	for i, ws := range workspaces {
+		converted[i] = convertWorkspace(ws)
+	}
+	return converted
+}
+
+// Helper to convert submodule changes to their JSON form; nil when there
+// are none so the field is omitted
+func convertSubmodules(subs []core.SubmoduleStatus) []jsonSubmoduleStatus {
+	if len(subs) == 0 {
+		return nil
+	}
+	jsonSubs := make([]jsonSubmoduleStatus, len(subs))
+	for i, sub := range subs {
+		jsonSubs[i] = jsonSubmoduleStatus{
+			Path:          sub.Path,
+			CommitChanged: sub.CommitChanged,
+			Modified:      sub.Modified,
+			Untracked:     sub.Untracked,
+		}
+	}
+	return jsonSubs
+}
+
+// Helper to convert hook results to their JSON form
+func convertHookResults(results []core.HookResult) []jsonHookResult {
+	jsonResults := make([]jsonHookResult, len(results))
+	for i, r := range results {
+		jsonResults[i] = jsonHookResult{
+			Hook:       string(r.Hook),
+			Command:    r.Command,
+			ExitCode:   r.ExitCode,
+			Stdout:     r.Stdout,
+			Stderr:     r.Stderr,
+			DurationMs: r.Duration.Milliseconds(),
+			TimedOut:   r.TimedOut,
+		}
+	}
+	return jsonResults
+}
+
+// Helper to convert bootstrapped files to their JSON form
+func convertBootstrapFiles(files []core.BootstrapFile) []jsonBootstrapFile {
+	jsonFiles := make([]jsonBootstrapFile, len(files))
+	for i, file := range files {
+		jsonFiles[i] = jsonBootstrapFile{
+			Path:    file.Path,
+			Mode:    file.Mode,
+			Skipped: file.Skipped,
+		}
+	}
+	return jsonFiles
+}
+
+// Helper to convert seeded directories to their JSON form
+func convertSeededDirs(dirs []core.SeededDir) []jsonSeededDir {
+	jsonDirs := make([]jsonSeededDir, len(dirs))
+	for i, dir := range dirs {
+		jsonDirs[i] = jsonSeededDir{
+			Path:    dir.Path,
+			Files:   dir.Files,
+			Bytes:   dir.Bytes,
+			Method:  dir.Method,
+			Skipped: dir.Skipped,
+		}
+	}
+	return jsonDirs
+}
+
+// Helper to convert warnings to their JSON form
+func convertWarnings(warnings []core.Warning) []jsonWarning {
+	jsonWarnings := make([]jsonWarning, len(warnings))
+	for i, w := range warnings {
+		jsonWarnings[i] = jsonWarning{
+			Code:    w.Code,
+			Message: w.Message,
+		}
+	}
+	return jsonWarnings
+}
+
+// Helper to convert a journal entry to its JSON form
+func convertJournalEntry(entry core.JournalEntry) jsonJournalEntry {
+	return jsonJournalEntry{
+		Seq:           entry.Seq,
+		Time:          entry.Time.Format("2006-01-02T15:04:05Z07:00"),
+		Operation:     entry.Operation,
+		WorkspaceID:   entry.WorkspaceID,
+		Name:          entry.Name,
+		Path:          entry.Path,
+		Branch:        entry.Branch,
+		HeadSHA:       entry.HeadSHA,
+		DeletedBranch: entry.DeletedBranch,
+		OnDirty:       entry.OnDirty,
+		StashRef:      entry.StashRef,
+		PatchFile:     entry.PatchFile,
+		WIPCommit:     entry.WIPCommit,
+		TrashRef:      entry.TrashRef,
+		NewName:       entry.NewName,
+		Undoes:        entry.Undoes,
+		UndoneBy:      entry.UndoneBy,
+	}
+}
+
 func formatTimePtr(t *time.Time) *string {
 	if t == nil {
 		return nil
@@ -3,6 +3,7 @@ package output
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bmf/yagwt/internal/core"
 	"github.com/bmf/yagwt/internal/errors"
@@ -56,6 +57,23 @@ func (f *porcelainFormatter) FormatWorkspaces(workspaces []core.Workspace) strin
 	return b.String()
 }
 
+func (f *porcelainFormatter) FormatWorkspaceFields(workspaces []core.Workspace, fields []string) string {
+	var b strings.Builder
+
+	// Format: one column per requested field, in the requested order
+	for _, ws := range workspaces {
+		values := projectWorkspace(ws, fields)
+		columns := make([]string, len(values))
+		for i, value := range values {
+			columns[i] = formatFieldValue(value)
+		}
+		b.WriteString(strings.Join(columns, "\t"))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
 func (f *porcelainFormatter) FormatWorkspace(workspace core.Workspace) string {
 	// Single workspace: same format as list but one line
 	var flags []string
@@ -98,6 +116,57 @@ func (f *porcelainFormatter) FormatWorkspacePath(workspace core.Workspace) strin
 	return workspace.Path
 }
 
+func (f *porcelainFormatter) FormatCreateResult(result core.CreateResult) string {
+	// Same single line as FormatWorkspace so scripts can keep parsing it
+	return f.FormatWorkspace(result.Workspace)
+}
+
+func (f *porcelainFormatter) FormatEnsureResult(result core.EnsureResult) string {
+	// Format: created|existing\t<workspace line>
+	state := "existing"
+	if result.Created {
+		state = "created"
+	}
+	return state + "\t" + f.FormatWorkspace(result.Workspace)
+}
+
+func (f *porcelainFormatter) FormatRemoveResult(result core.RemoveResult) string {
+	if result.Trashed {
+		return f.FormatSuccess("Worktree moved to trash")
+	}
+	return f.FormatSuccess("Worktree removed successfully")
+}
+
+func (f *porcelainFormatter) FormatStatusReport(report core.StatusReport) string {
+	var b strings.Builder
+
+	// Format: one record per line, first field is the record type
+	b.WriteString(fmt.Sprintf("total\t%d\n", report.Total))
+	for _, state := range core.LifecycleStates {
+		b.WriteString(fmt.Sprintf("state\t%s\t%d\n", state, report.States[state]))
+	}
+	b.WriteString(fmt.Sprintf("ahead\t%d\n", report.Ahead))
+	b.WriteString(fmt.Sprintf("behind\t%d\n", report.Behind))
+
+	for _, ws := range report.Dirty {
+		b.WriteString(fmt.Sprintf("dirty\t%s\t%s\n", ws.ID, ws.Name))
+	}
+	for _, ws := range report.Conflicted {
+		b.WriteString(fmt.Sprintf("conflicts\t%s\t%s\n", ws.ID, ws.Name))
+	}
+	for _, ws := range report.ExpiringSoon {
+		b.WriteString(fmt.Sprintf("expiring\t%s\t%s\t%s\n", ws.ID, ws.Name, ws.Ephemeral.ExpiresAt.Format(time.RFC3339)))
+	}
+	for _, ws := range report.Broken {
+		b.WriteString(fmt.Sprintf("broken\t%s\t%s\n", ws.ID, ws.Name))
+	}
+	for _, action := range report.CleanupCandidates {
+		b.WriteString(fmt.Sprintf("cleanup\t%s\t%s\t%s\n", action.Workspace.ID, action.Workspace.Name, action.Reason))
+	}
+
+	return b.String()
+}
+
 func (f *porcelainFormatter) FormatCleanupPlan(plan core.CleanupPlan) string {
 	var b strings.Builder
 
@@ -146,6 +215,105 @@ func (f *porcelainFormatter) FormatDoctorReport(report core.DoctorReport) string
 	return b.String()
 }
 
+func (f *porcelainFormatter) FormatJournal(entries []core.JournalEntry) string {
+	var b strings.Builder
+
+	// Format: seq\ttime\toperation\tworkspace_id\tname\tpath\tundone_by
+	for _, entry := range entries {
+		undoneBy := ""
+		if entry.UndoneBy != 0 {
+			undoneBy = fmt.Sprintf("%d", entry.UndoneBy)
+		}
+		b.WriteString(fmt.Sprintf("%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.Seq,
+			entry.Time.Format(time.RFC3339),
+			entry.Operation,
+			entry.WorkspaceID,
+			entry.Name,
+			entry.Path,
+			undoneBy,
+		))
+	}
+
+	return b.String()
+}
+
+func (f *porcelainFormatter) FormatUndoResult(result core.UndoResult) string {
+	// Format: seq\tpath
+	return fmt.Sprintf("%d\t%s\n", result.Entry.Seq, result.Entry.Path)
+}
+
+func (f *porcelainFormatter) FormatTrash(workspaces []core.Workspace) string {
+	var b strings.Builder
+
+	// Format: id\tname\ttarget\ttrashed_at\tpath
+	for _, ws := range workspaces {
+		b.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\n",
+			ws.ID,
+			ws.Name,
+			ws.Target.Short,
+			ws.Trash.TrashedAt.Format(time.RFC3339),
+			ws.Path,
+		))
+	}
+
+	return b.String()
+}
+
+func (f *porcelainFormatter) FormatBootstrapResult(result core.BootstrapResult) string {
+	var b strings.Builder
+
+	// Format: mode\tcreated|skipped\tpath
+	for _, file := range result.Files {
+		state := "created"
+		if file.Skipped {
+			state = "skipped"
+		}
+		b.WriteString(fmt.Sprintf("%s\t%s\t%s\n", file.Mode, state, file.Path))
+	}
+
+	return b.String()
+}
+
+func (f *porcelainFormatter) FormatSeedResult(result core.SeedResult) string {
+	var b strings.Builder
+
+	// Format: seeded|skipped\tmethod\tbytes\tpath
+	for _, dir := range result.Dirs {
+		state := "seeded"
+		if dir.Skipped {
+			state = "skipped"
+		}
+		b.WriteString(fmt.Sprintf("%s\t%s\t%d\t%s\n", state, dir.Method, dir.Bytes, dir.Path))
+	}
+
+	return b.String()
+}
+
+func (f *porcelainFormatter) FormatSparseInfo(info *core.SparseInfo) string {
+	if info == nil {
+		return ""
+	}
+
+	// Format: one pattern per line
+	var b strings.Builder
+	for _, pattern := range info.Patterns {
+		b.WriteString(pattern + "\n")
+	}
+	return b.String()
+}
+
+func (f *porcelainFormatter) FormatEmptyTrashResult(result core.EmptyTrashResult) string {
+	var b strings.Builder
+
+	// Format: id\tpath, one line per purged workspace
+	for _, ws := range result.Purged {
+		b.WriteString(fmt.Sprintf("%s\t%s\n", ws.ID, ws.Path))
+	}
+
+	return b.String()
+}
+
 func (f *porcelainFormatter) FormatError(err error) string {
 	// Format: error_code\tmessage
 	if yerr, ok := err.(*errors.Error); ok {
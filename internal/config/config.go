@@ -1,12 +1,18 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bmf/yagwt/internal/errors"
+	"github.com/bmf/yagwt/internal/filter"
+	"github.com/bmf/yagwt/internal/naming"
 	"github.com/pelletier/go-toml/v2"
 )
 
@@ -15,13 +21,72 @@ type Config struct {
 	Workspace WorkspaceConfig `toml:"workspace"`
 	Cleanup   CleanupConfig   `toml:"cleanup"`
 	Hooks     HooksConfig     `toml:"hooks"`
+	List      ListConfig      `toml:"list"`
+	Trash     TrashConfig     `toml:"trash"`
+	Sparse    SparseConfig    `toml:"sparse"`
 }
 
 // WorkspaceConfig controls workspace creation
 type WorkspaceConfig struct {
-	RootStrategy string `toml:"rootStrategy"` // "sibling" or "inside"
-	RootDir      string `toml:"rootDir"`      // ".workspaces" (if inside)
-	NameTemplate string `toml:"nameTemplate"` // "{branch}" or custom
+	RootStrategy  string   `toml:"rootStrategy"`  // "sibling" or "inside"
+	RootDir       string   `toml:"rootDir"`       // ".workspaces" (if inside)
+	NameTemplate  string   `toml:"nameTemplate"`  // "{branch}" or custom like "{ticket}-{slug}"
+	PathTemplate  string   `toml:"pathTemplate"`  // directory under the root, "{name}" by default
+	TicketPattern string   `toml:"ticketPattern"` // regex extracting {ticket} from the branch
+	BaseBranch    string   `toml:"baseBranch"`    // merge target checked before deleting branches (default: primary's branch)
+	DefaultTTL    Duration `toml:"defaultTTL"`    // TTL for ephemeral workspaces when none is given
+	SlidingTTL    bool     `toml:"slidingTTL"`    // activity pushes ephemeral expiry forward
+
+	Bootstrap  BootstrapConfig `toml:"bootstrap"`
+	Seed       SeedConfig      `toml:"seed"`
+	Submodules SubmoduleConfig `toml:"submodules"`
+	LFS        LFSConfig       `toml:"lfs"`
+}
+
+// BootstrapConfig lists untracked files (.env, IDE settings, ...) to bring
+// into new workspaces. Patterns are globs relative to the workspace root.
+type BootstrapConfig struct {
+	From     string   `toml:"from"`     // workspace to copy from (default: the primary worktree)
+	Copy     []string `toml:"copy"`     // copied, directories recursively
+	Symlink  []string `toml:"symlink"`  // linked to the source path
+	Hardlink []string `toml:"hardlink"` // hardlinked, file by file
+}
+
+// SeedConfig lists heavy directories (build caches, dependencies) cloned
+// into new workspaces from an existing one
+type SeedConfig struct {
+	From     string   `toml:"from"`     // workspace to seed from (default: the primary worktree)
+	Dirs     []string `toml:"dirs"`     // globs relative to the workspace root
+	Fallback string   `toml:"fallback"` // "copy" or "hardlink" when reflinks are unsupported
+}
+
+// SubmoduleConfig controls whether new workspaces get their submodules
+// checked out
+type SubmoduleConfig struct {
+	Update    bool `toml:"update"`    // run "git submodule update --init" on create
+	Recursive bool `toml:"recursive"` // also update nested submodules
+}
+
+// LFSConfig lists Git LFS paths to download into new workspaces, for when
+// the LFS smudge filter is not installed
+type LFSConfig struct {
+	Include []string `toml:"include"` // patterns passed to "git lfs pull --include"
+}
+
+// SparseConfig holds named sparse-checkout profiles
+type SparseConfig struct {
+	Profiles map[string]SparseProfile `toml:"profiles"`
+}
+
+// SparseProfile is a named set of sparse-checkout patterns
+type SparseProfile struct {
+	Patterns []string `toml:"patterns"`
+	Cone     *bool    `toml:"cone"` // patterns are directories (default); false for gitignore-style patterns
+}
+
+// IsCone reports whether the profile uses cone mode
+func (p SparseProfile) IsCone() bool {
+	return p.Cone == nil || *p.Cone
 }
 
 // CleanupConfig defines cleanup policies
@@ -31,18 +96,66 @@ type CleanupConfig struct {
 
 // CleanupPolicy defines rules for cleanup
 type CleanupPolicy struct {
-	RemoveEphemeral bool          `toml:"removeEphemeral"`
-	IdleThreshold   time.Duration `toml:"idleThreshold"`
-	RespectPinned   bool          `toml:"respectPinned"`
-	OnDirty         string        `toml:"onDirty"` // fail, stash, patch, wip-commit
+	RemoveEphemeral bool     `toml:"removeEphemeral"`
+	IdleThreshold   Duration `toml:"idleThreshold"` // 0 disables idle removal
+	RespectPinned   *bool    `toml:"respectPinned"`
+	OnDirty         string   `toml:"onDirty"`      // fail, stash, patch, wip-commit
+	DeleteBranch    bool     `toml:"deleteBranch"` // also delete merged branches
+	Trash           *bool    `toml:"trash"`        // overrides trash.enabled for this policy
+	Match           string   `toml:"match"`        // filter expression limiting the candidates
 }
 
 // HooksConfig defines hook scripts
 type HooksConfig struct {
-	PostCreate string `toml:"postCreate"`
-	PreRemove  string `toml:"preRemove"`
-	PostRemove string `toml:"postRemove"`
-	PostOpen   string `toml:"postOpen"`
+	PostCreate string   `toml:"postCreate"`
+	PreRemove  string   `toml:"preRemove"`
+	PostRemove string   `toml:"postRemove"`
+	PostOpen   string   `toml:"postOpen"`
+	Timeout    Duration `toml:"timeout"` // per-hook execution limit
+}
+
+// ListConfig controls how workspace state is collected
+type ListConfig struct {
+	StatusConcurrency int `toml:"statusConcurrency"` // parallel git status calls
+}
+
+// TrashConfig controls soft deletion of removed workspaces
+type TrashConfig struct {
+	Enabled bool   `toml:"enabled"` // removals move workspaces to the trash
+	Dir     string `toml:"dir"`     // trash location, relative to the repo root (default: <gitDir>/yagwt/trash)
+}
+
+// Duration is a time.Duration that decodes from TOML strings such as
+// "30s", "12h" or "7d"
+type Duration time.Duration
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// ParseDuration parses a duration, adding support for a "d" (days) suffix
+// on top of the units accepted by time.ParseDuration
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
 }
 
 // Load loads configuration from multiple sources with precedence
@@ -109,33 +222,36 @@ func Load(repoRoot string, configPath string) (*Config, error) {
 func DefaultConfig() *Config {
 	return &Config{
 		Workspace: WorkspaceConfig{
-			RootStrategy: "sibling",
-			RootDir:      ".workspaces",
-			NameTemplate: "{branch}",
+			RootStrategy:  "sibling",
+			RootDir:       ".workspaces",
+			NameTemplate:  "{branch}",
+			PathTemplate:  "{name}",
+			TicketPattern: naming.DefaultTicketPattern,
+			DefaultTTL:    Duration(7 * 24 * time.Hour), // 7 days
 		},
 		Cleanup: CleanupConfig{
 			Policies: map[string]CleanupPolicy{
 				"default": {
 					RemoveEphemeral: true,
-					IdleThreshold:   30 * 24 * time.Hour, // 30 days
-					RespectPinned:   true,
+					IdleThreshold:   Duration(30 * 24 * time.Hour), // 30 days
 					OnDirty:         "fail",
 				},
 				"conservative": {
 					RemoveEphemeral: true,
-					IdleThreshold:   90 * 24 * time.Hour, // 90 days
-					RespectPinned:   true,
+					IdleThreshold:   Duration(90 * 24 * time.Hour), // 90 days
 					OnDirty:         "fail",
 				},
 				"aggressive": {
 					RemoveEphemeral: true,
-					IdleThreshold:   7 * 24 * time.Hour, // 7 days
-					RespectPinned:   false,
+					IdleThreshold:   Duration(7 * 24 * time.Hour), // 7 days
 					OnDirty:         "stash",
 				},
 			},
 		},
 		Hooks: HooksConfig{},
+		List: ListConfig{
+			StatusConcurrency: 8,
+		},
 	}
 }
 
@@ -175,6 +291,51 @@ func mergeConfig(base *Config, override *Config) *Config {
 	if override.Workspace.NameTemplate != "" {
 		result.Workspace.NameTemplate = override.Workspace.NameTemplate
 	}
+	if override.Workspace.PathTemplate != "" {
+		result.Workspace.PathTemplate = override.Workspace.PathTemplate
+	}
+	if override.Workspace.TicketPattern != "" {
+		result.Workspace.TicketPattern = override.Workspace.TicketPattern
+	}
+	if override.Workspace.BaseBranch != "" {
+		result.Workspace.BaseBranch = override.Workspace.BaseBranch
+	}
+	if override.Workspace.DefaultTTL != 0 {
+		result.Workspace.DefaultTTL = override.Workspace.DefaultTTL
+	}
+	if override.Workspace.SlidingTTL {
+		result.Workspace.SlidingTTL = true
+	}
+	if override.Workspace.Bootstrap.From != "" {
+		result.Workspace.Bootstrap.From = override.Workspace.Bootstrap.From
+	}
+	if override.Workspace.Bootstrap.Copy != nil {
+		result.Workspace.Bootstrap.Copy = override.Workspace.Bootstrap.Copy
+	}
+	if override.Workspace.Bootstrap.Symlink != nil {
+		result.Workspace.Bootstrap.Symlink = override.Workspace.Bootstrap.Symlink
+	}
+	if override.Workspace.Bootstrap.Hardlink != nil {
+		result.Workspace.Bootstrap.Hardlink = override.Workspace.Bootstrap.Hardlink
+	}
+	if override.Workspace.Seed.From != "" {
+		result.Workspace.Seed.From = override.Workspace.Seed.From
+	}
+	if override.Workspace.Seed.Dirs != nil {
+		result.Workspace.Seed.Dirs = override.Workspace.Seed.Dirs
+	}
+	if override.Workspace.Seed.Fallback != "" {
+		result.Workspace.Seed.Fallback = override.Workspace.Seed.Fallback
+	}
+	if override.Workspace.Submodules.Update {
+		result.Workspace.Submodules.Update = true
+	}
+	if override.Workspace.Submodules.Recursive {
+		result.Workspace.Submodules.Recursive = true
+	}
+	if override.Workspace.LFS.Include != nil {
+		result.Workspace.LFS.Include = override.Workspace.LFS.Include
+	}
 
 	// Merge cleanup policies
 	if override.Cleanup.Policies != nil {
@@ -186,6 +347,16 @@ func mergeConfig(base *Config, override *Config) *Config {
 		}
 	}
 
+	// Merge sparse profiles
+	if override.Sparse.Profiles != nil {
+		if result.Sparse.Profiles == nil {
+			result.Sparse.Profiles = make(map[string]SparseProfile)
+		}
+		for name, profile := range override.Sparse.Profiles {
+			result.Sparse.Profiles[name] = profile
+		}
+	}
+
 	// Merge hooks
 	if override.Hooks.PostCreate != "" {
 		result.Hooks.PostCreate = override.Hooks.PostCreate
@@ -199,10 +370,44 @@ func mergeConfig(base *Config, override *Config) *Config {
 	if override.Hooks.PostOpen != "" {
 		result.Hooks.PostOpen = override.Hooks.PostOpen
 	}
+	if override.Hooks.Timeout != 0 {
+		result.Hooks.Timeout = override.Hooks.Timeout
+	}
+
+	// Merge trash settings
+	if override.Trash.Enabled {
+		result.Trash.Enabled = true
+	}
+	if override.Trash.Dir != "" {
+		result.Trash.Dir = override.Trash.Dir
+	}
+
+	// Merge list settings
+	if override.List.StatusConcurrency != 0 {
+		result.List.StatusConcurrency = override.List.StatusConcurrency
+	}
 
 	return &result
 }
 
+// ValidateWorkspacePattern checks that a bootstrap or seed glob is well
+// formed and stays inside the workspace
+func ValidateWorkspacePattern(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("empty pattern")
+	}
+	if filepath.IsAbs(pattern) {
+		return fmt.Errorf("pattern must be relative to the workspace root")
+	}
+	for _, part := range strings.Split(filepath.ToSlash(pattern), "/") {
+		if part == ".." || part == ".git" {
+			return fmt.Errorf("pattern must not contain %q", part)
+		}
+	}
+	_, err := filepath.Match(pattern, "")
+	return err
+}
+
 // validateConfig validates the configuration
 func validateConfig(config *Config) error {
 	// Validate root strategy
@@ -212,6 +417,75 @@ func validateConfig(config *Config) error {
 			WithDetail("valid", "sibling, inside")
 	}
 
+	// Validate name and path templates
+	templates := map[string]string{
+		"nameTemplate": config.Workspace.NameTemplate,
+		"pathTemplate": config.Workspace.PathTemplate,
+	}
+	for key, tmpl := range templates {
+		if err := naming.Validate(tmpl); err != nil {
+			return errors.WrapError(errors.ErrConfig, "invalid "+key, err).
+				WithDetail("value", tmpl)
+		}
+	}
+
+	// Validate ticket pattern
+	if _, err := regexp.Compile(config.Workspace.TicketPattern); err != nil {
+		return errors.WrapError(errors.ErrConfig, "invalid ticketPattern", err).
+			WithDetail("value", config.Workspace.TicketPattern)
+	}
+
+	// Validate bootstrap patterns
+	bootstrap := map[string][]string{
+		"copy":     config.Workspace.Bootstrap.Copy,
+		"symlink":  config.Workspace.Bootstrap.Symlink,
+		"hardlink": config.Workspace.Bootstrap.Hardlink,
+	}
+	for key, patterns := range bootstrap {
+		for _, pattern := range patterns {
+			if err := ValidateWorkspacePattern(pattern); err != nil {
+				return errors.WrapError(errors.ErrConfig, "invalid workspace.bootstrap."+key+" pattern", err).
+					WithDetail("value", pattern)
+			}
+		}
+	}
+
+	// Validate seed settings
+	for _, pattern := range config.Workspace.Seed.Dirs {
+		if err := ValidateWorkspacePattern(pattern); err != nil {
+			return errors.WrapError(errors.ErrConfig, "invalid workspace.seed.dirs pattern", err).
+				WithDetail("value", pattern)
+		}
+	}
+	switch config.Workspace.Seed.Fallback {
+	case "", "copy", "hardlink":
+	default:
+		return errors.NewError(errors.ErrConfig, "invalid workspace.seed.fallback").
+			WithDetail("value", config.Workspace.Seed.Fallback).
+			WithDetail("valid", "copy, hardlink")
+	}
+
+	// Validate LFS patterns; they are joined with commas for git lfs
+	for _, pattern := range config.Workspace.LFS.Include {
+		if strings.TrimSpace(pattern) == "" || strings.Contains(pattern, ",") {
+			return errors.NewError(errors.ErrConfig, "invalid workspace.lfs.include pattern").
+				WithDetail("value", pattern).
+				WithHint("List each pattern separately; patterns cannot contain ','", "")
+		}
+	}
+
+	for name, profile := range config.Sparse.Profiles {
+		if len(profile.Patterns) == 0 {
+			return errors.NewError(errors.ErrConfig, "sparse profile has no patterns").
+				WithDetail("profile", name)
+		}
+	}
+
+	if config.List.StatusConcurrency < 1 {
+		return errors.NewError(errors.ErrConfig, "list.statusConcurrency must be at least 1").
+			WithDetail("value", config.List.StatusConcurrency)
+	}
+
 	// Validate onDirty values in policies
 	validOnDirty := map[string]bool{
 		"fail":       true,
@@ -227,6 +501,13 @@ func validateConfig(config *Config) error {
 				WithDetail("value", policy.OnDirty).
 				WithDetail("valid", "fail, stash, patch, wip-commit")
 		}
+		if policy.Match != "" {
+			if _, err := filter.ParseFilter(policy.Match); err != nil {
+				return errors.WrapError(errors.ErrConfig, "invalid match filter in cleanup policy", err).
+					WithDetail("policy", name).
+					WithDetail("value", policy.Match)
+			}
+		}
 	}
 
 	return nil
@@ -191,6 +191,33 @@ func TestValidateOnDirty(t *testing.T) {
 	}
 }
 
+func TestValidatePolicyMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		match   string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"label", "label:scratch", false},
+		{"field", "meta.ticket~^ABC-", false},
+		{"invalid", "label>3", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Cleanup.Policies["test"] = CleanupPolicy{
+				Match: tt.match,
+			}
+
+			err := validateConfig(config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestInvalidTOML(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "config.toml")
@@ -302,22 +329,22 @@ func TestCleanupPolicyDuration(t *testing.T) {
 	defaultPolicy := config.Cleanup.Policies["default"]
 	expectedDuration := 30 * 24 * time.Hour
 
-	if defaultPolicy.IdleThreshold != expectedDuration {
-		t.Errorf("Expected idle threshold %v, got %v", expectedDuration, defaultPolicy.IdleThreshold)
+	if time.Duration(defaultPolicy.IdleThreshold) != expectedDuration {
+		t.Errorf("Expected idle threshold %v, got %v", expectedDuration, time.Duration(defaultPolicy.IdleThreshold))
 	}
 
 	conservativePolicy := config.Cleanup.Policies["conservative"]
 	expectedDuration = 90 * 24 * time.Hour
 
-	if conservativePolicy.IdleThreshold != expectedDuration {
-		t.Errorf("Expected conservative idle threshold %v, got %v", expectedDuration, conservativePolicy.IdleThreshold)
+	if time.Duration(conservativePolicy.IdleThreshold) != expectedDuration {
+		t.Errorf("Expected conservative idle threshold %v, got %v", expectedDuration, time.Duration(conservativePolicy.IdleThreshold))
 	}
 
 	aggressivePolicy := config.Cleanup.Policies["aggressive"]
 	expectedDuration = 7 * 24 * time.Hour
 
-	if aggressivePolicy.IdleThreshold != expectedDuration {
-		t.Errorf("Expected aggressive idle threshold %v, got %v", expectedDuration, aggressivePolicy.IdleThreshold)
+	if time.Duration(aggressivePolicy.IdleThreshold) != expectedDuration {
+		t.Errorf("Expected aggressive idle threshold %v, got %v", expectedDuration, time.Duration(aggressivePolicy.IdleThreshold))
 	}
 }
 
@@ -363,3 +390,300 @@ postOpen = "/usr/local/bin/post-open.sh"
 		t.Errorf("Expected postOpen hook, got %q", config.Hooks.PostOpen)
 	}
 }
+
+func TestHooksTimeout(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.toml")
+
+	configContent := `
+[hooks]
+postCreate = "npm install"
+timeout = "2m"
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	config, err := Load("", configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if time.Duration(config.Hooks.Timeout) != 2*time.Minute {
+		t.Errorf("Expected hook timeout 2m, got %v", time.Duration(config.Hooks.Timeout))
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30s", 30 * time.Second, false},
+		{"12h", 12 * time.Hour, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"xd", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDuration(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDuration(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDuration(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomCleanupPolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.toml")
+
+	configContent := `
+[cleanup.policies.ci]
+removeEphemeral = true
+idleThreshold = "2d"
+respectPinned = true
+onDirty = "wip-commit"
+
+[cleanup.policies.scratch]
+idleThreshold = "1d"
+
+[cleanup.policies.sweep]
+respectPinned = false
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	config, err := Load("", configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	ci, ok := config.Cleanup.Policies["ci"]
+	if !ok {
+		t.Fatal("Expected ci policy to be loaded")
+	}
+
+	if time.Duration(ci.IdleThreshold) != 48*time.Hour {
+		t.Errorf("Expected idle threshold 48h, got %v", time.Duration(ci.IdleThreshold))
+	}
+
+	if ci.OnDirty != "wip-commit" {
+		t.Errorf("Expected onDirty wip-commit, got %q", ci.OnDirty)
+	}
+
+	// respectPinned is only set when written, so a missing key can keep
+	// pinned workspaces while an explicit false still gets through
+	if ci.RespectPinned == nil || !*ci.RespectPinned {
+		t.Errorf("Expected ci respectPinned true, got %v", ci.RespectPinned)
+	}
+	if scratch := config.Cleanup.Policies["scratch"]; scratch.RespectPinned != nil {
+		t.Errorf("Expected scratch respectPinned unset, got %v", *scratch.RespectPinned)
+	}
+	if sweep := config.Cleanup.Policies["sweep"]; sweep.RespectPinned == nil || *sweep.RespectPinned {
+		t.Errorf("Expected sweep respectPinned false, got %v", sweep.RespectPinned)
+	}
+
+	// Built-in policies remain available
+	if _, ok := config.Cleanup.Policies["default"]; !ok {
+		t.Error("Expected default policy to be preserved")
+	}
+}
+
+func TestStatusConcurrency(t *testing.T) {
+	if got := DefaultConfig().List.StatusConcurrency; got != 8 {
+		t.Errorf("Expected default statusConcurrency 8, got %d", got)
+	}
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.toml")
+
+	if err := os.WriteFile(configPath, []byte("[list]\nstatusConcurrency = 2\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	config, err := Load("", configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.List.StatusConcurrency != 2 {
+		t.Errorf("Expected statusConcurrency 2, got %d", config.List.StatusConcurrency)
+	}
+
+	if err := os.WriteFile(configPath, []byte("[list]\nstatusConcurrency = -1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	_, err = Load("", configPath)
+	if err == nil {
+		t.Fatal("Expected error for negative statusConcurrency")
+	}
+	if coreErr, ok := err.(*errors.Error); !ok || coreErr.Code != errors.ErrConfig {
+		t.Errorf("Expected ErrConfig, got %v", err)
+	}
+}
+
+func TestTrashConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.toml")
+
+	content := `
+[trash]
+enabled = true
+dir = ".trash"
+
+[cleanup.policies.nightly]
+removeEphemeral = true
+trash = false
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	config, err := Load("", configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !config.Trash.Enabled || config.Trash.Dir != ".trash" {
+		t.Errorf("Trash = %+v, want enabled with dir .trash", config.Trash)
+	}
+
+	nightly := config.Cleanup.Policies["nightly"].Trash
+	if nightly == nil || *nightly {
+		t.Errorf("nightly policy trash = %v, want explicit false", nightly)
+	}
+	if config.Cleanup.Policies["default"].Trash != nil {
+		t.Error("default policy should inherit trash.enabled")
+	}
+}
+
+func TestBootstrapConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.toml")
+
+	content := `
+[workspace.bootstrap]
+from = "template"
+copy = [".env", ".vscode"]
+symlink = ["node_modules"]
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	config, err := Load("", configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	bootstrap := config.Workspace.Bootstrap
+	if bootstrap.From != "template" || len(bootstrap.Copy) != 2 || len(bootstrap.Symlink) != 1 {
+		t.Errorf("Bootstrap = %+v", bootstrap)
+	}
+
+	for _, pattern := range []string{"../secrets", "/etc/hosts", ".git/config", "[a-"} {
+		config := DefaultConfig()
+		config.Workspace.Bootstrap.Copy = []string{pattern}
+		if err := validateConfig(config); err == nil {
+			t.Errorf("validateConfig() should reject bootstrap pattern %q", pattern)
+		}
+	}
+}
+
+func TestSeedConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		dirs     []string
+		fallback string
+		wantErr  bool
+	}{
+		{"defaults", []string{"node_modules", "packages/*/node_modules"}, "", false},
+		{"hardlink", []string{"target"}, "hardlink", false},
+		{"bad fallback", []string{"target"}, "symlink", true},
+		{"escaping dir", []string{"../cache"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Workspace.Seed = SeedConfig{Dirs: tt.dirs, Fallback: tt.fallback}
+
+			err := validateConfig(config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSparseProfiles(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.toml")
+	content := `
+[sparse.profiles.frontend]
+patterns = ["apps/web", "libs/ui"]
+
+[sparse.profiles.docs]
+patterns = ["docs/*.md"]
+cone = false
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := Load("", configPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	frontend := config.Sparse.Profiles["frontend"]
+	if len(frontend.Patterns) != 2 || !frontend.IsCone() {
+		t.Errorf("frontend = %+v, want 2 cone patterns", frontend)
+	}
+	if config.Sparse.Profiles["docs"].IsCone() {
+		t.Error("docs profile should not use cone mode")
+	}
+
+	config.Sparse.Profiles["empty"] = SparseProfile{}
+	if err := validateConfig(config); err == nil {
+		t.Error("validateConfig() should reject a profile without patterns")
+	}
+}
+
+func TestSubmoduleAndLFSConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.toml")
+	content := `
+[workspace.submodules]
+update = true
+
+[workspace.lfs]
+include = ["assets/**", "*.psd"]
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := Load("", configPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !config.Workspace.Submodules.Update || config.Workspace.Submodules.Recursive {
+		t.Errorf("Submodules = %+v, want update without recursion", config.Workspace.Submodules)
+	}
+	if len(config.Workspace.LFS.Include) != 2 {
+		t.Errorf("LFS.Include = %v", config.Workspace.LFS.Include)
+	}
+
+	config.Workspace.LFS.Include = []string{"assets/**,*.psd"}
+	if err := validateConfig(config); err == nil {
+		t.Error("validateConfig() should reject an LFS pattern containing ','")
+	}
+}
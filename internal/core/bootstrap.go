@@ -0,0 +1,279 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/bmf/yagwt/internal/config"
+	"github.com/bmf/yagwt/internal/seed"
+)
+
+// Bootstrap modes
+const (
+	BootstrapCopy     = "copy"
+	BootstrapSymlink  = "symlink"
+	BootstrapHardlink = "hardlink"
+)
+
+// BootstrapOptions specifies parameters for bootstrapping a workspace
+type BootstrapOptions struct {
+	From  string // selector of the source workspace; overrides workspace.bootstrap.from
+	Force bool   // replace files that already exist in the workspace
+}
+
+// BootstrapFile describes one path brought into a workspace
+type BootstrapFile struct {
+	Path    string // relative to the workspace root
+	Mode    string // copy, symlink or hardlink
+	Skipped bool   // already present in the workspace, left untouched
+}
+
+// BootstrapResult describes the outcome of bootstrapping a workspace
+type BootstrapResult struct {
+	Workspace Workspace
+	Source    string // path of the workspace files were taken from
+	Files     []BootstrapFile
+	Warnings  []Warning
+}
+
+// Bootstrap copies, symlinks or hardlinks the untracked files configured
+// under [workspace.bootstrap] from the source workspace into a workspace
+func (e *engine) Bootstrap(selector Selector, opts BootstrapOptions) (result BootstrapResult, err error) {
+	err = e.withLock(writeLockTimeout, func() error {
+		ws, err := e.Locate(selector)
+		if err != nil {
+			return err
+		}
+
+		result, err = e.bootstrap(ws, opts)
+		return err
+	})
+	return result, err
+}
+
+// bootstrap implements Bootstrap; the caller must hold the engine lock
+func (e *engine) bootstrap(ws Workspace, opts BootstrapOptions) (BootstrapResult, error) {
+	result := BootstrapResult{Workspace: ws}
+
+	cfg := e.config.Workspace.Bootstrap
+	if len(cfg.Copy) == 0 && len(cfg.Symlink) == 0 && len(cfg.Hardlink) == 0 {
+		return result, nil
+	}
+
+	from := opts.From
+	if from == "" {
+		from = cfg.From
+	}
+	source, err := e.sourceWorkspace(from)
+	if err != nil {
+		return result, err
+	}
+	if normalizePath(source.Path) == normalizePath(ws.Path) {
+		return result, NewError(ErrConfig, "cannot bootstrap a workspace from itself").
+			WithDetail("name", ws.Name).
+			WithHint("Pick another source workspace", "yagwt bootstrap "+ws.Name+" --from <selector>")
+	}
+	result.Source = source.Path
+
+	modes := []struct {
+		mode     string
+		patterns []string
+	}{
+		{BootstrapCopy, cfg.Copy},
+		{BootstrapSymlink, cfg.Symlink},
+		{BootstrapHardlink, cfg.Hardlink},
+	}
+
+	seen := make(map[string]bool)
+	for _, m := range modes {
+		paths, warnings := matchWorkspacePaths("bootstrap", source.Path, ws.Path, m.patterns, opts.Force, seen)
+		result.Warnings = append(result.Warnings, warnings...)
+
+		for _, p := range paths {
+			file := BootstrapFile{Path: p.Rel, Mode: m.mode, Skipped: p.Skipped}
+			if !p.Skipped {
+				if err := bootstrapPath(p.Src, p.Dst, m.mode); err != nil {
+					result.Warnings = append(result.Warnings, pathWarning("bootstrap", p.Rel, err))
+					continue
+				}
+			}
+			result.Files = append(result.Files, file)
+		}
+	}
+
+	return result, nil
+}
+
+// sourceWorkspace returns the workspace matching from, or the primary
+// worktree when from is empty
+func (e *engine) sourceWorkspace(from string) (Workspace, error) {
+	if from != "" {
+		return e.Locate(ParseSelector(from))
+	}
+
+	workspaces, err := e.List(ListOptions{SkipStatus: true})
+	if err != nil {
+		return Workspace{}, err
+	}
+	for _, ws := range workspaces {
+		if ws.IsPrimary {
+			return ws, nil
+		}
+	}
+
+	return Workspace{}, NewError(ErrNotFound, "no primary worktree to copy from").
+		WithHint("Name a source workspace with --from", "")
+}
+
+// workspacePath is a path matched in a source workspace and where it goes
+// in the destination workspace
+type workspacePath struct {
+	Rel     string // relative to both workspace roots
+	Src     string
+	Dst     string
+	Skipped bool // Dst already exists and force was not set
+}
+
+// matchWorkspacePaths expands bootstrap or seed patterns (op names which)
+// in srcRoot, leaving out paths already in seen. Existing destinations are
+// skipped or, with force, deleted. Patterns and matches that would reach
+// outside dstRoot are refused with a warning.
+func matchWorkspacePaths(op, srcRoot, dstRoot string, patterns []string, force bool, seen map[string]bool) ([]workspacePath, []Warning) {
+	var paths []workspacePath
+	var warnings []Warning
+
+	for _, pattern := range patterns {
+		matches, err := workspaceGlob(srcRoot, pattern)
+		if err != nil {
+			warnings = append(warnings, Warning{
+				Code:    op + "_failed",
+				Message: "Invalid " + op + " pattern '" + pattern + "': " + err.Error(),
+			})
+			continue
+		}
+
+		for _, src := range matches {
+			rel, err := filepath.Rel(srcRoot, src)
+			if err != nil || seen[rel] {
+				continue
+			}
+			seen[rel] = true
+
+			p := workspacePath{Rel: rel, Src: src, Dst: filepath.Join(dstRoot, rel)}
+			if !filepath.IsLocal(rel) || !insideDir(dstRoot, filepath.Dir(p.Dst)) {
+				warnings = append(warnings, Warning{
+					Code:    op + "_failed",
+					Message: "Refusing to " + op + " '" + rel + "': it leads outside the workspace",
+				})
+				continue
+			}
+
+			if _, err := os.Lstat(p.Dst); err == nil {
+				if !force {
+					p.Skipped = true
+					paths = append(paths, p)
+					continue
+				}
+				if err := os.RemoveAll(p.Dst); err != nil {
+					warnings = append(warnings, pathWarning(op, rel, err))
+					continue
+				}
+			}
+			paths = append(paths, p)
+		}
+	}
+
+	return paths, warnings
+}
+
+// workspaceGlob returns the sorted matches of pattern in root. Patterns
+// passed on the command line skip config validation, so check them here.
+func workspaceGlob(root, pattern string) ([]string, error) {
+	if err := config.ValidateWorkspacePattern(pattern); err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(filepath.Join(root, pattern))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// insideDir reports whether path is root or below it once symlinks in the
+// part of path that exists are resolved
+func insideDir(root, path string) bool {
+	root, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return false
+	}
+
+	missing := ""
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			path = filepath.Join(resolved, missing)
+			break
+		}
+		if !os.IsNotExist(err) {
+			return false
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return false
+		}
+		missing = filepath.Join(filepath.Base(path), missing)
+		path = parent
+	}
+
+	rel, err := filepath.Rel(root, path)
+	return err == nil && filepath.IsLocal(rel)
+}
+
+// pathWarning reports a path that could not be bootstrapped or seeded
+func pathWarning(op, path string, err error) Warning {
+	return Warning{
+		Code:    op + "_failed",
+		Message: "Failed to " + op + " '" + path + "': " + err.Error(),
+	}
+}
+
+// bootstrapPath brings src to dst using mode. Directories are copied or
+// hardlinked recursively; symlinks inside them are recreated as is.
+func bootstrapPath(src, dst, mode string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	if mode == BootstrapSymlink {
+		return os.Symlink(src, dst)
+	}
+
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case mode == BootstrapHardlink:
+			return os.Link(path, target)
+		default:
+			return seed.CopyFile(path, target, info.Mode().Perm())
+		}
+	})
+}
@@ -63,6 +63,10 @@ type activityAdapter struct {
 	activity ActivityInfo
 }
 
+func (a *activityAdapter) GetLastOpenedAt() *time.Time {
+	return a.activity.LastOpenedAt
+}
+
 func (a *activityAdapter) GetLastGitActivityAt() *time.Time {
 	return a.activity.LastGitActivityAt
 }
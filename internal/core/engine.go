@@ -1,15 +1,23 @@
 package core
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bmf/yagwt/internal/cleanup"
 	"github.com/bmf/yagwt/internal/config"
+	"github.com/bmf/yagwt/internal/filter"
 	"github.com/bmf/yagwt/internal/git"
+	"github.com/bmf/yagwt/internal/hooks"
+	"github.com/bmf/yagwt/internal/journal"
 	"github.com/bmf/yagwt/internal/lock"
 	"github.com/bmf/yagwt/internal/metadata"
+	"github.com/bmf/yagwt/internal/naming"
 	"github.com/google/uuid"
 )
 
@@ -18,53 +26,105 @@ type WorkspaceManager interface {
 	// Read operations (lock-free)
 	List(opts ListOptions) ([]Workspace, error)
 	Get(selector Selector) (Workspace, error)
+	Locate(selector Selector) (Workspace, error)
 	Resolve(ref string) ([]Workspace, error)
+	Status(opts StatusOptions) (StatusReport, error)
 
 	// Write operations (acquire lock)
-	Create(opts CreateOptions) (Workspace, error)
-	Remove(selector Selector, opts RemoveOptions) error
+	Create(opts CreateOptions) (CreateResult, error)
+	Ensure(opts CreateOptions) (EnsureResult, error)
+	Remove(selector Selector, opts RemoveOptions) (RemoveResult, error)
 	Rename(selector Selector, newName string) error
 	Move(selector Selector, newPath string) error
 	Pin(selector Selector) error
 	Unpin(selector Selector) error
 	Lock(selector Selector) error
 	Unlock(selector Selector) error
+	AddLabels(selector Selector, labels []string) error
+	RemoveLabels(selector Selector, labels []string) error
+	SetFields(selector Selector, fields map[string]interface{}) error
+	UnsetFields(selector Selector, keys []string) error
+	Bootstrap(selector Selector, opts BootstrapOptions) (BootstrapResult, error)
+	Seed(selector Selector, opts SeedOptions) (SeedResult, error)
+	SparsePatterns(selector Selector) (*SparseInfo, error)
+	AddSparsePatterns(selector Selector, patterns []string) (*SparseInfo, error)
+	RemoveSparsePatterns(selector Selector, patterns []string) (*SparseInfo, error)
+	SetEphemeral(selector Selector, opts EphemeralOptions) error
+	SetPermanent(selector Selector) error
+	Renew(selector Selector, ttl time.Duration) error
+	Touch(selector Selector) (TouchResult, error)
 
 	// Maintenance operations
 	Cleanup(opts CleanupOptions) (CleanupPlan, error)
 	Doctor(opts DoctorOptions) (DoctorReport, error)
+
+	// Journal operations
+	Log(opts LogOptions) ([]JournalEntry, error)
+	Undo(seq int) (UndoResult, error)
+
+	// Trash operations
+	ListTrash() ([]Workspace, error)
+	Restore(selector Selector) (Workspace, error)
+	EmptyTrash(opts EmptyTrashOptions) (EmptyTrashResult, error)
 }
 
 // ListOptions specifies parameters for listing workspaces
 type ListOptions struct {
-	Filter string
-	All    bool
-	Fields []string
+	Filter     string // filter expression, e.g. "flag:pinned,status:dirty"
+	All        bool
+	Fields     []string // fields the caller needs (see WorkspaceFields); empty = all
+	SkipStatus bool     // identity fields only: no git status or activity refresh
+
+	paths map[string]bool // normalized paths to restrict to; nil = all
+}
+
+// StatusOptions specifies parameters for the status dashboard
+type StatusOptions struct {
+	Selector       string        // restrict to matching workspaces (empty = all)
+	ExpiringWithin time.Duration // window for "expiring soon" (0 = 24h)
 }
 
 // CreateOptions specifies parameters for creating a workspace
 type CreateOptions struct {
-	Target    string
-	Name      string
-	Dir       string
-	Base      string
-	NewBranch bool
-	Existing  bool
-	Detached  bool
-	Ephemeral bool
-	TTL       time.Duration
-	Pin       bool
-	Checkout  bool
+	Target     string
+	Name       string
+	Dir        string
+	Base       string
+	NewBranch  bool
+	Existing   bool
+	Detached   bool
+	Ephemeral  bool
+	TTL        time.Duration
+	Pin        bool
+	NoCheckout bool // register the worktree without populating its files
+
+	// Sparse checkout: patterns from the named [sparse.profiles] entry plus
+	// Sparse. NoCone switches to gitignore-style patterns.
+	SparseProfile string
+	Sparse        []string
+	NoCone        bool
+
+	SeedProgress func(SeedProgress) // reports seeding of workspace.seed.dirs, if set
+}
+
+// EphemeralOptions specifies parameters for making a workspace ephemeral
+type EphemeralOptions struct {
+	TTL     time.Duration // 0 uses workspace.defaultTTL
+	Sliding bool          // activity pushes expiry forward (also enabled by workspace.slidingTTL)
 }
 
 // RemoveOptions specifies parameters for removing a workspace
 type RemoveOptions struct {
-	DeleteBranch bool
-	KeepBranch   bool
-	OnDirty      string // fail, stash, patch, wip-commit, force
-	PatchDir     string
-	WipMessage   string
-	NoPrompt     bool
+	DeleteBranch      bool
+	ForceDeleteBranch bool // delete the branch even if it has unmerged commits
+	KeepBranch        bool
+	OnDirty           string // fail, stash, patch, wip-commit, force
+	PatchDir          string
+	WipMessage        string
+	NoPrompt          bool
+	Trash             bool // move to the trash even if trash.enabled is off
+	NoTrash           bool // delete permanently even if trash.enabled is on
+	IgnorePinned      bool // remove pinned workspaces too (policies with respectPinned = false)
 }
 
 // CleanupOptions specifies parameters for cleanup operations
@@ -79,6 +139,18 @@ type CleanupOptions struct {
 type DoctorOptions struct {
 	DryRun        bool
 	ForgetMissing bool
+	Migrate       bool // only plan the metadata schema migration; never writes
+}
+
+// LogOptions specifies parameters for reading the operation journal
+type LogOptions struct {
+	Limit int // newest entries to return (0 = all)
+}
+
+// EmptyTrashOptions specifies which trashed workspaces to purge
+type EmptyTrashOptions struct {
+	OlderThan time.Duration // only purge workspaces trashed at least this long ago
+	DryRun    bool
 }
 
 // CleanupPlan describes what cleanup would do
@@ -89,9 +161,12 @@ type CleanupPlan struct {
 
 // RemovalAction describes a workspace to be removed
 type RemovalAction struct {
-	Workspace Workspace
-	Reason    string
-	OnDirty   string
+	Workspace     Workspace
+	Reason        string
+	OnDirty       string
+	DeletedBranch string
+	Trashed       bool
+	Hooks         []HookResult
 }
 
 // DoctorReport describes repair results
@@ -99,6 +174,20 @@ type DoctorReport struct {
 	BrokenWorkspaces []Workspace
 	Repairs          []Repair
 	Warnings         []Warning
+	Migration        *MigrationPlan // set when metadata uses an older schema
+}
+
+// StatusReport summarizes workspaces that need attention
+type StatusReport struct {
+	Total             int
+	States            map[string]int // lifecycle state -> count
+	Ahead             int            // commits ahead of upstream, summed
+	Behind            int            // commits behind upstream, summed
+	Dirty             []Workspace
+	Conflicted        []Workspace
+	ExpiringSoon      []Workspace // includes already expired
+	Broken            []Workspace
+	CleanupCandidates []RemovalAction
 }
 
 // Repair describes a repair operation
@@ -122,8 +211,31 @@ type engine struct {
 	config   *config.Config
 	lockMgr  lock.Manager
 	lockPath string
+	hooks    hooks.Executor
+	journal  journal.Journal
+}
+
+// workspaceRefresh is what List learned from git about a workspace that
+// its metadata does not record yet
+type workspaceRefresh struct {
+	lastGitActivity *time.Time
+
+	// The target replaces the recorded one only if that is still
+	// fromBranch at fromHead, so stale reads cannot undo newer writes
+	fromBranch, fromHead string
+	branch, head         string
 }
 
+// errUnchanged aborts a store transaction that has nothing to write
+var errUnchanged = NewError(ErrConfig, "metadata unchanged")
+
+// Engine lock acquisition timeouts
+const (
+	writeLockTimeout   = 3 * time.Second
+	ensureLockTimeout  = 10 * time.Second
+	cleanupLockTimeout = 5 * time.Second
+)
+
 // NewEngine creates a new WorkspaceManager from a path
 func NewEngine(path string) (WorkspaceManager, error) {
 	// Find and initialize git repository
@@ -133,7 +245,7 @@ func NewEngine(path string) (WorkspaceManager, error) {
 	}
 
 	// Create metadata store
-	store, err := metadata.NewStore(repo.GitDir())
+	store, err := metadata.NewStore(repo.CommonDir())
 	if err != nil {
 		return nil, err
 	}
@@ -146,7 +258,7 @@ func NewEngine(path string) (WorkspaceManager, error) {
 
 	// Create lock manager
 	lockMgr := lock.NewManager()
-	lockPath := filepath.Join(repo.GitDir(), "yagwt", "lock")
+	lockPath := filepath.Join(repo.CommonDir(), "yagwt", "lock")
 
 	return &engine{
 		repo:     repo,
@@ -154,23 +266,47 @@ func NewEngine(path string) (WorkspaceManager, error) {
 		config:   cfg,
 		lockMgr:  lockMgr,
 		lockPath: lockPath,
+		hooks:    hooks.NewExecutor(repo.Root(), cfg.Hooks),
+		journal:  journal.New(repo.CommonDir()),
 	}, nil
 }
 
 // NewEngineWithDeps creates a new WorkspaceManager with injected dependencies (for testing)
 func NewEngineWithDeps(repo git.Repository, store metadata.Store, cfg *config.Config, lockMgr lock.Manager) WorkspaceManager {
-	lockPath := filepath.Join(repo.GitDir(), "yagwt", "lock")
+	lockPath := filepath.Join(repo.CommonDir(), "yagwt", "lock")
 	return &engine{
 		repo:     repo,
 		store:    store,
 		config:   cfg,
 		lockMgr:  lockMgr,
 		lockPath: lockPath,
+		hooks:    hooks.NewExecutor(repo.Root(), cfg.Hooks),
+		journal:  journal.New(repo.CommonDir()),
 	}
 }
 
 // List returns all workspaces with merged git + metadata
 func (e *engine) List(opts ListOptions) ([]Workspace, error) {
+	// Validate the filter and fields before doing any git work
+	var wsFilter filter.Filter
+	if opts.Filter != "" {
+		var err error
+		if wsFilter, err = parseFilter(opts.Filter); err != nil {
+			return nil, err
+		}
+	}
+
+	fields, err := ExpandFields(opts.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only collect git state that the fields or filter actually use
+	skipStatus := opts.SkipStatus || (len(fields) > 0 && !fieldsNeedGitState(fields))
+	if wsFilter != nil && filter.NeedsGitState(wsFilter) {
+		skipStatus = false
+	}
+
 	// Get git worktrees
 	worktrees, err := e.repo.ListWorktrees()
 	if err != nil {
@@ -186,6 +322,9 @@ func (e *engine) List(opts ListOptions) ([]Workspace, error) {
 	// Build map of normalized path -> metadata for efficient lookup
 	pathToMeta := make(map[string]metadata.WorkspaceMetadata)
 	for _, ws := range meta.Workspaces {
+		if ws.Trash != nil {
+			continue
+		}
 		normalizedPath := normalizePath(ws.Path)
 		pathToMeta[normalizedPath] = ws
 	}
@@ -193,8 +332,32 @@ func (e *engine) List(opts ListOptions) ([]Workspace, error) {
 	// Merge worktrees with metadata
 	var workspaces []Workspace
 	seenPaths := make(map[string]bool)
+	refreshed := make(map[string]workspaceRefresh)
+
+	// Resolving a selector only needs the worktrees it can match
+	if opts.paths != nil {
+		var selected []git.Worktree
+		for _, wt := range worktrees {
+			if opts.paths[normalizePath(wt.Path)] {
+				selected = append(selected, wt)
+			}
+		}
+		worktrees = selected
+	}
+
+	var states []worktreeState
+	if !skipStatus {
+		states = e.collectWorktreeStates(worktrees)
+	} else {
+		states = make([]worktreeState, len(worktrees))
+	}
 
 	for i, wt := range worktrees {
+		// A bare repository is listed as a worktree but has no checkout
+		if wt.Bare {
+			continue
+		}
+
 		// Normalize worktree path for consistent comparison
 		normalizedWtPath := normalizePath(wt.Path)
 		seenPaths[normalizedWtPath] = true
@@ -202,15 +365,7 @@ func (e *engine) List(opts ListOptions) ([]Workspace, error) {
 		// Get metadata if available
 		wsMeta, hasMeta := pathToMeta[normalizedWtPath]
 
-		// Get git status
-		status, err := e.repo.GetStatus(wt.Path)
-		if err != nil {
-			// If we can't get status, continue but mark as broken
-			status = git.Status{}
-		}
-
-		// Determine if this is the primary workspace
-		isPrimary := i == 0 // First worktree is typically primary
+		status := states[i].status
 
 		// Build Target info
 		target := Target{
@@ -232,7 +387,7 @@ func (e *engine) List(opts ListOptions) ([]Workspace, error) {
 			ID:        "",
 			Name:      "",
 			Path:      wt.Path,
-			IsPrimary: isPrimary,
+			IsPrimary: wt.Main,
 			Target:    target,
 			Flags: WorkspaceFlags{
 				Pinned:    false,
@@ -242,17 +397,31 @@ func (e *engine) List(opts ListOptions) ([]Workspace, error) {
 			},
 			Activity: ActivityInfo{},
 			Status: StatusInfo{
-				Dirty:     status.Dirty,
-				Conflicts: status.Conflicts,
-				Ahead:     status.Ahead,
-				Behind:    status.Behind,
-				Branch:    status.Branch,
-				Detached:  status.Detached,
+				Dirty:         status.Dirty,
+				Conflicts:     status.Conflicts,
+				Ahead:         status.Ahead,
+				Behind:        status.Behind,
+				Branch:        status.Branch,
+				Detached:      status.Detached,
+				Submodules:    submoduleStatuses(status.Submodules),
+				SubmoduleOnly: status.SubmoduleOnly,
 			},
 		}
 
 		// Merge metadata if available
 		if hasMeta {
+			recorded := wsMeta
+			activityChanged := refreshActivity(states[i].lastActivity, &wsMeta.Activity)
+			if e.refreshTarget(wt, &wsMeta) || activityChanged {
+				refreshed[wsMeta.ID] = workspaceRefresh{
+					lastGitActivity: wsMeta.Activity.LastGitActivityAt,
+					fromBranch:      recorded.Branch,
+					fromHead:        recorded.HeadSHA,
+					branch:          wsMeta.Branch,
+					head:            wsMeta.HeadSHA,
+				}
+			}
+
 			ws.ID = wsMeta.ID
 			ws.Name = wsMeta.Name
 
@@ -268,12 +437,12 @@ func (e *engine) List(opts ListOptions) ([]Workspace, error) {
 			}
 
 			// Copy ephemeral info
-			if wsMeta.Ephemeral != nil {
-				ws.Ephemeral = &EphemeralInfo{
-					TTLSeconds: wsMeta.Ephemeral.TTLSeconds,
-					ExpiresAt:  wsMeta.Ephemeral.ExpiresAt,
-				}
-			}
+			ws.Ephemeral = ephemeralInfo(wsMeta)
+			ws.CreatedAt = createdAt(wsMeta)
+			ws.Labels = wsMeta.Labels
+			ws.Fields = wsMeta.Fields
+			ws.Seed = seedInfo(wsMeta)
+			ws.Sparse = sparseInfo(wsMeta)
 
 			// Copy activity info
 			ws.Activity = ActivityInfo{
@@ -292,7 +461,10 @@ func (e *engine) List(opts ListOptions) ([]Workspace, error) {
 	// Check for orphaned metadata (metadata without git worktree)
 	for _, wsMeta := range meta.Workspaces {
 		normalizedMetaPath := normalizePath(wsMeta.Path)
-		if !seenPaths[normalizedMetaPath] {
+		if opts.paths != nil && !opts.paths[normalizedMetaPath] {
+			continue
+		}
+		if wsMeta.Trash == nil && !seenPaths[normalizedMetaPath] {
 			// Workspace metadata exists but git worktree is missing
 			ws := Workspace{
 				ID:   wsMeta.ID,
@@ -311,12 +483,12 @@ func (e *engine) List(opts ListOptions) ([]Workspace, error) {
 				ws.Flags.Ephemeral = ephemeral
 			}
 
-			if wsMeta.Ephemeral != nil {
-				ws.Ephemeral = &EphemeralInfo{
-					TTLSeconds: wsMeta.Ephemeral.TTLSeconds,
-					ExpiresAt:  wsMeta.Ephemeral.ExpiresAt,
-				}
-			}
+			ws.Ephemeral = ephemeralInfo(wsMeta)
+			ws.CreatedAt = createdAt(wsMeta)
+			ws.Labels = wsMeta.Labels
+			ws.Fields = wsMeta.Fields
+			ws.Seed = seedInfo(wsMeta)
+			ws.Sparse = sparseInfo(wsMeta)
 
 			ws.Activity = ActivityInfo{
 				LastOpenedAt:      wsMeta.Activity.LastOpenedAt,
@@ -327,12 +499,177 @@ func (e *engine) List(opts ListOptions) ([]Workspace, error) {
 		}
 	}
 
+	e.persistRefresh(refreshed)
+
+	if wsFilter != nil {
+		workspaces = applyFilter(workspaces, wsFilter)
+	}
+
 	return workspaces, nil
 }
 
+// worktreeState is the git state List collects for each worktree
+type worktreeState struct {
+	status       git.Status
+	lastActivity time.Time
+}
+
+// collectWorktreeStates runs git status and activity probes on a bounded
+// pool of workers. Results are indexed like worktrees, so order is stable.
+func (e *engine) collectWorktreeStates(worktrees []git.Worktree) []worktreeState {
+	states := make([]worktreeState, len(worktrees))
+
+	workers := e.config.List.StatusConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(worktrees) {
+		workers = len(worktrees)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				path := worktrees[i].Path
+				// A worktree whose status can't be read keeps a zero status
+				if status, err := e.repo.GetStatus(path); err == nil {
+					states[i].status = status
+				}
+				if last, err := e.repo.LastActivity(path); err == nil {
+					states[i].lastActivity = last
+				}
+			}
+		}()
+	}
+
+	for i := range worktrees {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return states
+}
+
+// createdAt returns the creation time recorded in metadata, if any
+func createdAt(meta metadata.WorkspaceMetadata) *time.Time {
+	if meta.CreatedAt.IsZero() {
+		return nil
+	}
+	t := meta.CreatedAt
+	return &t
+}
+
+// refreshActivity updates activity with the worktree's latest git activity.
+// It reports whether the stored value changed.
+func refreshActivity(last time.Time, activity *metadata.ActivityMetadata) bool {
+	if last.IsZero() {
+		return false
+	}
+
+	if activity.LastGitActivityAt != nil && !last.After(*activity.LastGitActivityAt) {
+		return false
+	}
+
+	activity.LastGitActivityAt = &last
+	return true
+}
+
+// refreshTarget records the worktree's branch and HEAD in meta. A recorded
+// branch that no longer exists is kept, along with its HEAD, so doctor can
+// report that it was renamed or deleted. It reports whether meta changed.
+func (e *engine) refreshTarget(wt git.Worktree, meta *metadata.WorkspaceMetadata) bool {
+	if meta.Branch == wt.Branch && meta.HeadSHA == wt.HEAD {
+		return false
+	}
+	if unborn(wt.HEAD) {
+		return false
+	}
+	if meta.Branch != "" && meta.Branch != wt.Branch && !e.branchExists(meta.Branch) {
+		return false
+	}
+
+	meta.Branch = wt.Branch
+	meta.HeadSHA = wt.HEAD
+	return true
+}
+
+// unborn reports whether a worktree HEAD names a branch with no commit,
+// which is what git lists after the checked-out branch is deleted
+func unborn(head string) bool {
+	return head == "" || strings.Trim(head, "0") == ""
+}
+
+// branchExists reports whether a local branch exists
+func (e *engine) branchExists(branch string) bool {
+	_, err := e.repo.ResolveRef("refs/heads/" + branch)
+	return err == nil
+}
+
+// persistRefresh writes the activity and targets List refreshed back to
+// the store, so the next process sees them without asking git. It only
+// moves timestamps forward and does not rotate backups. It is best effort:
+// the values are recomputed on the next read anyway.
+func (e *engine) persistRefresh(refreshed map[string]workspaceRefresh) {
+	if len(refreshed) == 0 {
+		return
+	}
+
+	e.store.Refresh(func(m *metadata.Metadata) error {
+		changed := false
+		for id, fresh := range refreshed {
+			meta, ok := m.Workspaces[id]
+			if !ok {
+				continue
+			}
+
+			updated := false
+			if later(fresh.lastGitActivity, meta.Activity.LastGitActivityAt) {
+				meta.Activity.LastGitActivityAt = fresh.lastGitActivity
+				updated = true
+			}
+			if meta.Branch == fresh.fromBranch && meta.HeadSHA == fresh.fromHead &&
+				(meta.Branch != fresh.branch || meta.HeadSHA != fresh.head) {
+				meta.Branch = fresh.branch
+				meta.HeadSHA = fresh.head
+				updated = true
+			}
+
+			if updated {
+				m.SetWorkspace(meta)
+				changed = true
+			}
+		}
+		if !changed {
+			return errUnchanged
+		}
+		return nil
+	})
+}
+
+// later reports whether a is set and after b
+func later(a, b *time.Time) bool {
+	return a != nil && (b == nil || a.After(*b))
+}
+
 // Get returns a single workspace by selector
 func (e *engine) Get(selector Selector) (Workspace, error) {
-	workspaces, err := e.Resolve(selectorToString(selector))
+	return e.get(selector, ListOptions{})
+}
+
+// Locate returns a single workspace by selector without collecting git
+// status. Use it when only identity fields (ID, name, path, target, flags)
+// are needed.
+func (e *engine) Locate(selector Selector) (Workspace, error) {
+	return e.get(selector, ListOptions{SkipStatus: true})
+}
+
+func (e *engine) get(selector Selector, opts ListOptions) (Workspace, error) {
+	workspaces, err := e.resolve(selectorToString(selector), opts)
 	if err != nil {
 		return Workspace{}, err
 	}
@@ -354,14 +691,58 @@ func (e *engine) Get(selector Selector) (Workspace, error) {
 
 // Resolve resolves a selector to matching workspaces
 func (e *engine) Resolve(ref string) ([]Workspace, error) {
+	return e.resolve(ref, ListOptions{})
+}
+
+func (e *engine) resolve(ref string, opts ListOptions) ([]Workspace, error) {
 	selector := ParseSelector(ref)
 
+	// Name and branch selectors are answered from the metadata indexes, so
+	// only the candidate worktrees are inspected. The branch index can be
+	// stale, so fall back to a full scan when it finds nothing.
+	if paths := e.indexedPaths(selector); len(paths) > 0 {
+		opts.paths = paths
+		candidates, err := e.List(opts)
+		if err != nil {
+			return nil, err
+		}
+		if matches := matchSelector(candidates, selector); len(matches) > 0 {
+			return matches, nil
+		}
+		opts.paths = nil
+	}
+
 	// Get all workspaces
-	allWorkspaces, err := e.List(ListOptions{})
+	allWorkspaces, err := e.List(opts)
 	if err != nil {
 		return nil, err
 	}
 
+	return matchSelector(allWorkspaces, selector), nil
+}
+
+// indexedPaths returns the normalized paths of workspaces the metadata
+// indexes associate with a name or branch selector
+func (e *engine) indexedPaths(selector Selector) map[string]bool {
+	var candidates []metadata.WorkspaceMetadata
+	switch selector.Type {
+	case SelectorName:
+		if ws, err := e.store.FindByName(selector.Value); err == nil {
+			candidates = append(candidates, ws)
+		}
+	case SelectorBranch:
+		candidates, _ = e.store.FindByBranch(selector.Value)
+	}
+
+	paths := make(map[string]bool)
+	for _, ws := range candidates {
+		paths[normalizePath(ws.Path)] = true
+	}
+	return paths
+}
+
+// matchSelector returns the workspaces selector identifies
+func matchSelector(allWorkspaces []Workspace, selector Selector) []Workspace {
 	var matches []Workspace
 
 	switch selector.Type {
@@ -408,7 +789,7 @@ func (e *engine) Resolve(ref string) ([]Workspace, error) {
 			}
 		}
 		if len(matches) > 0 {
-			return matches, nil
+			return matches
 		}
 
 		// Try name
@@ -418,7 +799,7 @@ func (e *engine) Resolve(ref string) ([]Workspace, error) {
 			}
 		}
 		if len(matches) > 0 {
-			return matches, nil
+			return matches
 		}
 
 		// Try path
@@ -430,7 +811,7 @@ func (e *engine) Resolve(ref string) ([]Workspace, error) {
 			}
 		}
 		if len(matches) > 0 {
-			return matches, nil
+			return matches
 		}
 
 		// Try branch
@@ -441,29 +822,71 @@ func (e *engine) Resolve(ref string) ([]Workspace, error) {
 		}
 	}
 
-	return matches, nil
+	return matches
 }
 
-// Create creates a new workspace
-func (e *engine) Create(opts CreateOptions) (Workspace, error) {
-	// Acquire lock for write operation
+// withLock runs fn while holding the engine lock. The lock is not
+// re-entrant, so fn must only call the lock-free internal operations
+// (create, remove, ...) and never the public methods that take it again.
+func (e *engine) withLock(timeout time.Duration, fn func() error) error {
 	lck, err := e.lockMgr.NewLock(e.lockPath)
 	if err != nil {
-		return Workspace{}, err
+		return err
 	}
 
-	if err := lck.Acquire(3 * time.Second); err != nil {
-		return Workspace{}, err
+	if err := lck.Acquire(timeout); err != nil {
+		return err
 	}
 	defer lck.Release()
 
+	return fn()
+}
+
+// checkLifecycleFlags refuses a pinned ephemeral workspace: pinned
+// workspaces are kept forever, so they cannot also expire
+func checkLifecycleFlags(opts CreateOptions) error {
+	if opts.Pin && opts.Ephemeral {
+		return NewError(ErrConfig, "a workspace cannot be both pinned and ephemeral").
+			WithHint("Use either --pin or --ephemeral", "")
+	}
+	return nil
+}
+
+// Create creates a new workspace
+func (e *engine) Create(opts CreateOptions) (result CreateResult, err error) {
+	err = e.withLock(writeLockTimeout, func() error {
+		result, err = e.create(opts)
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	e.finishCreate(&result, opts)
+	return result, nil
+}
+
+// create creates a workspace up to the point it is usable: worktree,
+// metadata and bootstrapped files. The caller must hold the engine lock
+// and then call finishCreate without it.
+func (e *engine) create(opts CreateOptions) (CreateResult, error) {
+	if err := checkLifecycleFlags(opts); err != nil {
+		return CreateResult{}, err
+	}
+
+	// Determine workspace name (explicit, or rendered from the name template)
+	wsName, rendered, err := e.workspaceName(opts)
+	if err != nil {
+		return CreateResult{}, err
+	}
+
 	// Determine workspace path
 	wsPath := opts.Dir
 	if wsPath == "" {
 		// Derive path from config
-		wsPath, err = e.deriveWorkspacePath(opts)
+		wsPath, err = e.deriveWorkspacePath(opts, wsName)
 		if err != nil {
-			return Workspace{}, err
+			return CreateResult{}, err
 		}
 	}
 
@@ -471,48 +894,74 @@ func (e *engine) Create(opts CreateOptions) (Workspace, error) {
 	if !filepath.IsAbs(wsPath) {
 		wsPath, err = filepath.Abs(wsPath)
 		if err != nil {
-			return Workspace{}, WrapError(ErrConfig, "failed to make path absolute", err).
+			return CreateResult{}, WrapError(ErrConfig, "failed to make path absolute", err).
 				WithDetail("path", wsPath)
 		}
 	}
 
-	// Build git add options
+	// Fall back to the directory name
+	if wsName == "" {
+		wsName = filepath.Base(wsPath)
+	}
+
+	if err := e.checkNameAvailable(wsName, rendered); err != nil {
+		return CreateResult{}, err
+	}
+
+	sparse, err := e.sparseSpec(opts)
+	if err != nil {
+		return CreateResult{}, err
+	}
+
+	// Build git add options. Sparse worktrees are populated only once
+	// their patterns are set.
 	gitOpts := git.AddOptions{
-		NewBranch: opts.NewBranch,
-		Detach:    opts.Detached,
-		Checkout:  opts.Checkout,
-		Force:     false,
-		Base:      opts.Base,
+		NewBranch:  opts.NewBranch,
+		Detach:     opts.Detached,
+		NoCheckout: opts.NoCheckout || sparse != nil,
+		Force:      false,
+		Base:       opts.Base,
 	}
 
 	// Create git worktree
 	if err := e.repo.AddWorktree(wsPath, opts.Target, gitOpts); err != nil {
-		return Workspace{}, err
+		return CreateResult{}, err
 	}
 
-	// Generate workspace ID
-	wsID := uuid.New().String()
+	// rollback undoes the worktree, and the branch if this call created
+	// it, so a retry does not fail on leftovers
+	rollback := func() {
+		_ = e.repo.RemoveWorktree(wsPath, true)
+		if opts.NewBranch {
+			_ = e.repo.DeleteBranch(opts.Target, true)
+		}
+	}
 
-	// Determine workspace name
-	wsName := opts.Name
-	if wsName == "" {
-		// Derive from target or path
-		if opts.Target != "" && !opts.Detached {
-			// Use branch name
-			wsName = strings.TrimPrefix(opts.Target, "refs/heads/")
-			wsName = strings.ReplaceAll(wsName, "/", "-")
-		} else {
-			// Use directory name
-			wsName = filepath.Base(wsPath)
+	if sparse != nil {
+		if err := e.applySparse(wsPath, sparse, !opts.NoCheckout); err != nil {
+			rollback()
+			return CreateResult{}, err
 		}
 	}
 
+	// Check out submodules and LFS files (failures are reported, never fatal)
+	var checkoutWarnings []Warning
+	if !opts.NoCheckout {
+		checkoutWarnings = e.checkoutExtras(wsPath)
+	}
+
+	// Generate workspace ID
+	wsID := uuid.New().String()
+
 	// Create metadata
 	now := time.Now()
+	branch, head := e.worktreeTarget(wsPath)
 	wsMeta := metadata.WorkspaceMetadata{
-		ID:   wsID,
-		Name: wsName,
-		Path: wsPath,
+		ID:      wsID,
+		Name:    wsName,
+		Path:    wsPath,
+		Branch:  branch,
+		HeadSHA: head,
 		Flags: map[string]bool{
 			"pinned":    opts.Pin,
 			"ephemeral": opts.Ephemeral,
@@ -522,139 +971,507 @@ func (e *engine) Create(opts CreateOptions) (Workspace, error) {
 			LastOpenedAt:      nil,
 			LastGitActivityAt: &now,
 		},
+		Sparse:    sparse,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 
 	// Add ephemeral info if needed
-	if opts.Ephemeral && opts.TTL > 0 {
-		wsMeta.Ephemeral = &metadata.EphemeralMetadata{
-			TTLSeconds: int(opts.TTL.Seconds()),
-			ExpiresAt:  now.Add(opts.TTL),
-		}
+	if opts.Ephemeral {
+		wsMeta.Ephemeral = e.newEphemeral(opts.TTL, false, now)
 	}
 
 	// Save metadata
 	if err := e.store.Set(wsID, wsMeta); err != nil {
 		// Try to clean up git worktree on metadata failure
-		_ = e.repo.RemoveWorktree(wsPath, true)
-		return Workspace{}, err
+		rollback()
+		return CreateResult{}, err
 	}
 
 	// Get fresh workspace state
 	ws, err := e.Get(Selector{Type: SelectorID, Value: wsID})
 	if err != nil {
-		return Workspace{}, err
+		return CreateResult{}, err
 	}
 
-	return ws, nil
+	result := CreateResult{Workspace: ws, Warnings: checkoutWarnings}
+
+	// Bring in untracked files before the hook, which may rely on them
+	bootstrap, err := e.bootstrap(ws, BootstrapOptions{})
+	result.Bootstrap = bootstrap.Files
+	result.Warnings = append(result.Warnings, bootstrap.Warnings...)
+	if err != nil {
+		result.Warnings = append(result.Warnings, Warning{
+			Code:    "bootstrap_failed",
+			Message: "Bootstrap failed: " + err.Error(),
+		})
+	}
+
+	return result, nil
 }
 
-// deriveWorkspacePath derives a workspace path from config and options
-func (e *engine) deriveWorkspacePath(opts CreateOptions) (string, error) {
-	repoRoot := e.repo.Root()
+// finishCreate seeds a workspace made by create and runs the post-create
+// hook. It runs after the engine lock is released: seeding can copy
+// gigabytes, and other writers would time out waiting for it.
+func (e *engine) finishCreate(result *CreateResult, opts CreateOptions) {
+	ws := result.Workspace
 
-	// Determine directory name
-	dirName := opts.Name
-	if dirName == "" {
-		if opts.Target != "" && !opts.Detached {
-			// Use branch name
-			dirName = strings.TrimPrefix(opts.Target, "refs/heads/")
-			dirName = strings.ReplaceAll(dirName, "/", "-")
-		} else {
-			return "", NewError(ErrConfig, "cannot derive workspace path: name or target required")
-		}
+	// Clone build caches so the workspace does not start cold
+	seeded, err := e.seed(ws, SeedOptions{Progress: opts.SeedProgress})
+	result.Seed = seeded.Dirs
+	result.Warnings = append(result.Warnings, seeded.Warnings...)
+	if err != nil {
+		result.Warnings = append(result.Warnings, Warning{
+			Code:    "seed_failed",
+			Message: "Seeding failed: " + err.Error(),
+		})
+	}
+	if seeded.Workspace.Seed != nil {
+		result.Workspace.Seed = seeded.Workspace.Seed
 	}
 
-	var wsPath string
-	switch e.config.Workspace.RootStrategy {
-	case "sibling":
-		// Place workspace as sibling to repo root
-		parentDir := filepath.Dir(repoRoot)
-		wsPath = filepath.Join(parentDir, dirName)
+	// Run post-create hook (failure is reported, never fatal)
+	hookResult, err := e.hooks.Execute(hooks.PostCreate, e.hookContext(ws, "create"))
+	if hookResult != nil {
+		result.Hooks = append(result.Hooks, *hookResult)
+	}
+	if err != nil {
+		result.Warnings = append(result.Warnings, hookWarning(hooks.PostCreate, err))
+	}
+}
 
-	case "inside":
-		// Place workspace inside repo
-		rootDir := e.config.Workspace.RootDir
-		if rootDir == "" {
-			rootDir = ".workspaces"
+// worktreeTarget returns the branch and HEAD git lists for the worktree at
+// path, or empty strings if it isn't listed
+func (e *engine) worktreeTarget(path string) (branch, head string) {
+	worktrees, err := e.repo.ListWorktrees()
+	if err != nil {
+		return "", ""
+	}
+	for _, wt := range worktrees {
+		if normalizePath(wt.Path) == normalizePath(path) {
+			return wt.Branch, wt.HEAD
 		}
-		wsPath = filepath.Join(repoRoot, rootDir, dirName)
+	}
+	return "", ""
+}
 
-	default:
-		return "", NewError(ErrConfig, "invalid rootStrategy in config").
-			WithDetail("strategy", e.config.Workspace.RootStrategy)
+// Ensure returns the workspace for the target branch or name, creating it
+// if it does not exist. Lookup and creation happen under one lock so
+// concurrent callers agree on a single workspace.
+func (e *engine) Ensure(opts CreateOptions) (result EnsureResult, err error) {
+	err = e.withLock(ensureLockTimeout, func() error {
+		result, err = e.ensure(opts)
+		return err
+	})
+	if err != nil || !result.Created {
+		return result, err
 	}
 
-	return wsPath, nil
+	created := CreateResult{Workspace: result.Workspace, Bootstrap: result.Bootstrap, Warnings: result.Warnings}
+	e.finishCreate(&created, opts)
+	result.Workspace = created.Workspace
+	result.Seed = created.Seed
+	result.Hooks = created.Hooks
+	result.Warnings = created.Warnings
+	return result, nil
 }
 
-// Remove removes a workspace
-func (e *engine) Remove(selector Selector, opts RemoveOptions) error {
-	// Acquire lock for write operation
-	lck, err := e.lockMgr.NewLock(e.lockPath)
+// ensure implements Ensure up to create; the caller must hold the engine
+// lock and finish a created workspace without it
+func (e *engine) ensure(opts CreateOptions) (EnsureResult, error) {
+	// Refuse conflicting flags whether or not the workspace exists yet
+	if err := checkLifecycleFlags(opts); err != nil {
+		return EnsureResult{}, err
+	}
+
+	existing, found, err := e.findEnsured(opts)
 	if err != nil {
-		return err
+		return EnsureResult{}, err
+	}
+	if found {
+		return EnsureResult{Workspace: existing}, nil
 	}
 
-	if err := lck.Acquire(3 * time.Second); err != nil {
-		return err
+	// --new-branch means "create the branch if missing" for ensure
+	if opts.NewBranch && !opts.Detached {
+		if _, err := e.repo.ResolveRef("refs/heads/" + strings.TrimPrefix(opts.Target, "refs/heads/")); err == nil {
+			opts.NewBranch = false
+		}
 	}
-	defer lck.Release()
 
-	// Resolve workspace
-	ws, err := e.Get(selector)
+	created, err := e.create(opts)
 	if err != nil {
-		return err
+		return EnsureResult{}, err
 	}
 
-	// Check if pinned
-	if ws.Flags.Pinned {
-		return NewError(ErrLocked, "workspace is pinned").
-			WithDetail("id", ws.ID).
-			WithDetail("name", ws.Name).
-			WithHint("Unpin the workspace first", "yagwt unpin "+ws.Name)
+	return EnsureResult{
+		Workspace: created.Workspace,
+		Created:   true,
+		Bootstrap: created.Bootstrap,
+		Warnings:  created.Warnings,
+	}, nil
+}
+
+// findEnsured looks up the workspace Ensure should return: by explicit
+// name first, then by checked-out branch
+func (e *engine) findEnsured(opts CreateOptions) (Workspace, bool, error) {
+	workspaces, err := e.List(ListOptions{})
+	if err != nil {
+		return Workspace{}, false, err
 	}
 
-	// Check if locked
-	if ws.Flags.Locked {
-		return NewError(ErrLocked, "workspace is locked").
-			WithDetail("id", ws.ID).
-			WithDetail("name", ws.Name).
-			WithHint("Unlock the workspace first", "yagwt unlock "+ws.Name)
+	branch := ""
+	if opts.Target != "" && !opts.Detached {
+		branch = strings.TrimPrefix(opts.Target, "refs/heads/")
 	}
 
-	// Handle dirty workspace based on strategy
-	onDirty := opts.OnDirty
-	if onDirty == "" {
-		onDirty = "fail"
+	if opts.Name != "" {
+		for _, ws := range workspaces {
+			if ws.Name != opts.Name {
+				continue
+			}
+			if branch != "" && ws.Target.Short != branch {
+				return Workspace{}, false, NewError(ErrConflict, "workspace name is used by a different branch").
+					WithDetail("name", opts.Name).
+					WithDetail("branch", ws.Target.Short).
+					WithDetail("requested", branch).
+					WithHint("Choose a different name", "yagwt ensure "+branch+" --name <name>")
+			}
+			return ws, true, nil
+		}
 	}
 
-	if ws.Status.Dirty {
-		switch onDirty {
-		case "fail":
-			return NewError(ErrDirty, "workspace has uncommitted changes").
-				WithDetail("id", ws.ID).
-				WithDetail("name", ws.Name).
-				WithHint("Commit or stash changes, or use --on-dirty=stash|patch|wip-commit|force", "git -C "+ws.Path+" status")
+	if branch != "" {
+		for _, ws := range workspaces {
+			if ws.Target.Type == "branch" && ws.Target.Short == branch {
+				return ws, true, nil
+			}
+		}
+	}
 
-		case "stash":
-			// Stash changes before removal
-			stashMsg := "yagwt: auto-stash before removal of " + ws.Name
-			if err := e.repo.Stash(ws.Path, stashMsg); err != nil {
+	return Workspace{}, false, nil
+}
+
+// workspaceName returns the explicit name, or renders the name template
+// when the target is a branch. rendered reports whether the template was
+// used; an empty name means the caller should fall back to the directory.
+func (e *engine) workspaceName(opts CreateOptions) (name string, rendered bool, err error) {
+	if opts.Name != "" {
+		return opts.Name, false, nil
+	}
+
+	if opts.Target == "" || opts.Detached {
+		return "", false, nil
+	}
+
+	vars, err := e.templateVars(opts.Target)
+	if err != nil {
+		return "", false, err
+	}
+
+	tmpl := e.config.Workspace.NameTemplate
+	if tmpl == "" {
+		tmpl = "{branch}"
+	}
+
+	name, err = naming.RenderName(tmpl, vars)
+	if err != nil {
+		return "", false, err
+	}
+
+	return name, true, nil
+}
+
+// templateVars builds naming template variables for a branch target
+func (e *engine) templateVars(branch string) (naming.Vars, error) {
+	pattern := e.config.Workspace.TicketPattern
+	if pattern == "" {
+		pattern = naming.DefaultTicketPattern
+	}
+
+	ticketRe, err := regexp.Compile(pattern)
+	if err != nil {
+		return naming.Vars{}, WrapError(ErrConfig, "invalid ticketPattern in config", err).
+			WithDetail("pattern", pattern)
+	}
+
+	// Name a bare repository after its directory: the parent of a hidden
+	// .bare or .git dir, or repo for repo.git
+	root := e.repo.Root()
+	if e.repo.IsBare() {
+		if strings.HasPrefix(filepath.Base(root), ".") {
+			root = filepath.Dir(root)
+		}
+		root = strings.TrimSuffix(root, ".git")
+	}
+
+	return naming.NewVars(branch, root, ticketRe), nil
+}
+
+// checkNameAvailable fails with ErrConflict if a workspace already uses name
+func (e *engine) checkNameAvailable(name string, rendered bool) error {
+	meta, err := e.store.Load()
+	if err != nil {
+		return err
+	}
+
+	for id, ws := range meta.Workspaces {
+		if ws.Name != name || ws.Trash != nil {
+			continue
+		}
+
+		conflict := NewError(ErrConflict, "workspace name already in use").
+			WithDetail("name", name).
+			WithDetail("existingId", id)
+		if rendered {
+			return conflict.WithHint("The name template produced an existing name; pass --name to choose another", "yagwt new <target> --name <name>")
+		}
+		return conflict.WithHint("Choose a different name", "yagwt new <target> --name <name>")
+	}
+
+	return nil
+}
+
+// deriveWorkspacePath derives a workspace path from config and options
+func (e *engine) deriveWorkspacePath(opts CreateOptions, name string) (string, error) {
+	repoRoot := e.repo.Root()
+
+	if name == "" {
+		return "", NewError(ErrConfig, "cannot derive workspace path: name or target required")
+	}
+
+	vars := naming.Vars{Name: name}
+	if opts.Target != "" && !opts.Detached {
+		var err error
+		vars, err = e.templateVars(opts.Target)
+		if err != nil {
+			return "", err
+		}
+		vars.Name = name
+	}
+
+	tmpl := e.config.Workspace.PathTemplate
+	if tmpl == "" {
+		tmpl = "{name}"
+	}
+
+	dirName, err := naming.RenderPath(tmpl, vars)
+	if err != nil {
+		return "", err
+	}
+
+	var wsPath string
+	switch e.config.Workspace.RootStrategy {
+	case "sibling":
+		// Place workspace as sibling to repo root
+		parentDir := filepath.Dir(repoRoot)
+		wsPath = filepath.Join(parentDir, dirName)
+
+	case "inside":
+		// Place workspace inside repo. A bare repository has no checkout to
+		// nest in, so use the directory that contains it rather than the
+		// git dir itself.
+		rootDir := e.config.Workspace.RootDir
+		if rootDir == "" {
+			rootDir = ".workspaces"
+		}
+		if e.repo.IsBare() {
+			wsPath = filepath.Join(bareWorkspaceRoot(repoRoot, rootDir), dirName)
+		} else {
+			wsPath = filepath.Join(repoRoot, rootDir, dirName)
+		}
+
+	default:
+		return "", NewError(ErrConfig, "invalid rootStrategy in config").
+			WithDetail("strategy", e.config.Workspace.RootStrategy)
+	}
+
+	return wsPath, nil
+}
+
+// bareWorkspaceRoot returns the "inside" workspace directory for the bare
+// repository at gitDir. A hidden bare dir (project/.bare) has the project
+// to itself, so workspaces go under project/<rootDir>. A named one
+// (repos/app.git) usually sits next to other repositories, so workspaces
+// go under repos/<rootDir>/app to keep each repository's names apart.
+func bareWorkspaceRoot(gitDir, rootDir string) string {
+	parent, base := filepath.Dir(gitDir), filepath.Base(gitDir)
+	if strings.HasPrefix(base, ".") {
+		return filepath.Join(parent, rootDir)
+	}
+	return filepath.Join(parent, rootDir, strings.TrimSuffix(base, ".git"))
+}
+
+// Remove removes a workspace
+func (e *engine) Remove(selector Selector, opts RemoveOptions) (result RemoveResult, err error) {
+	err = e.withLock(writeLockTimeout, func() error {
+		result, err = e.remove(selector, opts, journal.OpRemove)
+		return err
+	})
+	return result, err
+}
+
+// remove removes a workspace and journals it as op; the caller must hold
+// the engine lock
+func (e *engine) remove(selector Selector, opts RemoveOptions, op string) (RemoveResult, error) {
+	// Resolve workspace
+	ws, err := e.Get(selector)
+	if err != nil {
+		return RemoveResult{}, err
+	}
+
+	// Check if pinned
+	if ws.Flags.Pinned && !opts.IgnorePinned {
+		return RemoveResult{}, NewError(ErrLocked, "workspace is pinned").
+			WithDetail("id", ws.ID).
+			WithDetail("name", ws.Name).
+			WithHint("Unpin the workspace first", "yagwt unpin "+ws.Name)
+	}
+
+	// Check if locked
+	if ws.Flags.Locked {
+		return RemoveResult{}, NewError(ErrLocked, "workspace is locked").
+			WithDetail("id", ws.ID).
+			WithDetail("name", ws.Name).
+			WithHint("Unlock the workspace first", "yagwt unlock "+ws.Name)
+	}
+
+	// The trash keeps every file, so dirty workspaces need no strategy
+	trash := (opts.Trash || e.config.Trash.Enabled) && !opts.NoTrash
+	if trash && ws.IsPrimary {
+		return RemoveResult{}, NewError(ErrPolicy, "cannot move the primary workspace to the trash").
+			WithDetail("path", ws.Path)
+	}
+
+	// Handle dirty workspace based on strategy
+	onDirty := opts.OnDirty
+	if onDirty == "" {
+		onDirty = "fail"
+	}
+
+	// Refuse before running hooks so a dirty workspace never sees pre-remove
+	if ws.Status.Dirty && onDirty == "fail" && !trash {
+		return RemoveResult{}, NewError(ErrDirty, "workspace has uncommitted changes").
+			WithDetail("id", ws.ID).
+			WithDetail("name", ws.Name).
+			WithHint("Commit or stash changes, or use --on-dirty=stash|patch|wip-commit|force", "git -C "+ws.Path+" status")
+	}
+
+	// Check branch deletion safety before anything is changed
+	branch, err := e.branchToDelete(ws, opts, onDirty)
+	if err != nil {
+		return RemoveResult{}, err
+	}
+
+	result := RemoveResult{Workspace: ws}
+
+	if opts.DeleteBranch && !opts.KeepBranch && branch == "" {
+		result.Warnings = append(result.Warnings, Warning{
+			Code:    "no_branch",
+			Message: "Workspace '" + ws.Name + "' has a detached HEAD; no branch deleted",
+		})
+	}
+
+	// Run pre-remove hook; a failing hook aborts the removal
+	hookResult, err := e.hooks.Execute(hooks.PreRemove, e.hookContext(ws, "remove"))
+	if hookResult != nil {
+		result.Hooks = append(result.Hooks, *hookResult)
+	}
+	if err != nil {
+		if yerr, ok := err.(*Error); ok {
+			yerr.WithDetail("id", ws.ID).
+				WithDetail("name", ws.Name).
+				WithHint("Fix the pre-remove hook or remove its config entry", "")
+		}
+		return result, err
+	}
+
+	// Record enough to recreate the workspace with yagwt undo
+	entry := journal.Entry{
+		Operation:   op,
+		WorkspaceID: ws.ID,
+		Name:        ws.Name,
+		Path:        ws.Path,
+		HeadSHA:     ws.Target.HeadSHA,
+	}
+	if ws.Target.Type == "branch" {
+		entry.Branch = ws.Target.Short
+	}
+	if meta, err := e.store.Get(ws.ID); err == nil {
+		entry.Previous = &meta
+	}
+
+	if trash {
+		ref, err := e.trashWorkspace(ws)
+		if err != nil {
+			return result, err
+		}
+		result.Trashed = true
+		entry.TrashRef = ref
+	} else if err := e.deleteWorkspace(ws, opts, onDirty, &entry); err != nil {
+		return result, err
+	}
+
+	// Delete the branch now that no worktree uses it. Safety was checked
+	// above, so a failure here only warns: the workspace is already gone.
+	if branch != "" {
+		if err := e.repo.DeleteBranch(branch, true); err != nil {
+			result.Warnings = append(result.Warnings, Warning{
+				Code:    "branch_delete_failed",
+				Message: "Failed to delete branch '" + branch + "': " + err.Error(),
+			})
+		} else {
+			result.DeletedBranch = branch
+			entry.DeletedBranch = true
+		}
+	}
+
+	e.appendJournal(entry, &result.Warnings)
+
+	// Run post-remove hook (failure is reported, never fatal)
+	hookResult, err = e.hooks.Execute(hooks.PostRemove, e.hookContext(ws, "remove"))
+	if hookResult != nil {
+		result.Hooks = append(result.Hooks, *hookResult)
+	}
+	if err != nil {
+		result.Warnings = append(result.Warnings, hookWarning(hooks.PostRemove, err))
+	}
+
+	return result, nil
+}
+
+// deleteWorkspace saves uncommitted changes with the onDirty strategy,
+// then removes the worktree and its metadata for good
+func (e *engine) deleteWorkspace(ws Workspace, opts RemoveOptions, onDirty string, entry *journal.Entry) error {
+	if ws.Status.Dirty {
+		entry.OnDirty = onDirty
+
+		switch onDirty {
+		case "stash":
+			// Stash changes before removal
+			stashMsg := "yagwt: auto-stash before removal of " + ws.Name
+			stashRef, err := e.repo.Stash(ws.Path, stashMsg)
+			if err != nil {
 				return WrapError(ErrGit, "failed to stash changes", err).
 					WithDetail("id", ws.ID).
 					WithDetail("name", ws.Name).
 					WithHint("Use --on-dirty=force to remove anyway", "")
 			}
+			entry.StashRef = stashRef
 
 		case "patch":
 			// Create patch file before removal
 			patchDir := opts.PatchDir
 			if patchDir == "" {
-				patchDir = filepath.Join(e.repo.GitDir(), "yagwt", "patches")
+				patchDir = filepath.Join(e.repo.CommonDir(), "yagwt", "patches")
+			}
+			// Include the ID so a later removal of a workspace with the
+			// same name cannot overwrite a patch the journal still needs
+			id := ws.ID
+			if entry.Previous == nil {
+				id = uuid.New().String()
 			}
-			patchFile := filepath.Join(patchDir, ws.Name+".patch")
+			patchFile := filepath.Join(patchDir, ws.Name+"-"+id+".patch")
 
 			if err := e.repo.CreatePatch(ws.Path, patchFile); err != nil {
 				return WrapError(ErrGit, "failed to create patch", err).
@@ -663,6 +1480,7 @@ func (e *engine) Remove(selector Selector, opts RemoveOptions) error {
 					WithDetail("patchFile", patchFile).
 					WithHint("Use --on-dirty=force to remove anyway", "")
 			}
+			entry.PatchFile = patchFile
 
 		case "wip-commit":
 			// Create WIP commit before removal
@@ -671,12 +1489,15 @@ func (e *engine) Remove(selector Selector, opts RemoveOptions) error {
 				wipMsg = "WIP: auto-commit before removal"
 			}
 
-			if err := e.repo.CreateWIPCommit(ws.Path, wipMsg); err != nil {
+			wipCommit, err := e.repo.CreateWIPCommit(ws.Path, wipMsg)
+			if err != nil {
 				return WrapError(ErrGit, "failed to create WIP commit", err).
 					WithDetail("id", ws.ID).
 					WithDetail("name", ws.Name).
 					WithHint("Use --on-dirty=force to remove anyway", "")
 			}
+			entry.WIPCommit = wipCommit
+			entry.HeadSHA = wipCommit
 
 		case "force":
 			// Continue with removal (will use force flag below)
@@ -688,68 +1509,260 @@ func (e *engine) Remove(selector Selector, opts RemoveOptions) error {
 		}
 	}
 
-	// Remove git worktree
-	force := onDirty == "force"
+	// Remove git worktree; the patch already holds the changes
+	force := onDirty == "force" || onDirty == "patch"
 	if err := e.repo.RemoveWorktree(ws.Path, force); err != nil {
 		return err
 	}
 
 	// Remove metadata
-	if err := e.store.Delete(ws.ID); err != nil {
-		return err
+	return e.store.Delete(ws.ID)
+}
+
+// branchToDelete returns the branch Remove should delete, or "" when none
+// was requested. It refuses branches checked out in another worktree and,
+// unless forced, branches not merged into their upstream or base branch.
+func (e *engine) branchToDelete(ws Workspace, opts RemoveOptions, onDirty string) (string, error) {
+	if !opts.DeleteBranch || opts.KeepBranch || ws.Target.Type != "branch" {
+		return "", nil
 	}
+	branch := ws.Target.Short
 
-	return nil
+	worktrees, err := e.repo.ListWorktrees()
+	if err != nil {
+		return "", err
+	}
+
+	for _, wt := range worktrees {
+		if wt.Branch == branch && normalizePath(wt.Path) != normalizePath(ws.Path) {
+			return "", NewError(ErrConflict, "branch is checked out in another worktree").
+				WithDetail("branch", branch).
+				WithDetail("worktree", wt.Path).
+				WithHint("Remove without --delete-branch, or switch that worktree to another branch", "")
+		}
+	}
+
+	if opts.ForceDeleteBranch {
+		return branch, nil
+	}
+
+	forceHint := "yagwt rm " + ws.Name + " --delete-branch --force-delete-branch"
+
+	// A WIP commit is by definition not merged anywhere
+	if ws.Status.Dirty && onDirty == "wip-commit" {
+		return "", NewError(ErrPolicy, "WIP commit would leave the branch unmerged").
+			WithDetail("branch", branch).
+			WithHint("Use a different --on-dirty strategy, or force branch deletion", forceHint)
+	}
+
+	var targets []string
+	if info, err := e.repo.GetBranch(branch); err == nil && info.Upstream != "" {
+		targets = append(targets, info.Upstream)
+	}
+
+	base := e.config.Workspace.BaseBranch
+	for _, wt := range worktrees {
+		if base == "" && wt.Main {
+			base = wt.Branch
+		}
+	}
+	if base != "" && base != branch {
+		if _, err := e.repo.ResolveRef(base); err == nil {
+			targets = append(targets, base)
+		}
+	}
+
+	if len(targets) == 0 {
+		return "", NewError(ErrPolicy, "cannot verify branch is merged: no upstream or base branch").
+			WithDetail("branch", branch).
+			WithHint("Set workspace.baseBranch in config, or force branch deletion", forceHint)
+	}
+
+	for _, target := range targets {
+		merged, err := e.repo.IsMerged(branch, target)
+		if err != nil {
+			return "", err
+		}
+		if merged {
+			return branch, nil
+		}
+	}
+
+	return "", NewError(ErrPolicy, "branch has unmerged commits").
+		WithDetail("branch", branch).
+		WithDetail("checked", strings.Join(targets, ", ")).
+		WithHint("Merge the branch first, or force branch deletion", forceHint)
 }
 
 // Rename renames a workspace
 func (e *engine) Rename(selector Selector, newName string) error {
-	// Acquire lock
-	lck, err := e.lockMgr.NewLock(e.lockPath)
+	return e.withLock(writeLockTimeout, func() error {
+		return e.rename(selector, newName)
+	})
+}
+
+// rename renames a workspace; the caller must hold the engine lock
+func (e *engine) rename(selector Selector, newName string) error {
+	// Resolve workspace
+	ws, err := e.Locate(selector)
 	if err != nil {
 		return err
 	}
 
-	if err := lck.Acquire(3 * time.Second); err != nil {
+	// Check for name conflict
+	existing, _ := e.resolve("name:"+newName, ListOptions{SkipStatus: true})
+	if len(existing) > 0 && existing[0].ID != ws.ID {
+		return NewError(ErrConflict, "workspace with this name already exists").
+			WithDetail("name", newName)
+	}
+
+	var previous metadata.WorkspaceMetadata
+	err = e.store.Update(func(m *metadata.Metadata) error {
+		meta, ok := m.Workspaces[ws.ID]
+		if !ok {
+			return NewError(ErrNotFound, "workspace not found").
+				WithDetail("id", ws.ID)
+		}
+		previous = meta
+		meta.Name = newName
+		meta.UpdatedAt = time.Now()
+		m.SetWorkspace(meta)
+		return nil
+	})
+	if err != nil {
 		return err
 	}
-	defer lck.Release()
 
+	// Rename has no result to carry warnings, so journal failures are
+	// dropped; the rename itself already succeeded
+	e.appendJournal(journal.Entry{
+		Operation:   journal.OpRename,
+		WorkspaceID: ws.ID,
+		Name:        previous.Name,
+		Path:        previous.Path,
+		Previous:    &previous,
+		NewName:     newName,
+	}, nil)
+	return nil
+}
+
+// Move moves a workspace to a new directory
+func (e *engine) Move(selector Selector, newPath string) error {
+	return e.withLock(writeLockTimeout, func() error {
+		return e.move(selector, newPath)
+	})
+}
+
+// move moves a workspace; the caller must hold the engine lock
+func (e *engine) move(selector Selector, newPath string) error {
 	// Resolve workspace
 	ws, err := e.Get(selector)
 	if err != nil {
 		return err
 	}
 
-	// Check for name conflict
-	existing, _ := e.Resolve("name:" + newName)
-	if len(existing) > 0 && existing[0].ID != ws.ID {
-		return NewError(ErrConflict, "workspace with this name already exists").
-			WithDetail("name", newName)
+	if ws.IsPrimary {
+		return NewError(ErrPolicy, "cannot move the primary workspace").
+			WithDetail("path", ws.Path)
 	}
 
-	// Load metadata
-	meta, err := e.store.Get(ws.ID)
-	if err != nil {
+	if ws.Flags.Broken {
+		return NewError(ErrBroken, "workspace is broken").
+			WithDetail("id", ws.ID).
+			WithDetail("name", ws.Name).
+			WithHint("Repair the workspace first", "yagwt doctor")
+	}
+
+	if ws.Flags.Locked {
+		return NewError(ErrLocked, "workspace is locked").
+			WithDetail("id", ws.ID).
+			WithDetail("name", ws.Name).
+			WithHint("Unlock the workspace first", "yagwt unlock "+ws.Name)
+	}
+
+	if ws.Status.Conflicts {
+		return NewError(ErrDirty, "workspace has unresolved merge conflicts").
+			WithDetail("id", ws.ID).
+			WithDetail("name", ws.Name).
+			WithHint("Resolve conflicts before moving", "git -C "+ws.Path+" status")
+	}
+
+	// Make path absolute
+	if !filepath.IsAbs(newPath) {
+		newPath, err = filepath.Abs(newPath)
+		if err != nil {
+			return WrapError(ErrConfig, "failed to make path absolute", err).
+				WithDetail("path", newPath)
+		}
+	}
+	newPath = filepath.Clean(newPath)
+
+	if normalizePath(newPath) == normalizePath(ws.Path) {
+		return nil
+	}
+
+	if _, err := os.Stat(newPath); err == nil {
+		return NewError(ErrConflict, "target path already exists").
+			WithDetail("path", newPath).
+			WithHint("Choose a different directory", "")
+	}
+
+	// git worktree move does not create missing parent directories
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return WrapError(ErrConfig, "failed to create parent directory", err).
+			WithDetail("path", filepath.Dir(newPath))
+	}
+
+	// Load metadata before touching git so a broken store aborts early
+	meta, metaErr := e.store.Get(ws.ID)
+	hasMeta := metaErr == nil
+	if metaErr != nil {
+		if yerr, ok := metaErr.(*Error); !ok || yerr.Code != ErrNotFound {
+			return metaErr
+		}
+	}
+
+	// Move git worktree
+	oldPath := ws.Path
+	if err := e.repo.MoveWorktree(oldPath, newPath); err != nil {
 		return err
 	}
 
-	// Update name
-	meta.Name = newName
-	meta.UpdatedAt = time.Now()
+	// Untracked worktrees have nothing else to update
+	if !hasMeta {
+		return nil
+	}
 
-	// Save
-	return e.store.Set(ws.ID, meta)
-}
+	// Update metadata, rolling back the move if it can't be persisted
+	meta.Path = newPath
+	meta.UpdatedAt = time.Now()
+	if err := e.store.Set(ws.ID, meta); err != nil {
+		if rbErr := e.repo.MoveWorktree(newPath, oldPath); rbErr != nil {
+			return WrapError(ErrBroken, "failed to save metadata and roll back move", err).
+				WithDetail("path", newPath).
+				WithDetail("originalPath", oldPath).
+				WithDetail("rollbackError", rbErr.Error()).
+				WithHint("Run doctor to reconcile metadata", "yagwt doctor")
+		}
+		return err
+	}
 
-// Move moves a workspace (stub for Phase 3)
-func (e *engine) Move(selector Selector, newPath string) error {
-	return NewError(ErrConfig, "move operation not yet implemented")
+	return nil
 }
 
 // Pin pins a workspace
 func (e *engine) Pin(selector Selector) error {
-	return e.setFlag(selector, "pinned", true)
+	return e.updateMetadata(selector, func(ws Workspace, meta *metadata.WorkspaceMetadata) error {
+		if ws.Flags.Ephemeral {
+			return NewError(ErrPolicy, "workspace is ephemeral").
+				WithDetail("id", ws.ID).
+				WithDetail("name", ws.Name).
+				WithHint("Make the workspace permanent first", "yagwt permanent "+ws.Name)
+		}
+
+		meta.Flags["pinned"] = true
+		return nil
+	})
 }
 
 // Unpin unpins a workspace
@@ -767,49 +1780,185 @@ func (e *engine) Unlock(selector Selector) error {
 	return e.setFlag(selector, "locked", false)
 }
 
-// setFlag sets a flag on a workspace
-func (e *engine) setFlag(selector Selector, flag string, value bool) error {
-	// Acquire lock
-	lck, err := e.lockMgr.NewLock(e.lockPath)
-	if err != nil {
-		return err
+// SetEphemeral marks a workspace ephemeral so TTL cleanup can remove it
+func (e *engine) SetEphemeral(selector Selector, opts EphemeralOptions) error {
+	return e.updateMetadata(selector, func(ws Workspace, meta *metadata.WorkspaceMetadata) error {
+		if ws.IsPrimary {
+			return NewError(ErrPolicy, "cannot make the primary workspace ephemeral").
+				WithDetail("name", ws.Name)
+		}
+		if ws.Flags.Pinned {
+			return NewError(ErrPolicy, "workspace is pinned").
+				WithDetail("id", ws.ID).
+				WithDetail("name", ws.Name).
+				WithHint("Unpin the workspace first", "yagwt unpin "+ws.Name)
+		}
+
+		meta.Flags["ephemeral"] = true
+		meta.Ephemeral = e.newEphemeral(opts.TTL, opts.Sliding, time.Now())
+		return nil
+	})
+}
+
+// SetPermanent clears the ephemeral flag and TTL from a workspace
+func (e *engine) SetPermanent(selector Selector) error {
+	return e.updateMetadata(selector, func(ws Workspace, meta *metadata.WorkspaceMetadata) error {
+		meta.Flags["ephemeral"] = false
+		meta.Ephemeral = nil
+		return nil
+	})
+}
+
+// Renew restarts an ephemeral workspace's TTL from now. A zero ttl keeps
+// the workspace's current TTL.
+func (e *engine) Renew(selector Selector, ttl time.Duration) error {
+	return e.updateMetadata(selector, func(ws Workspace, meta *metadata.WorkspaceMetadata) error {
+		if meta.Ephemeral == nil {
+			return NewError(ErrPolicy, "workspace is not ephemeral").
+				WithDetail("id", ws.ID).
+				WithDetail("name", ws.Name).
+				WithHint("Make it ephemeral first", "yagwt ephemeral "+ws.Name)
+		}
+
+		if ttl <= 0 {
+			ttl = time.Duration(meta.Ephemeral.TTLSeconds) * time.Second
+		}
+		meta.Ephemeral.TTLSeconds = int(ttl.Seconds())
+		meta.Ephemeral.ExpiresAt = time.Now().Add(ttl)
+		return nil
+	})
+}
+
+// newEphemeral builds TTL metadata, falling back to the configured defaults
+func (e *engine) newEphemeral(ttl time.Duration, sliding bool, now time.Time) *metadata.EphemeralMetadata {
+	if ttl <= 0 {
+		ttl = time.Duration(e.config.Workspace.DefaultTTL)
+	}
+	if ttl <= 0 {
+		ttl = 7 * 24 * time.Hour
 	}
 
-	if err := lck.Acquire(3 * time.Second); err != nil {
-		return err
+	return &metadata.EphemeralMetadata{
+		TTLSeconds: int(ttl.Seconds()),
+		ExpiresAt:  now.Add(ttl),
+		Sliding:    sliding || e.config.Workspace.SlidingTTL,
 	}
-	defer lck.Release()
+}
 
-	// Resolve workspace
-	ws, err := e.Get(selector)
+// ephemeralInfo converts TTL metadata for display. With a sliding TTL the
+// expiry is pushed to the last recorded activity plus the TTL.
+func ephemeralInfo(meta metadata.WorkspaceMetadata) *EphemeralInfo {
+	if meta.Ephemeral == nil {
+		return nil
+	}
+
+	info := &EphemeralInfo{
+		TTLSeconds: meta.Ephemeral.TTLSeconds,
+		ExpiresAt:  meta.Ephemeral.ExpiresAt,
+		Sliding:    meta.Ephemeral.Sliding,
+	}
+
+	if info.Sliding {
+		ttl := time.Duration(info.TTLSeconds) * time.Second
+		for _, at := range []*time.Time{meta.Activity.LastOpenedAt, meta.Activity.LastGitActivityAt} {
+			if at != nil && at.Add(ttl).After(info.ExpiresAt) {
+				info.ExpiresAt = at.Add(ttl)
+			}
+		}
+	}
+
+	return info
+}
+
+// Touch records that a workspace was opened and runs the post-open hook
+func (e *engine) Touch(selector Selector) (TouchResult, error) {
+	var result TouchResult
+	err := e.updateMetadata(selector, func(ws Workspace, meta *metadata.WorkspaceMetadata) error {
+		now := time.Now()
+		meta.Activity.LastOpenedAt = &now
+		result.Workspace = ws
+		result.Workspace.Activity.LastOpenedAt = &now
+		return nil
+	})
 	if err != nil {
-		return err
+		return result, err
 	}
 
-	// Load metadata
-	meta, err := e.store.Get(ws.ID)
+	// The hook runs without the lock (failure is reported, never fatal)
+	hookResult, err := e.hooks.Execute(hooks.PostOpen, e.hookContext(result.Workspace, "open"))
+	if hookResult != nil {
+		result.Hooks = append(result.Hooks, *hookResult)
+	}
 	if err != nil {
-		return err
+		result.Warnings = append(result.Warnings, hookWarning(hooks.PostOpen, err))
 	}
 
-	// Update flag
-	if meta.Flags == nil {
-		meta.Flags = make(map[string]bool)
+	return result, nil
+}
+
+// updateMetadata applies fn to a workspace's metadata under the engine lock
+func (e *engine) updateMetadata(selector Selector, fn func(ws Workspace, meta *metadata.WorkspaceMetadata) error) error {
+	return e.withLock(writeLockTimeout, func() error {
+		ws, err := e.Locate(selector)
+		if err != nil {
+			return err
+		}
+
+		return e.store.Update(func(m *metadata.Metadata) error {
+			meta, ok := m.Workspaces[ws.ID]
+			if !ok {
+				return NewError(ErrNotFound, "workspace metadata not found").
+					WithDetail("id", ws.ID)
+			}
+
+			if meta.Flags == nil {
+				meta.Flags = make(map[string]bool)
+			}
+
+			if err := fn(ws, &meta); err != nil {
+				return err
+			}
+			meta.UpdatedAt = time.Now()
+
+			m.SetWorkspace(meta)
+			return nil
+		})
+	})
+}
+
+// setFlag sets a flag on a workspace
+func (e *engine) setFlag(selector Selector, flag string, value bool) error {
+	return e.updateMetadata(selector, func(ws Workspace, meta *metadata.WorkspaceMetadata) error {
+		meta.Flags[flag] = value
+		return nil
+	})
+}
+
+// Cleanup generates and optionally executes a cleanup plan. When executing,
+// planning and removal happen under one lock so the plan cannot go stale.
+func (e *engine) Cleanup(opts CleanupOptions) (plan CleanupPlan, err error) {
+	if opts.DryRun {
+		return e.cleanup(opts)
 	}
-	meta.Flags[flag] = value
-	meta.UpdatedAt = time.Now()
 
-	// Save
-	return e.store.Set(ws.ID, meta)
+	err = e.withLock(cleanupLockTimeout, func() error {
+		plan, err = e.cleanup(opts)
+		return err
+	})
+	return plan, err
 }
 
-// Cleanup generates and optionally executes a cleanup plan
-func (e *engine) Cleanup(opts CleanupOptions) (CleanupPlan, error) {
+// cleanup plans and, unless DryRun, executes a cleanup; executing requires
+// the caller to hold the engine lock
+func (e *engine) cleanup(opts CleanupOptions) (CleanupPlan, error) {
 	// Get policy
-	policy := cleanup.GetPolicy(opts.Policy)
+	policy, err := cleanup.GetPolicy(opts.Policy, e.config.Cleanup.Policies)
+	if err != nil {
+		return CleanupPlan{}, err
+	}
 
-	// List all workspaces
-	workspaces, err := e.List(ListOptions{})
+	// List the workspaces the policy applies to
+	workspaces, err := e.List(ListOptions{Filter: policy.Match()})
 	if err != nil {
 		return CleanupPlan{}, err
 	}
@@ -832,32 +1981,33 @@ func (e *engine) Cleanup(opts CleanupOptions) (CleanupPlan, error) {
 		return plan, nil
 	}
 
-	// Acquire lock for write operations
-	lck, err := e.lockMgr.NewLock(e.lockPath)
-	if err != nil {
-		return plan, err
-	}
-
-	if err := lck.Acquire(5 * time.Second); err != nil {
-		return plan, err
-	}
-	defer lck.Release()
-
 	// Execute removals
 	var executed []RemovalAction
 	for _, action := range actions {
-		// Determine on-dirty strategy
+		// Determine on-dirty strategy (explicit option wins over policy)
 		onDirty := opts.OnDirty
 		if onDirty == "" {
-			onDirty = "fail"
+			onDirty = policy.OnDirty()
 		}
 		action.OnDirty = onDirty
 
 		// Try to remove
-		err := e.Remove(
+		trash := policy.Trash(e.config.Trash.Enabled)
+		result, err := e.remove(
 			Selector{Type: SelectorID, Value: action.Workspace.ID},
-			RemoveOptions{OnDirty: onDirty},
+			RemoveOptions{
+				OnDirty:      onDirty,
+				DeleteBranch: policy.DeleteBranch(),
+				Trash:        trash,
+				NoTrash:      !trash,
+				IgnorePinned: !policy.RespectPinned(),
+			},
+			journal.OpCleanup,
 		)
+		action.Hooks = result.Hooks
+		action.Trashed = result.Trashed
+		action.DeletedBranch = result.DeletedBranch
+		plan.Warnings = append(plan.Warnings, result.Warnings...)
 		if err != nil {
 			// Add warning but continue with other removals
 			plan.Warnings = append(plan.Warnings, Warning{
@@ -876,12 +2026,98 @@ func (e *engine) Cleanup(opts CleanupOptions) (CleanupPlan, error) {
 	return plan, nil
 }
 
-// Doctor detects and optionally repairs inconsistencies
-func (e *engine) Doctor(opts DoctorOptions) (DoctorReport, error) {
-	report := DoctorReport{
-		BrokenWorkspaces: []Workspace{},
-		Repairs:          []Repair{},
-		Warnings:         []Warning{},
+// Doctor detects and optionally repairs inconsistencies. Repairs run under
+// the engine lock so they cannot race with other write operations.
+func (e *engine) Doctor(opts DoctorOptions) (report DoctorReport, err error) {
+	if opts.Migrate {
+		return e.planMigration()
+	}
+	if opts.DryRun {
+		return e.doctor(opts)
+	}
+
+	err = e.withLock(writeLockTimeout, func() error {
+		report, err = e.doctor(opts)
+		return err
+	})
+	return report, err
+}
+
+// doctor implements Doctor; repairing requires the caller to hold the
+// engine lock
+func (e *engine) doctor(opts DoctorOptions) (DoctorReport, error) {
+	// A damaged meta.json is rebuilt before anything else reads it
+	var rebuild *Repair
+	if damaged := e.store.Verify(); damaged != nil {
+		rebuild = &Repair{
+			Issue: "Metadata file is damaged: " + damaged.Error(),
+			Fix:   "Rebuild from backup generations and git worktree list",
+		}
+	}
+
+	var rebuildWarnings []Warning
+	if rebuild != nil && !opts.DryRun {
+		if count, err := e.rebuildMetadata(); err != nil {
+			rebuildWarnings = append(rebuildWarnings, Warning{
+				Code:    "repair_failed",
+				Message: "Failed to rebuild metadata: " + err.Error(),
+			})
+		} else {
+			rebuild.Applied = true
+			rebuild.Fix += fmt.Sprintf(" (%d workspace(s) recovered)", count)
+		}
+	}
+
+	report, err := e.planMigration()
+	if err != nil {
+		// Without any readable generation there is nothing more to check
+		if rebuild != nil && opts.DryRun {
+			report.Repairs = append(report.Repairs, *rebuild)
+			return report, nil
+		}
+		return report, err
+	}
+	report.Warnings = append(report.Warnings, rebuildWarnings...)
+
+	// Upgrade the metadata schema first so the backup holds the file as
+	// it was before any other repair
+	if report.Migration != nil && !opts.DryRun {
+		if _, err := e.store.Migrate(); err != nil {
+			report.Warnings = append(report.Warnings, Warning{
+				Code:    "repair_failed",
+				Message: "Failed to migrate metadata: " + err.Error(),
+			})
+		} else {
+			report.Repairs[0].Applied = true
+		}
+	}
+	if rebuild != nil {
+		report.Repairs = append([]Repair{*rebuild}, report.Repairs...)
+	}
+
+	// Older versions stored metadata in the git dir of whichever worktree
+	// they ran in; fold those files into the shared store
+	strays, _ := filepath.Glob(filepath.Join(e.repo.CommonDir(), "worktrees", "*", "yagwt", "meta.json"))
+	for _, path := range strays {
+		repair := Repair{
+			Issue: "Per-worktree metadata file outside the shared store: " + path,
+			Fix:   "Merge its workspaces into the shared metadata",
+		}
+
+		if !opts.DryRun {
+			merged, skipped, err := e.store.MergeFile(path)
+			if err != nil {
+				report.Warnings = append(report.Warnings, Warning{
+					Code:    "repair_failed",
+					Message: "Failed to merge " + path + ": " + err.Error(),
+				})
+			} else {
+				repair.Applied = true
+				repair.Fix += fmt.Sprintf(" (%d merged, %d skipped as duplicates)", merged, skipped)
+			}
+		}
+
+		report.Repairs = append(report.Repairs, repair)
 	}
 
 	// Get git worktrees
@@ -905,6 +2141,9 @@ func (e *engine) Doctor(opts DoctorOptions) (DoctorReport, error) {
 
 	metaPaths := make(map[string]string) // path -> ID
 	for id, ws := range meta.Workspaces {
+		if ws.Trash != nil {
+			continue
+		}
 		normalizedPath := normalizePath(ws.Path)
 		metaPaths[normalizedPath] = id
 	}
@@ -937,6 +2176,14 @@ func (e *engine) Doctor(opts DoctorOptions) (DoctorReport, error) {
 					})
 				} else {
 					repair.Applied = true
+					forgotten := ws
+					e.appendJournal(journal.Entry{
+						Operation:   journal.OpForgetMissing,
+						WorkspaceID: id,
+						Name:        ws.Name,
+						Path:        ws.Path,
+						Previous:    &forgotten,
+					}, &report.Warnings)
 				}
 			}
 
@@ -948,9 +2195,9 @@ func (e *engine) Doctor(opts DoctorOptions) (DoctorReport, error) {
 	for _, wt := range worktrees {
 		normalizedPath := normalizePath(wt.Path)
 		if _, hasMetadata := metaPaths[normalizedPath]; !hasMetadata {
-			// Skip primary worktree (usually doesn't need metadata)
-			isPrimary := len(worktrees) > 0 && normalizedPath == normalizePath(worktrees[0].Path)
-			if isPrimary {
+			// Skip the primary worktree (usually doesn't need metadata) and
+			// the bare repository entry
+			if wt.Main || wt.Bare {
 				continue
 			}
 
@@ -968,9 +2215,11 @@ func (e *engine) Doctor(opts DoctorOptions) (DoctorReport, error) {
 				now := time.Now()
 
 				wsMeta := metadata.WorkspaceMetadata{
-					ID:   wsID,
-					Name: wsName,
-					Path: wt.Path,
+					ID:      wsID,
+					Name:    wsName,
+					Path:    wt.Path,
+					Branch:  wt.Branch,
+					HeadSHA: wt.HEAD,
 					Flags: map[string]bool{
 						"pinned":    false,
 						"ephemeral": false,
@@ -998,6 +2247,8 @@ func (e *engine) Doctor(opts DoctorOptions) (DoctorReport, error) {
 		}
 	}
 
+	report.Repairs = append(report.Repairs, e.checkBranches(worktrees, meta, opts, &report.Warnings)...)
+
 	// Check for stale index entries
 	reloadedMeta, _ := e.store.Load()
 	for path, id := range reloadedMeta.Index.ByPath {
@@ -1028,6 +2279,156 @@ func (e *engine) Doctor(opts DoctorOptions) (DoctorReport, error) {
 	return report, nil
 }
 
+// checkBranches finds workspaces whose recorded branch was renamed or
+// deleted outside yagwt. A renamed branch is recorded under its new name; a
+// deleted branch that is still checked out is recreated at the last HEAD
+// yagwt saw.
+func (e *engine) checkBranches(worktrees []git.Worktree, meta metadata.Metadata, opts DoctorOptions, warnings *[]Warning) []Repair {
+	byPath := make(map[string]git.Worktree)
+	for _, wt := range worktrees {
+		byPath[normalizePath(wt.Path)] = wt
+	}
+
+	var repairs []Repair
+	for id, ws := range meta.Workspaces {
+		wt, ok := byPath[normalizePath(ws.Path)]
+		if ws.Trash != nil || ws.Branch == "" || !ok {
+			continue
+		}
+
+		var repair Repair
+		var apply func() error
+		switch {
+		case wt.Branch == ws.Branch && unborn(wt.HEAD):
+			repair = Repair{
+				WorkspaceID: id,
+				Issue:       "Branch '" + ws.Branch + "' of workspace '" + ws.Name + "' was deleted",
+				Fix:         "Recreate it at " + shortSHA(ws.HeadSHA),
+			}
+			if ws.HeadSHA == "" {
+				repair.Fix = "None: no HEAD was recorded; check out another branch in the workspace"
+				break
+			}
+			apply = func() error {
+				return e.repo.UpdateRef("refs/heads/"+ws.Branch, ws.HeadSHA)
+			}
+
+		case wt.Branch != ws.Branch && !e.branchExists(ws.Branch):
+			repair = Repair{
+				WorkspaceID: id,
+				Issue:       "Branch '" + ws.Branch + "' of workspace '" + ws.Name + "' no longer exists; the workspace is detached",
+				Fix:         "Forget the branch",
+			}
+			if wt.Branch != "" {
+				repair.Issue = "Branch '" + ws.Branch + "' of workspace '" + ws.Name + "' was renamed to '" + wt.Branch + "'"
+				repair.Fix = "Record the new branch name"
+			}
+			apply = func() error {
+				return e.store.Update(func(m *metadata.Metadata) error {
+					if current, ok := m.Workspaces[id]; ok {
+						current.Branch = wt.Branch
+						current.HeadSHA = wt.HEAD
+						m.SetWorkspace(current)
+					}
+					return nil
+				})
+			}
+
+		default:
+			continue
+		}
+
+		if apply != nil && !opts.DryRun {
+			if err := apply(); err != nil {
+				*warnings = append(*warnings, Warning{
+					Code:    "repair_failed",
+					Message: "Failed to repair branch of '" + ws.Name + "': " + err.Error(),
+				})
+			} else {
+				repair.Applied = true
+			}
+		}
+
+		repairs = append(repairs, repair)
+	}
+
+	return repairs
+}
+
+// shortSHA abbreviates a commit SHA for messages
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// rebuildMetadata recreates meta.json from its backup generations, keeping
+// workspaces git still lists and trashed workspaces whose files remain
+func (e *engine) rebuildMetadata() (int, error) {
+	worktrees, err := e.repo.ListWorktrees()
+	if err != nil {
+		return 0, err
+	}
+
+	worktreePaths := make(map[string]bool)
+	for _, wt := range worktrees {
+		worktreePaths[normalizePath(wt.Path)] = true
+	}
+
+	return e.store.Rebuild(func(ws metadata.WorkspaceMetadata) bool {
+		if ws.Trash != nil {
+			_, err := os.Stat(ws.Trash.Dir)
+			return err == nil
+		}
+		return worktreePaths[normalizePath(ws.Path)]
+	})
+}
+
+// planMigration reports a pending metadata schema migration as a repair
+func (e *engine) planMigration() (DoctorReport, error) {
+	report := DoctorReport{
+		BrokenWorkspaces: []Workspace{},
+		Repairs:          []Repair{},
+		Warnings:         []Warning{},
+	}
+
+	plan, err := e.store.PlanMigration()
+	if err != nil {
+		return report, err
+	}
+	if !plan.Needed() {
+		return report, nil
+	}
+
+	report.Migration = &plan
+	report.Repairs = append(report.Repairs, Repair{
+		Issue: fmt.Sprintf("Metadata uses schema v%d; this version uses v%d", plan.FromVersion, plan.ToVersion),
+		Fix:   "Migrate " + plan.Path + " (original kept at " + plan.BackupPath + ")",
+	})
+	return report, nil
+}
+
+// hookContext builds the environment context passed to lifecycle hooks
+func (e *engine) hookContext(ws Workspace, operation string) hooks.Context {
+	return hooks.Context{
+		RepoRoot:      e.repo.Root(),
+		WorkspaceID:   ws.ID,
+		WorkspacePath: ws.Path,
+		WorkspaceName: ws.Name,
+		TargetRef:     ws.Target.Ref,
+		Operation:     operation,
+	}
+}
+
+// hookWarning converts a non-fatal hook failure into a warning
+func hookWarning(hook hooks.Type, err error) Warning {
+	return Warning{
+		Code:    "hook_failed",
+		Message: "Hook '" + string(hook) + "' failed: " + err.Error(),
+	}
+}
+
 // selectorToString converts a Selector back to a string for error messages
 func selectorToString(s Selector) string {
 	switch s.Type {
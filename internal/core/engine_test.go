@@ -0,0 +1,61 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bmf/yagwt/internal/metadata"
+)
+
+func TestEphemeralInfoSliding(t *testing.T) {
+	now := time.Now()
+	created := now.Add(-10 * 24 * time.Hour)
+	opened := now.Add(-time.Hour)
+
+	meta := metadata.WorkspaceMetadata{
+		Ephemeral: &metadata.EphemeralMetadata{
+			TTLSeconds: int((7 * 24 * time.Hour).Seconds()),
+			ExpiresAt:  created.Add(7 * 24 * time.Hour),
+		},
+		Activity: metadata.ActivityMetadata{
+			LastGitActivityAt: &created,
+			LastOpenedAt:      &opened,
+		},
+	}
+
+	// Fixed TTL ignores activity
+	info := ephemeralInfo(meta)
+	if !info.ExpiresAt.Equal(created.Add(7 * 24 * time.Hour)) {
+		t.Errorf("ExpiresAt = %v, want %v", info.ExpiresAt, created.Add(7*24*time.Hour))
+	}
+
+	// Sliding TTL counts from the latest activity
+	meta.Ephemeral.Sliding = true
+	info = ephemeralInfo(meta)
+	if want := opened.Add(7 * 24 * time.Hour); !info.ExpiresAt.Equal(want) {
+		t.Errorf("ExpiresAt = %v, want %v", info.ExpiresAt, want)
+	}
+
+	if ephemeralInfo(metadata.WorkspaceMetadata{}) != nil {
+		t.Error("Expected nil info for permanent workspace")
+	}
+}
+
+func TestBareWorkspaceRoot(t *testing.T) {
+	tests := []struct {
+		gitDir string
+		want   string
+	}{
+		{"/src/project/.bare", "/src/project/.workspaces"},
+		{"/src/repos/app.git", "/src/repos/.workspaces/app"},
+		{"/src/repos/lib", "/src/repos/.workspaces/lib"},
+	}
+
+	for _, tt := range tests {
+		got := bareWorkspaceRoot(filepath.FromSlash(tt.gitDir), ".workspaces")
+		if got != filepath.FromSlash(tt.want) {
+			t.Errorf("bareWorkspaceRoot(%q) = %q, want %q", tt.gitDir, got, tt.want)
+		}
+	}
+}
@@ -21,6 +21,7 @@ const (
 	ErrConflict  = errors.ErrConflict
 	ErrTimeout   = errors.ErrTimeout
 	ErrConfig    = errors.ErrConfig
+	ErrHook      = errors.ErrHook
 )
 
 // Re-export error constructors
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"strings"
+	"time"
+
+	"github.com/bmf/yagwt/internal/filter"
+)
+
+// filterAdapter adapts core.Workspace to the filter.Workspace interface and
+// its sub-interfaces
+type filterAdapter struct {
+	ws Workspace
+}
+
+func (a *filterAdapter) GetName() string              { return a.ws.Name }
+func (a *filterAdapter) GetTarget() filter.Target     { return a }
+func (a *filterAdapter) GetFlags() filter.Flags       { return a }
+func (a *filterAdapter) GetStatus() filter.Status     { return a }
+func (a *filterAdapter) GetActivity() filter.Activity { return a }
+
+func (a *filterAdapter) GetCreatedAt() *time.Time { return a.ws.CreatedAt }
+func (a *filterAdapter) GetLabels() []string      { return a.ws.Labels }
+
+func (a *filterAdapter) GetField(key string) (interface{}, bool) {
+	value, ok := a.ws.Fields[key]
+	return value, ok
+}
+
+func (a *filterAdapter) GetExpiresAt() *time.Time {
+	if a.ws.Ephemeral == nil {
+		return nil
+	}
+	return &a.ws.Ephemeral.ExpiresAt
+}
+
+func (a *filterAdapter) GetType() string  { return a.ws.Target.Type }
+func (a *filterAdapter) GetShort() string { return a.ws.Target.Short }
+
+func (a *filterAdapter) IsPinned() bool    { return a.ws.Flags.Pinned }
+func (a *filterAdapter) IsEphemeral() bool { return a.ws.Flags.Ephemeral }
+func (a *filterAdapter) IsLocked() bool    { return a.ws.Flags.Locked }
+func (a *filterAdapter) IsBroken() bool    { return a.ws.Flags.Broken }
+
+func (a *filterAdapter) IsDirty() bool         { return a.ws.Status.Dirty }
+func (a *filterAdapter) HasConflicts() bool    { return a.ws.Status.Conflicts }
+func (a *filterAdapter) IsSubmoduleOnly() bool { return a.ws.Status.SubmoduleOnly }
+func (a *filterAdapter) IsDetached() bool      { return a.ws.Status.Detached }
+func (a *filterAdapter) GetAhead() int         { return a.ws.Status.Ahead }
+func (a *filterAdapter) GetBehind() int        { return a.ws.Status.Behind }
+
+func (a *filterAdapter) GetLastActiveAt() *time.Time { return a.ws.Activity.LastActiveAt() }
+
+// WorkspaceFields lists the field names accepted by ListOptions.Fields, in
+// output order. A group name such as "status" selects all of its sub-fields.
+var WorkspaceFields = []string{
+	"id", "name", "path", "isPrimary", "createdAt", "labels", "fields",
+	"target.type", "target.ref", "target.short", "target.headSHA", "target.upstream",
+	"flags.pinned", "flags.ephemeral", "flags.locked", "flags.broken",
+	"ephemeral.ttlSeconds", "ephemeral.expiresAt", "ephemeral.sliding",
+	"activity.lastOpenedAt", "activity.lastGitActivityAt",
+	"status.dirty", "status.conflicts", "status.ahead", "status.behind", "status.branch", "status.detached",
+	"status.submoduleOnly",
+}
+
+// ExpandFields validates field names and expands groups into their
+// sub-fields, preserving the requested order
+func ExpandFields(fields []string) ([]string, error) {
+	var expanded []string
+	seen := make(map[string]bool)
+
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+
+		// Individual custom fields are open-ended
+		if strings.HasPrefix(field, "fields.") && len(field) > len("fields.") {
+			if !seen[field] {
+				seen[field] = true
+				expanded = append(expanded, field)
+			}
+			continue
+		}
+
+		matched := false
+		for _, known := range WorkspaceFields {
+			if known == field || strings.HasPrefix(known, field+".") {
+				matched = true
+				if !seen[known] {
+					seen[known] = true
+					expanded = append(expanded, known)
+				}
+			}
+		}
+		if !matched {
+			return nil, NewError(ErrConfig, "unknown field").
+				WithDetail("field", field).
+				WithHint("Valid fields: "+strings.Join(WorkspaceFields, ", ")+" (or a group such as status, or fields.<key>)", "")
+		}
+	}
+
+	return expanded, nil
+}
+
+// fieldsNeedGitState reports whether any field is filled from git status or
+// activity probes (a sliding ephemeral expiry depends on activity)
+func fieldsNeedGitState(fields []string) bool {
+	for _, field := range fields {
+		if strings.HasPrefix(field, "status.") || strings.HasPrefix(field, "activity.") ||
+			strings.HasPrefix(field, "ephemeral.") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFilter parses a filter expression, reporting syntax errors as
+// ErrConfig
+func parseFilter(expr string) (filter.Filter, error) {
+	f, err := filter.ParseFilter(expr)
+	if err != nil {
+		if _, ok := err.(*Error); ok {
+			return nil, err
+		}
+		return nil, WrapError(ErrConfig, "invalid filter expression", err).
+			WithDetail("filter", expr)
+	}
+	return f, nil
+}
+
+// applyFilter returns the workspaces matching f, preserving order
+func applyFilter(workspaces []Workspace, f filter.Filter) []Workspace {
+	var matched []Workspace
+	for _, ws := range workspaces {
+		if f.Match(&filterAdapter{ws: ws}) {
+			matched = append(matched, ws)
+		}
+	}
+	return matched
+}
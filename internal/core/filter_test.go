@@ -0,0 +1,69 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "leaf fields keep order",
+			fields: []string{"path", "name"},
+			want:   []string{"path", "name"},
+		},
+		{
+			name:   "group expands",
+			fields: []string{"name", "flags"},
+			want:   []string{"name", "flags.pinned", "flags.ephemeral", "flags.locked", "flags.broken"},
+		},
+		{
+			name:   "duplicates dropped",
+			fields: []string{"status.dirty", "status"},
+			want:   []string{"status.dirty", "status.conflicts", "status.ahead", "status.behind", "status.branch", "status.detached", "status.submoduleOnly"},
+		},
+		{
+			name:    "unknown field",
+			fields:  []string{"name", "color"},
+			wantErr: true,
+		},
+		{
+			name:    "partial group name",
+			fields:  []string{"stat"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandFields(tt.fields)
+			if tt.wantErr {
+				coreErr, ok := err.(*Error)
+				if !ok || coreErr.Code != ErrConfig {
+					t.Fatalf("Expected ErrConfig, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExpandFields() failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldsNeedGitState(t *testing.T) {
+	if fieldsNeedGitState([]string{"id", "name", "path", "target.short", "flags.pinned"}) {
+		t.Error("Identity fields should not need git state")
+	}
+	if !fieldsNeedGitState([]string{"name", "status.dirty"}) {
+		t.Error("status.dirty should need git state")
+	}
+}
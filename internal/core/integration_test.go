@@ -1,13 +1,17 @@
 package core_test
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/bmf/yagwt/internal/core"
+	"github.com/bmf/yagwt/internal/lock"
+	"github.com/bmf/yagwt/internal/metadata"
 )
 
 // setupTestRepo creates a temporary git repository for testing
@@ -143,10 +147,11 @@ func TestCreateWorkspace(t *testing.T) {
 		Dir:    filepath.Join(repoDir, ".workspaces", "my-feature"),
 	}
 
-	ws, err := engine.Create(opts)
+	result, err := engine.Create(opts)
 	if err != nil {
 		t.Fatalf("Create() failed: %v", err)
 	}
+	ws := result.Workspace
 
 	// Verify workspace properties
 	if ws.ID == "" {
@@ -203,10 +208,11 @@ func TestCreateEphemeralWorkspace(t *testing.T) {
 		TTL:       7 * 24 * time.Hour,
 	}
 
-	ws, err := engine.Create(opts)
+	result, err := engine.Create(opts)
 	if err != nil {
 		t.Fatalf("Create() failed: %v", err)
 	}
+	ws := result.Workspace
 
 	// Verify ephemeral flag
 	if !ws.Flags.Ephemeral {
@@ -239,7 +245,7 @@ func TestGetWorkspace(t *testing.T) {
 	}
 
 	// Create workspace
-	created, err := engine.Create(core.CreateOptions{
+	result, err := engine.Create(core.CreateOptions{
 		Target: "feature-test",
 		Name:   "test-workspace",
 		Dir:    filepath.Join(repoDir, ".workspaces", "test-workspace"),
@@ -247,6 +253,7 @@ func TestGetWorkspace(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Create() failed: %v", err)
 	}
+	created := result.Workspace
 
 	// Get by ID
 	ws, err := engine.Get(core.Selector{Type: core.SelectorID, Value: created.ID})
@@ -304,7 +311,7 @@ func TestResolveWorkspaces(t *testing.T) {
 	}
 
 	// Create two workspaces on the same branch
-	ws1, err := engine.Create(core.CreateOptions{
+	result, err := engine.Create(core.CreateOptions{
 		Target: "feature-test",
 		Name:   "workspace-1",
 		Dir:    filepath.Join(repoDir, ".workspaces", "workspace-1"),
@@ -312,6 +319,7 @@ func TestResolveWorkspaces(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Create() failed: %v", err)
 	}
+	ws1 := result.Workspace
 
 	// Resolve by branch should return at least one workspace
 	matches, err := engine.Resolve("branch:feature-test")
@@ -347,7 +355,7 @@ func TestRemoveWorkspace(t *testing.T) {
 	}
 
 	// Create workspace
-	ws, err := engine.Create(core.CreateOptions{
+	result, err := engine.Create(core.CreateOptions{
 		Target: "feature-test",
 		Name:   "to-remove",
 		Dir:    filepath.Join(repoDir, ".workspaces", "to-remove"),
@@ -355,11 +363,12 @@ func TestRemoveWorkspace(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Create() failed: %v", err)
 	}
+	ws := result.Workspace
 
 	wsPath := ws.Path
 
 	// Remove workspace
-	err = engine.Remove(
+	_, err = engine.Remove(
 		core.Selector{Type: core.SelectorID, Value: ws.ID},
 		core.RemoveOptions{},
 	)
@@ -395,7 +404,7 @@ func TestRemovePinnedWorkspace(t *testing.T) {
 	}
 
 	// Create pinned workspace
-	ws, err := engine.Create(core.CreateOptions{
+	result, err := engine.Create(core.CreateOptions{
 		Target: "feature-test",
 		Name:   "pinned-ws",
 		Dir:    filepath.Join(repoDir, ".workspaces", "pinned-ws"),
@@ -404,9 +413,10 @@ func TestRemovePinnedWorkspace(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Create() failed: %v", err)
 	}
+	ws := result.Workspace
 
 	// Should fail to remove pinned workspace
-	err = engine.Remove(
+	_, err = engine.Remove(
 		core.Selector{Type: core.SelectorID, Value: ws.ID},
 		core.RemoveOptions{},
 	)
@@ -434,7 +444,7 @@ func TestPinUnpinWorkspace(t *testing.T) {
 	}
 
 	// Create workspace
-	ws, err := engine.Create(core.CreateOptions{
+	result, err := engine.Create(core.CreateOptions{
 		Target: "feature-test",
 		Name:   "test-pin",
 		Dir:    filepath.Join(repoDir, ".workspaces", "test-pin"),
@@ -442,6 +452,7 @@ func TestPinUnpinWorkspace(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Create() failed: %v", err)
 	}
+	ws := result.Workspace
 
 	// Pin workspace
 	err = engine.Pin(core.Selector{Type: core.SelectorID, Value: ws.ID})
@@ -486,7 +497,7 @@ func TestRenameWorkspace(t *testing.T) {
 	}
 
 	// Create workspace
-	ws, err := engine.Create(core.CreateOptions{
+	result, err := engine.Create(core.CreateOptions{
 		Target: "feature-test",
 		Name:   "old-name",
 		Dir:    filepath.Join(repoDir, ".workspaces", "old-name"),
@@ -494,6 +505,7 @@ func TestRenameWorkspace(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Create() failed: %v", err)
 	}
+	ws := result.Workspace
 
 	// Rename workspace
 	err = engine.Rename(
@@ -537,7 +549,7 @@ func TestLockUnlockWorkspace(t *testing.T) {
 	}
 
 	// Create workspace
-	ws, err := engine.Create(core.CreateOptions{
+	result, err := engine.Create(core.CreateOptions{
 		Target: "feature-test",
 		Name:   "test-lock",
 		Dir:    filepath.Join(repoDir, ".workspaces", "test-lock"),
@@ -545,6 +557,7 @@ func TestLockUnlockWorkspace(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Create() failed: %v", err)
 	}
+	ws := result.Workspace
 
 	// Lock workspace
 	err = engine.Lock(core.Selector{Type: core.SelectorID, Value: ws.ID})
@@ -578,3 +591,2258 @@ func TestLockUnlockWorkspace(t *testing.T) {
 		t.Error("Workspace should be unlocked")
 	}
 }
+
+func TestMoveWorkspace(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	// Create workspace
+	result, err := engine.Create(core.CreateOptions{
+		Target: "feature-test",
+		Name:   "to-move",
+		Dir:    filepath.Join(repoDir, ".workspaces", "to-move"),
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	ws := result.Workspace
+
+	oldPath := ws.Path
+	newPath := filepath.Join(repoDir, ".workspaces", "nested", "moved")
+
+	// Move workspace
+	err = engine.Move(core.Selector{Type: core.SelectorID, Value: ws.ID}, newPath)
+	if err != nil {
+		t.Fatalf("Move() failed: %v", err)
+	}
+
+	// Old path should be gone, new path should exist
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("Old path %q should no longer exist", oldPath)
+	}
+	if _, err := os.Stat(newPath); err != nil {
+		t.Errorf("New path %q should exist: %v", newPath, err)
+	}
+
+	// Metadata should follow the worktree
+	moved, err := engine.Get(core.Selector{Type: core.SelectorID, Value: ws.ID})
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+
+	if moved.Flags.Broken {
+		t.Error("Moved workspace should not be broken")
+	}
+
+	if moved.Name != "to-move" {
+		t.Errorf("Workspace name = %q, want %q", moved.Name, "to-move")
+	}
+
+	if _, err := engine.Get(core.Selector{Type: core.SelectorPath, Value: newPath}); err != nil {
+		t.Errorf("Get() by new path failed: %v", err)
+	}
+}
+
+func TestMoveLockedWorkspace(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	result, err := engine.Create(core.CreateOptions{
+		Target: "feature-test",
+		Name:   "locked-move",
+		Dir:    filepath.Join(repoDir, ".workspaces", "locked-move"),
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	ws := result.Workspace
+
+	if err := engine.Lock(core.Selector{Type: core.SelectorID, Value: ws.ID}); err != nil {
+		t.Fatalf("Lock() failed: %v", err)
+	}
+
+	// Should refuse to move a locked workspace
+	err = engine.Move(core.Selector{Type: core.SelectorID, Value: ws.ID}, filepath.Join(repoDir, ".workspaces", "elsewhere"))
+	if err == nil {
+		t.Fatal("Move() should fail for locked workspace")
+	}
+
+	coreErr, ok := err.(*core.Error)
+	if !ok {
+		t.Fatalf("Expected *core.Error, got %T", err)
+	}
+
+	if coreErr.Code != core.ErrLocked {
+		t.Errorf("Expected error code %s, got %s", core.ErrLocked, coreErr.Code)
+	}
+}
+
+func TestMoveToExistingPath(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	result, err := engine.Create(core.CreateOptions{
+		Target: "feature-test",
+		Name:   "conflict-move",
+		Dir:    filepath.Join(repoDir, ".workspaces", "conflict-move"),
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	ws := result.Workspace
+
+	existing := filepath.Join(repoDir, ".workspaces", "occupied")
+	if err := os.MkdirAll(existing, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+
+	err = engine.Move(core.Selector{Type: core.SelectorID, Value: ws.ID}, existing)
+	if err == nil {
+		t.Fatal("Move() should fail when target exists")
+	}
+
+	coreErr, ok := err.(*core.Error)
+	if !ok {
+		t.Fatalf("Expected *core.Error, got %T", err)
+	}
+
+	if coreErr.Code != core.ErrConflict {
+		t.Errorf("Expected error code %s, got %s", core.ErrConflict, coreErr.Code)
+	}
+}
+
+// writeRepoConfig writes a repository-level config file
+func writeRepoConfig(t *testing.T, repoDir, content string) {
+	t.Helper()
+	configDir := filepath.Join(repoDir, ".yagwt")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "config.toml"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+}
+
+func TestCreateRunsPostCreateHook(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	writeRepoConfig(t, repoDir, `
+[hooks]
+postCreate = "echo created $YAGWT_WORKSPACE_NAME"
+`)
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	result, err := engine.Create(core.CreateOptions{
+		Target: "feature-test",
+		Name:   "hooked",
+		Dir:    filepath.Join(repoDir, ".workspaces", "hooked"),
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	if len(result.Hooks) != 1 {
+		t.Fatalf("Expected 1 hook result, got %d", len(result.Hooks))
+	}
+
+	if got := result.Hooks[0].Stdout; got != "created hooked\n" {
+		t.Errorf("Hook stdout = %q, want %q", got, "created hooked\n")
+	}
+}
+
+func TestPreRemoveHookAbortsRemoval(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	writeRepoConfig(t, repoDir, `
+[hooks]
+preRemove = "exit 1"
+`)
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	result, err := engine.Create(core.CreateOptions{
+		Target: "feature-test",
+		Name:   "guarded",
+		Dir:    filepath.Join(repoDir, ".workspaces", "guarded"),
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	ws := result.Workspace
+
+	_, err = engine.Remove(core.Selector{Type: core.SelectorID, Value: ws.ID}, core.RemoveOptions{})
+	if err == nil {
+		t.Fatal("Remove() should fail when pre-remove hook fails")
+	}
+
+	coreErr, ok := err.(*core.Error)
+	if !ok {
+		t.Fatalf("Expected *core.Error, got %T", err)
+	}
+
+	if coreErr.Code != core.ErrHook {
+		t.Errorf("Expected error code %s, got %s", core.ErrHook, coreErr.Code)
+	}
+
+	// Workspace must still exist
+	if _, err := os.Stat(ws.Path); err != nil {
+		t.Errorf("Workspace path should still exist: %v", err)
+	}
+}
+
+func TestCleanupUnknownPolicy(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	_, err = engine.Cleanup(core.CleanupOptions{Policy: "nonexistent", DryRun: true})
+	if err == nil {
+		t.Fatal("Cleanup() should fail for unknown policy")
+	}
+
+	coreErr, ok := err.(*core.Error)
+	if !ok {
+		t.Fatalf("Expected *core.Error, got %T", err)
+	}
+
+	if coreErr.Code != core.ErrConfig {
+		t.Errorf("Expected error code %s, got %s", core.ErrConfig, coreErr.Code)
+	}
+}
+
+func TestCleanupConfiguredPolicy(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	writeRepoConfig(t, repoDir, `
+[cleanup.policies.ci]
+removeEphemeral = true
+respectPinned = true
+onDirty = "stash"
+`)
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	_, err = engine.Create(core.CreateOptions{
+		Target:    "feature-test",
+		Name:      "short-lived",
+		Dir:       filepath.Join(repoDir, ".workspaces", "short-lived"),
+		Ephemeral: true,
+		TTL:       time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+
+	plan, err := engine.Cleanup(core.CleanupOptions{Policy: "ci", DryRun: true})
+	if err != nil {
+		t.Fatalf("Cleanup() failed: %v", err)
+	}
+
+	if len(plan.Actions) != 1 {
+		t.Fatalf("Expected 1 action, got %d", len(plan.Actions))
+	}
+
+	if plan.Actions[0].Reason != "Ephemeral workspace has expired" {
+		t.Errorf("Reason = %q, want %q", plan.Actions[0].Reason, "Ephemeral workspace has expired")
+	}
+}
+
+func TestCleanupPinnedWorkspaces(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	writeRepoConfig(t, repoDir, `
+[cleanup.policies.sweep]
+removeEphemeral = true
+respectPinned = false
+`)
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	result, err := engine.Create(core.CreateOptions{
+		Target:    "feature-test",
+		Name:      "pinned-scratch",
+		Dir:       filepath.Join(t.TempDir(), "pinned-scratch"),
+		Ephemeral: true,
+		TTL:       time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	// Pin refuses ephemeral workspaces, but metadata written before that
+	// rule can still have both flags
+	store, _ := metadata.NewStore(filepath.Join(repoDir, ".git"))
+	meta, err := store.Get(result.Workspace.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta.Flags["pinned"] = true
+	if err := store.Set(meta.ID, meta); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+
+	// Built-in policies all keep pinned workspaces out of the plan
+	for _, policy := range []string{"default", "conservative", "aggressive"} {
+		plan, err := engine.Cleanup(core.CleanupOptions{Policy: policy, DryRun: true})
+		if err != nil {
+			t.Fatalf("Cleanup(%s) failed: %v", policy, err)
+		}
+		if len(plan.Actions) != 0 {
+			t.Errorf("Cleanup(%s) planned %d actions for a pinned workspace", policy, len(plan.Actions))
+		}
+	}
+
+	// A policy that ignores pins removes the workspace instead of failing
+	plan, err := engine.Cleanup(core.CleanupOptions{Policy: "sweep"})
+	if err != nil {
+		t.Fatalf("Cleanup(sweep) failed: %v", err)
+	}
+	if len(plan.Actions) != 1 || len(plan.Warnings) != 0 {
+		t.Errorf("Cleanup(sweep) = %d actions, warnings %+v; want 1 action", len(plan.Actions), plan.Warnings)
+	}
+}
+
+func TestCreateUsesNameTemplate(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	writeRepoConfig(t, repoDir, `
+[workspace]
+rootStrategy = "inside"
+nameTemplate = "{ticket}-{slug}"
+`)
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	result, err := engine.Create(core.CreateOptions{
+		Target:    "feature/ABC-7-Login",
+		NewBranch: true,
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	ws := result.Workspace
+
+	if ws.Name != "ABC-7-login" {
+		t.Errorf("Name = %q, want %q", ws.Name, "ABC-7-login")
+	}
+
+	// Directory follows the default "{name}" path template
+	wantPath := filepath.Join(repoDir, ".workspaces", "ABC-7-login")
+	if _, err := os.Stat(wantPath); err != nil {
+		t.Errorf("Expected workspace at %q: %v", wantPath, err)
+	}
+
+	// A second branch rendering to the same name must be refused
+	_, err = engine.Create(core.CreateOptions{
+		Target:    "bugfix/ABC-7-login",
+		NewBranch: true,
+	})
+	if err == nil {
+		t.Fatal("Create() should fail when rendered name collides")
+	}
+
+	coreErr, ok := err.(*core.Error)
+	if !ok {
+		t.Fatalf("Expected *core.Error, got %T", err)
+	}
+
+	if coreErr.Code != core.ErrConflict {
+		t.Errorf("Expected error code %s, got %s", core.ErrConflict, coreErr.Code)
+	}
+
+	if len(coreErr.Hints) == 0 {
+		t.Error("Expected a hint on name conflict")
+	}
+}
+
+func TestRemoveDeletesMergedBranch(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	result, err := engine.Create(core.CreateOptions{
+		Target: "feature-test",
+		Name:   "merged",
+		Dir:    filepath.Join(repoDir, ".workspaces", "merged"),
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	removeResult, err := engine.Remove(
+		core.Selector{Type: core.SelectorID, Value: result.Workspace.ID},
+		core.RemoveOptions{DeleteBranch: true},
+	)
+	if err != nil {
+		t.Fatalf("Remove() failed: %v", err)
+	}
+
+	if removeResult.DeletedBranch != "feature-test" {
+		t.Errorf("DeletedBranch = %q, want %q", removeResult.DeletedBranch, "feature-test")
+	}
+
+	if err := runCommand(repoDir, "git", "rev-parse", "--verify", "refs/heads/feature-test"); err == nil {
+		t.Error("Branch feature-test should have been deleted")
+	}
+}
+
+func TestRemoveRefusesUnmergedBranch(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	result, err := engine.Create(core.CreateOptions{
+		Target: "feature-test",
+		Name:   "unmerged",
+		Dir:    filepath.Join(repoDir, ".workspaces", "unmerged"),
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	ws := result.Workspace
+
+	// Add a commit that only exists on the feature branch
+	if err := os.WriteFile(filepath.Join(ws.Path, "work.txt"), []byte("work\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := runCommand(ws.Path, "git", "add", "work.txt"); err != nil {
+		t.Fatalf("git add failed: %v", err)
+	}
+	if err := runCommand(ws.Path, "git", "commit", "-m", "Feature work"); err != nil {
+		t.Fatalf("git commit failed: %v", err)
+	}
+
+	selector := core.Selector{Type: core.SelectorID, Value: ws.ID}
+	_, err = engine.Remove(selector, core.RemoveOptions{DeleteBranch: true})
+	if err == nil {
+		t.Fatal("Remove() should refuse to delete an unmerged branch")
+	}
+
+	coreErr, ok := err.(*core.Error)
+	if !ok {
+		t.Fatalf("Expected *core.Error, got %T", err)
+	}
+
+	if coreErr.Code != core.ErrPolicy {
+		t.Errorf("Expected error code %s, got %s", core.ErrPolicy, coreErr.Code)
+	}
+
+	// Nothing should have been removed
+	if _, err := os.Stat(ws.Path); err != nil {
+		t.Errorf("Workspace should still exist after refusal: %v", err)
+	}
+
+	// Forcing deletes both
+	removeResult, err := engine.Remove(selector, core.RemoveOptions{DeleteBranch: true, ForceDeleteBranch: true})
+	if err != nil {
+		t.Fatalf("Remove() with force failed: %v", err)
+	}
+
+	if removeResult.DeletedBranch != "feature-test" {
+		t.Errorf("DeletedBranch = %q, want %q", removeResult.DeletedBranch, "feature-test")
+	}
+}
+
+func TestEnsureReturnsExisting(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	opts := core.CreateOptions{
+		Target: "feature-test",
+		Dir:    filepath.Join(repoDir, ".workspaces", "ensured"),
+	}
+
+	first, err := engine.Ensure(opts)
+	if err != nil {
+		t.Fatalf("Ensure() failed: %v", err)
+	}
+
+	if !first.Created {
+		t.Error("First Ensure() should create the workspace")
+	}
+
+	second, err := engine.Ensure(opts)
+	if err != nil {
+		t.Fatalf("Second Ensure() failed: %v", err)
+	}
+
+	if second.Created {
+		t.Error("Second Ensure() should return the existing workspace")
+	}
+
+	if second.Workspace.ID != first.Workspace.ID {
+		t.Errorf("Ensure() returned ID %q, want %q", second.Workspace.ID, first.Workspace.ID)
+	}
+
+	// A name already used by another branch is a conflict
+	_, err = engine.Ensure(core.CreateOptions{Target: "main", Name: first.Workspace.Name})
+	if err == nil {
+		t.Fatal("Ensure() should fail when the name belongs to another branch")
+	}
+
+	if coreErr, ok := err.(*core.Error); !ok || coreErr.Code != core.ErrConflict {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+}
+
+func TestEnsureConcurrent(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	const callers = 4
+
+	type outcome struct {
+		result core.EnsureResult
+		err    error
+	}
+	results := make(chan outcome, callers)
+
+	for i := 0; i < callers; i++ {
+		go func() {
+			// Separate engines behave like separate processes sharing the lock file
+			engine, err := core.NewEngine(repoDir)
+			if err != nil {
+				results <- outcome{err: err}
+				return
+			}
+			result, err := engine.Ensure(core.CreateOptions{
+				Target: "feature-test",
+				Dir:    filepath.Join(repoDir, ".workspaces", "shared"),
+			})
+			results <- outcome{result: result, err: err}
+		}()
+	}
+
+	created := 0
+	ids := make(map[string]bool)
+	for i := 0; i < callers; i++ {
+		o := <-results
+		if o.err != nil {
+			t.Fatalf("Ensure() failed: %v", o.err)
+		}
+		if o.result.Created {
+			created++
+		}
+		ids[o.result.Workspace.ID] = true
+	}
+
+	if created != 1 {
+		t.Errorf("Expected exactly 1 creation, got %d", created)
+	}
+
+	if len(ids) != 1 {
+		t.Errorf("Expected all callers to get the same workspace, got %d distinct IDs", len(ids))
+	}
+}
+
+func TestStatusReport(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	result, err := engine.Create(core.CreateOptions{
+		Target: "feature-test",
+		Name:   "dirty-ws",
+		Dir:    filepath.Join(repoDir, ".workspaces", "dirty-ws"),
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(result.Workspace.Path, "scratch.txt"), []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	report, err := engine.Status(core.StatusOptions{Selector: "dirty-ws"})
+	if err != nil {
+		t.Fatalf("Status() failed: %v", err)
+	}
+
+	if report.Total != 1 {
+		t.Errorf("Total = %d, want 1", report.Total)
+	}
+
+	if len(report.Dirty) != 1 || report.Dirty[0].Name != "dirty-ws" {
+		t.Errorf("Expected dirty-ws to be reported dirty, got %v", report.Dirty)
+	}
+
+	if _, err := engine.Status(core.StatusOptions{Selector: "no-such-ws"}); err == nil {
+		t.Error("Status() should fail for unknown selector")
+	}
+}
+
+func TestCleanupExecutesRemovals(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	wsDir := filepath.Join(repoDir, ".workspaces", "expired")
+	_, err = engine.Create(core.CreateOptions{
+		Target:    "feature-test",
+		Name:      "expired",
+		Dir:       wsDir,
+		Ephemeral: true,
+		TTL:       time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+
+	// Removal runs inside the cleanup lock and must not deadlock on it
+	plan, err := engine.Cleanup(core.CleanupOptions{Policy: "default"})
+	if err != nil {
+		t.Fatalf("Cleanup() failed: %v", err)
+	}
+
+	for _, w := range plan.Warnings {
+		if w.Code == "removal_failed" {
+			t.Errorf("Unexpected warning: %s", w.Message)
+		}
+	}
+	if len(plan.Actions) != 1 {
+		t.Fatalf("Expected 1 executed action, got %d", len(plan.Actions))
+	}
+
+	if _, err := os.Stat(wsDir); !os.IsNotExist(err) {
+		t.Error("Workspace directory should be removed")
+	}
+	if _, err := engine.Get(core.Selector{Type: core.SelectorName, Value: "expired"}); err == nil {
+		t.Error("Removed workspace should not be found")
+	}
+}
+
+func TestDoctorRepairsUnderLock(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	wsDir := filepath.Join(repoDir, ".workspaces", "orphan")
+	if _, err := engine.Create(core.CreateOptions{Target: "feature-test", Name: "orphan", Dir: wsDir}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if err := runCommand(repoDir, "git", "worktree", "remove", "--force", wsDir); err != nil {
+		t.Fatalf("git worktree remove failed: %v", err)
+	}
+
+	// Hold the engine lock from another handle; repairs must wait for it
+	lck, err := lock.NewManager().NewLock(filepath.Join(repoDir, ".git", "yagwt", "lock"))
+	if err != nil {
+		t.Fatalf("NewLock() failed: %v", err)
+	}
+	if err := lck.Acquire(time.Second); err != nil {
+		t.Fatalf("Acquire() failed: %v", err)
+	}
+
+	if _, err := engine.Doctor(core.DoctorOptions{ForgetMissing: true}); err == nil {
+		t.Error("Doctor() should time out while another process holds the lock")
+	}
+	if _, err := engine.Doctor(core.DoctorOptions{DryRun: true}); err != nil {
+		t.Errorf("Doctor() dry run should not need the lock: %v", err)
+	}
+
+	lck.Release()
+
+	report, err := engine.Doctor(core.DoctorOptions{ForgetMissing: true})
+	if err != nil {
+		t.Fatalf("Doctor() failed: %v", err)
+	}
+	if len(report.Repairs) == 0 || !report.Repairs[0].Applied {
+		t.Errorf("Expected an applied repair, got %+v", report.Repairs)
+	}
+}
+
+func TestDoctorMigratesMetadata(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	wsDir := filepath.Join(repoDir, ".workspaces", "old-schema")
+	if _, err := engine.Create(core.CreateOptions{Target: "feature-test", Name: "old-schema", Dir: wsDir}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	// Rewrite the file as schema v1
+	metaPath := filepath.Join(repoDir, ".git", "yagwt", "meta.json")
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := fmt.Sprintf(`"schemaVersion": %d`, metadata.CurrentSchemaVersion)
+	v1 := []byte(strings.Replace(string(data), current, `"schemaVersion": 1`, 1))
+	if err := os.WriteFile(metaPath, v1, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := engine.Doctor(core.DoctorOptions{Migrate: true})
+	if err != nil {
+		t.Fatalf("Doctor(migrate) failed: %v", err)
+	}
+	if report.Migration == nil || report.Migration.FromVersion != 1 || report.Repairs[0].Applied {
+		t.Fatalf("Doctor(migrate) = %+v", report)
+	}
+	if data, _ := os.ReadFile(metaPath); string(data) != string(v1) {
+		t.Error("Doctor(migrate) must not modify metadata")
+	}
+
+	report, err = engine.Doctor(core.DoctorOptions{})
+	if err != nil {
+		t.Fatalf("Doctor() failed: %v", err)
+	}
+	if len(report.Repairs) == 0 || !report.Repairs[0].Applied {
+		t.Fatalf("Expected an applied migration, got %+v", report.Repairs)
+	}
+	if backup, _ := os.ReadFile(report.Migration.BackupPath); string(backup) != string(v1) {
+		t.Error("Backup does not hold the pre-migration file")
+	}
+	if _, err := engine.Get(core.Selector{Type: core.SelectorName, Value: "old-schema"}); err != nil {
+		t.Errorf("Workspace lost in migration: %v", err)
+	}
+}
+
+func TestDoctorRebuildsDamagedMetadata(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	keepDir := filepath.Join(repoDir, ".workspaces", "keep")
+	if _, err := engine.Create(core.CreateOptions{Target: "feature-test", Name: "keep", Dir: keepDir}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if err := engine.Pin(core.Selector{Type: core.SelectorName, Value: "keep"}); err != nil {
+		t.Fatalf("Pin() failed: %v", err)
+	}
+	goneDir := filepath.Join(repoDir, ".workspaces", "gone")
+	if _, err := engine.Create(core.CreateOptions{Target: "HEAD", Name: "gone", Dir: goneDir, Detached: true}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if err := runCommand(repoDir, "git", "worktree", "remove", "--force", goneDir); err != nil {
+		t.Fatalf("git worktree remove failed: %v", err)
+	}
+
+	metaPath := filepath.Join(repoDir, ".git", "yagwt", "meta.json")
+	if err := os.WriteFile(metaPath, []byte("{\"schemaVersion\": 2, \"work"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := engine.Doctor(core.DoctorOptions{})
+	if err != nil {
+		t.Fatalf("Doctor() failed: %v", err)
+	}
+	if len(report.Repairs) == 0 || !report.Repairs[0].Applied {
+		t.Fatalf("Expected an applied rebuild, got %+v", report.Repairs)
+	}
+
+	ws, err := engine.Get(core.Selector{Type: core.SelectorName, Value: "keep"})
+	if err != nil || !ws.Flags.Pinned {
+		t.Errorf("Pinned workspace not recovered: %+v, %v", ws.Flags, err)
+	}
+	if _, err := engine.Get(core.Selector{Type: core.SelectorName, Value: "gone"}); err == nil {
+		t.Error("Workspace without a worktree should not be recovered")
+	}
+}
+
+func TestCreateFromLinkedWorktreeUsesMainRoot(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	writeRepoConfig(t, repoDir, `
+[workspace]
+rootStrategy = "inside"
+`)
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+	first, err := engine.Create(core.CreateOptions{Target: "feature-test", Dir: filepath.Join(t.TempDir(), "first")})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	// Config and derived paths come from the main worktree, not from the
+	// linked worktree yagwt runs in
+	inner, err := core.NewEngine(first.Workspace.Path)
+	if err != nil {
+		t.Fatalf("NewEngine() in linked worktree failed: %v", err)
+	}
+	second, err := inner.Create(core.CreateOptions{Target: "from-linked", NewBranch: true})
+	if err != nil {
+		t.Fatalf("Create() from linked worktree failed: %v", err)
+	}
+
+	want := filepath.Join(repoDir, ".workspaces", "from-linked")
+	if !strings.HasSuffix(second.Workspace.Path, want) {
+		t.Errorf("Path = %q, want %q", second.Workspace.Path, want)
+	}
+}
+
+func TestEngineInLinkedWorktreeSharesMetadata(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	wsDir := filepath.Join(repoDir, ".workspaces", "linked")
+	if _, err := engine.Create(core.CreateOptions{Target: "feature-test", Name: "linked", Dir: wsDir}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	// An engine opened inside the workspace uses the same store
+	inner, err := core.NewEngine(wsDir)
+	if err != nil {
+		t.Fatalf("NewEngine() in linked worktree failed: %v", err)
+	}
+	if _, err := inner.Get(core.Selector{Type: core.SelectorName, Value: "linked"}); err != nil {
+		t.Errorf("Workspace not visible from linked worktree: %v", err)
+	}
+
+	// Metadata an older version left in the worktree's git dir is merged
+	strayDir := filepath.Join(repoDir, ".git", "worktrees", "linked", "yagwt")
+	if err := os.MkdirAll(strayDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	stray := `{"schemaVersion": 1, "workspaces": {"stray-id": {"id": "stray-id", "name": "stray", "path": "/elsewhere/stray"}}}`
+	if err := os.WriteFile(filepath.Join(strayDir, "meta.json"), []byte(stray), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := inner.Doctor(core.DoctorOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Doctor() failed: %v", err)
+	}
+	found := false
+	for _, repair := range report.Repairs {
+		if strings.Contains(repair.Issue, strayDir) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Expected a repair for the stray metadata, got %+v", report.Repairs)
+	}
+
+	if _, err := inner.Doctor(core.DoctorOptions{}); err != nil {
+		t.Fatalf("Doctor() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(strayDir, "meta.json.merged")); err != nil {
+		t.Errorf("Stray metadata file not retired: %v", err)
+	}
+	store, _ := metadata.NewStore(filepath.Join(repoDir, ".git"))
+	if _, err := store.Get("stray-id"); err != nil {
+		t.Errorf("Stray workspace not merged: %v", err)
+	}
+}
+
+func TestBareRepositoryWorkspaces(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// The "bare repo + worktrees" layout: project/.bare plus checkouts
+	project := t.TempDir()
+	bareDir := filepath.Join(project, ".bare")
+	if err := runCommand(project, "git", "clone", "--bare", repoDir, bareDir); err != nil {
+		t.Fatalf("git clone --bare failed: %v", err)
+	}
+
+	engine, err := core.NewEngine(bareDir)
+	if err != nil {
+		t.Fatalf("NewEngine() on bare repository failed: %v", err)
+	}
+
+	workspaces, err := engine.List(core.ListOptions{})
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(workspaces) != 0 {
+		t.Errorf("Bare repository should have no workspaces, got %+v", workspaces)
+	}
+
+	result, err := engine.Create(core.CreateOptions{Target: "feature-test"})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if want := filepath.Join(project, "feature-test"); !strings.HasSuffix(result.Workspace.Path, want) {
+		t.Errorf("Path = %q, want a sibling of the bare dir %q", result.Workspace.Path, want)
+	}
+
+	// Neither engine marks the only checkout as primary
+	inner, err := core.NewEngine(result.Workspace.Path)
+	if err != nil {
+		t.Fatalf("NewEngine() in worktree failed: %v", err)
+	}
+	for _, e := range []core.WorkspaceManager{engine, inner} {
+		workspaces, err := e.List(core.ListOptions{})
+		if err != nil {
+			t.Fatalf("List() failed: %v", err)
+		}
+		if len(workspaces) != 1 || workspaces[0].IsPrimary || workspaces[0].Name != "feature-test" {
+			t.Errorf("List() = %+v, want one non-primary feature-test workspace", workspaces)
+		}
+	}
+}
+
+func TestDoctorDetectsRenamedAndDeletedBranches(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	for _, name := range []string{"renamed", "deleted"} {
+		opts := core.CreateOptions{Target: name, NewBranch: true, Name: name, Dir: filepath.Join(repoDir, ".workspaces", name)}
+		if _, err := engine.Create(opts); err != nil {
+			t.Fatalf("Create(%s) failed: %v", name, err)
+		}
+	}
+
+	// Branch selectors are answered from the index
+	ws, err := engine.Get(core.Selector{Type: core.SelectorBranch, Value: "deleted"})
+	if err != nil || ws.Name != "deleted" {
+		t.Fatalf("Get(branch:deleted) = %+v, %v", ws, err)
+	}
+	head := ws.Target.HeadSHA
+
+	if err := runCommand(repoDir, "git", "branch", "-m", "renamed", "renamed-2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runCommand(repoDir, "git", "update-ref", "-d", "refs/heads/deleted"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Listing must not overwrite the recorded branches
+	if _, err := engine.List(core.ListOptions{}); err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+
+	report, err := engine.Doctor(core.DoctorOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Doctor() failed: %v", err)
+	}
+	issues := []string{}
+	for _, repair := range report.Repairs {
+		issues = append(issues, repair.Issue)
+	}
+	joined := strings.Join(issues, "\n")
+	if !strings.Contains(joined, "'renamed' of workspace 'renamed' was renamed to 'renamed-2'") ||
+		!strings.Contains(joined, "'deleted' of workspace 'deleted' was deleted") {
+		t.Fatalf("Doctor() issues = %q", joined)
+	}
+
+	if _, err := engine.Doctor(core.DoctorOptions{}); err != nil {
+		t.Fatalf("Doctor() failed: %v", err)
+	}
+	if err := runCommand(repoDir, "git", "rev-parse", "--verify", "refs/heads/deleted"); err != nil {
+		t.Error("Deleted branch was not recreated")
+	}
+
+	store, _ := metadata.NewStore(filepath.Join(repoDir, ".git"))
+	found, _ := store.FindByBranch("renamed-2")
+	if len(found) != 1 || found[0].Name != "renamed" {
+		t.Errorf("FindByBranch(renamed-2) = %+v", found)
+	}
+	if found, _ := store.FindByBranch("deleted"); len(found) != 1 || found[0].HeadSHA != head {
+		t.Errorf("FindByBranch(deleted) = %+v, want HEAD %s", found, head)
+	}
+}
+
+func TestEphemeralLifecycle(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	result, err := engine.Create(core.CreateOptions{
+		Target: "feature-test",
+		Name:   "lifecycle",
+		Dir:    filepath.Join(repoDir, ".workspaces", "lifecycle"),
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	selector := core.Selector{Type: core.SelectorID, Value: result.Workspace.ID}
+
+	// Renewing a permanent workspace is refused
+	err = engine.Renew(selector, time.Hour)
+	if coreErr, ok := err.(*core.Error); !ok || coreErr.Code != core.ErrPolicy {
+		t.Errorf("Renew() on permanent workspace: expected ErrPolicy, got %v", err)
+	}
+
+	if err := engine.SetEphemeral(selector, core.EphemeralOptions{TTL: time.Hour}); err != nil {
+		t.Fatalf("SetEphemeral() failed: %v", err)
+	}
+
+	ws, err := engine.Get(selector)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if !ws.Flags.Ephemeral || ws.Ephemeral == nil {
+		t.Fatal("Workspace should be ephemeral")
+	}
+	if ws.Ephemeral.TTLSeconds != 3600 {
+		t.Errorf("TTLSeconds = %d, want 3600", ws.Ephemeral.TTLSeconds)
+	}
+
+	if err := engine.Renew(selector, 48*time.Hour); err != nil {
+		t.Fatalf("Renew() failed: %v", err)
+	}
+
+	ws, _ = engine.Get(selector)
+	expected := time.Now().Add(48 * time.Hour)
+	if ws.Ephemeral.ExpiresAt.Before(expected.Add(-time.Minute)) || ws.Ephemeral.ExpiresAt.After(expected.Add(time.Minute)) {
+		t.Errorf("ExpiresAt = %v, expected around %v", ws.Ephemeral.ExpiresAt, expected)
+	}
+
+	if err := engine.SetPermanent(selector); err != nil {
+		t.Fatalf("SetPermanent() failed: %v", err)
+	}
+
+	ws, _ = engine.Get(selector)
+	if ws.Flags.Ephemeral || ws.Ephemeral != nil {
+		t.Error("Workspace should be permanent")
+	}
+}
+
+func TestPinnedWorkspacesCannotBeEphemeral(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	result, err := engine.Create(core.CreateOptions{
+		Target: "feature-test",
+		Name:   "pinned",
+		Dir:    filepath.Join(repoDir, ".workspaces", "pinned"),
+		Pin:    true,
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	err = engine.SetEphemeral(core.Selector{Type: core.SelectorID, Value: result.Workspace.ID}, core.EphemeralOptions{})
+	if coreErr, ok := err.(*core.Error); !ok || coreErr.Code != core.ErrPolicy {
+		t.Errorf("Expected ErrPolicy for pinned workspace, got %v", err)
+	}
+
+	// Asking for both at creation is refused before anything is created
+	both := core.CreateOptions{
+		Target:    "HEAD",
+		Name:      "both",
+		Dir:       filepath.Join(repoDir, ".workspaces", "both"),
+		Pin:       true,
+		Ephemeral: true,
+	}
+	if _, err := engine.Create(both); err == nil {
+		t.Error("Create() accepted a pinned ephemeral workspace")
+	}
+	if _, err := engine.Ensure(both); err == nil {
+		t.Error("Ensure() accepted a pinned ephemeral workspace")
+	}
+	if _, err := os.Stat(both.Dir); !os.IsNotExist(err) {
+		t.Errorf("Worktree created for refused options: %v", err)
+	}
+
+	// Pinning an ephemeral workspace is refused the same way
+	result, err = engine.Create(core.CreateOptions{
+		Target:    "HEAD",
+		Name:      "scratch",
+		Dir:       filepath.Join(repoDir, ".workspaces", "scratch"),
+		Ephemeral: true,
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	err = engine.Pin(core.Selector{Type: core.SelectorID, Value: result.Workspace.ID})
+	if coreErr, ok := err.(*core.Error); !ok || coreErr.Code != core.ErrPolicy {
+		t.Errorf("Expected ErrPolicy for pinning an ephemeral workspace, got %v", err)
+	}
+}
+
+func TestTouchRecordsLastOpened(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	writeRepoConfig(t, repoDir, `
+[hooks]
+postOpen = "echo $YAGWT_OPERATION $YAGWT_WORKSPACE_NAME"
+`)
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	result, err := engine.Create(core.CreateOptions{
+		Target: "feature-test",
+		Name:   "opened",
+		Dir:    filepath.Join(repoDir, ".workspaces", "opened"),
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if result.Workspace.Activity.LastOpenedAt != nil {
+		t.Error("New workspace should not have LastOpenedAt")
+	}
+
+	selector := core.Selector{Type: core.SelectorName, Value: "opened"}
+	touched, err := engine.Touch(selector)
+	if err != nil {
+		t.Fatalf("Touch() failed: %v", err)
+	}
+	if len(touched.Hooks) != 1 || touched.Hooks[0].Stdout != "open opened\n" {
+		t.Errorf("Hooks = %+v, want post-open output %q", touched.Hooks, "open opened\n")
+	}
+
+	ws, err := engine.Get(selector)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if ws.Activity.LastOpenedAt == nil || time.Since(*ws.Activity.LastOpenedAt) > time.Minute {
+		t.Errorf("LastOpenedAt = %v, expected just now", ws.Activity.LastOpenedAt)
+	}
+}
+
+func TestListRefreshesGitActivity(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	wsDir := filepath.Join(repoDir, ".workspaces", "busy")
+	if _, err := engine.Create(core.CreateOptions{
+		Target: "feature-test",
+		Name:   "busy",
+		Dir:    wsDir,
+	}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	// Commit in the workspace with a timestamp later than its creation
+	commitAt := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	if err := os.WriteFile(filepath.Join(wsDir, "work.txt"), []byte("work\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	runCommand(wsDir, "git", "add", "work.txt")
+	cmd := exec.Command("git", "commit", "-m", "Work")
+	cmd.Dir = wsDir
+	cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+commitAt.Format(time.RFC3339))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit failed: %v\n%s", err, output)
+	}
+
+	ws, err := engine.Get(core.ParseSelector("name:busy"))
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if last := ws.Activity.LastGitActivityAt; last == nil || last.Before(commitAt) {
+		t.Errorf("LastGitActivityAt = %v, want at least %v", last, commitAt)
+	}
+
+	// The refreshed value is persisted by the read itself, so another
+	// process sees it, both through the store and through its own engine
+	store, err := metadata.NewStore(filepath.Join(repoDir, ".git"))
+	if err != nil {
+		t.Fatalf("NewStore() failed: %v", err)
+	}
+	meta, err := store.FindByName("busy")
+	if err != nil {
+		t.Fatalf("FindByName() failed: %v", err)
+	}
+	if last := meta.Activity.LastGitActivityAt; last == nil || last.Before(commitAt) {
+		t.Errorf("Stored LastGitActivityAt = %v, want at least %v", last, commitAt)
+	}
+
+	other, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+	ws, err = other.Locate(core.ParseSelector("name:busy"))
+	if err != nil {
+		t.Fatalf("Locate() failed: %v", err)
+	}
+	if last := ws.Activity.LastGitActivityAt; last == nil || last.Before(commitAt) {
+		t.Errorf("Second engine LastGitActivityAt = %v, want at least %v", last, commitAt)
+	}
+}
+
+func TestListParallelStatusOrder(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	writeRepoConfig(t, repoDir, "[list]\nstatusConcurrency = 2\n")
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	for _, name := range []string{"one", "two", "three", "four", "five"} {
+		if _, err := engine.Create(core.CreateOptions{
+			Target:    "ws-" + name,
+			NewBranch: true,
+			Name:      name,
+			Dir:       filepath.Join(repoDir, ".workspaces", name),
+		}); err != nil {
+			t.Fatalf("Create(%s) failed: %v", name, err)
+		}
+	}
+
+	// Make one workspace dirty so status results are distinguishable
+	dirtyFile := filepath.Join(repoDir, ".workspaces", "three", "dirty.txt")
+	if err := os.WriteFile(dirtyFile, []byte("dirty\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	first, err := engine.List(core.ListOptions{})
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(first) != 6 {
+		t.Fatalf("Expected 6 workspaces, got %d", len(first))
+	}
+
+	for run := 0; run < 5; run++ {
+		workspaces, err := engine.List(core.ListOptions{})
+		if err != nil {
+			t.Fatalf("List() failed: %v", err)
+		}
+		for i, ws := range workspaces {
+			if ws.Path != first[i].Path {
+				t.Fatalf("Run %d: workspace %d is %s, want %s", run, i, ws.Path, first[i].Path)
+			}
+			if !ws.IsPrimary && ws.Status.Dirty != (ws.Name == "three") {
+				t.Errorf("Run %d: %s dirty = %v", run, ws.Name, ws.Status.Dirty)
+			}
+		}
+	}
+}
+
+func TestListSkipStatus(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	wsDir := filepath.Join(repoDir, ".workspaces", "quick")
+	if _, err := engine.Create(core.CreateOptions{
+		Target: "feature-test",
+		Name:   "quick",
+		Dir:    wsDir,
+	}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(wsDir, "dirty.txt"), []byte("dirty\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	ws, err := engine.Locate(core.Selector{Type: core.SelectorName, Value: "quick"})
+	if err != nil {
+		t.Fatalf("Locate() failed: %v", err)
+	}
+	if ws.Target.Short != "feature-test" || filepath.Base(ws.Path) != "quick" {
+		t.Errorf("Locate() returned %s at %s", ws.Target.Short, ws.Path)
+	}
+	if ws.Status.Dirty {
+		t.Error("Locate() should not collect git status")
+	}
+
+	ws, err = engine.Get(core.Selector{Type: core.SelectorName, Value: "quick"})
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if !ws.Status.Dirty {
+		t.Error("Get() should report the workspace as dirty")
+	}
+}
+
+func TestListAppliesFilter(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	for _, name := range []string{"kept", "other"} {
+		if _, err := engine.Create(core.CreateOptions{
+			Target:    "filter-" + name,
+			NewBranch: true,
+			Name:      name,
+			Dir:       filepath.Join(repoDir, ".workspaces", name),
+			Pin:       name == "kept",
+		}); err != nil {
+			t.Fatalf("Create(%s) failed: %v", name, err)
+		}
+	}
+
+	workspaces, err := engine.List(core.ListOptions{Filter: "flag:pinned"})
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(workspaces) != 1 || workspaces[0].Name != "kept" {
+		t.Errorf("Expected only the pinned workspace, got %d workspaces", len(workspaces))
+	}
+
+	workspaces, err = engine.List(core.ListOptions{Filter: "name:other|flag:pinned", Fields: []string{"name"}})
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(workspaces) != 2 {
+		t.Errorf("Expected 2 workspaces, got %d", len(workspaces))
+	}
+}
+
+func TestLabelsAndFields(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	writeRepoConfig(t, repoDir, `
+[cleanup.policies.scratch]
+removeEphemeral = true
+match = "label:scratch"
+`)
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	for _, name := range []string{"tagged", "plain"} {
+		if _, err := engine.Create(core.CreateOptions{
+			Target:    "labels-" + name,
+			NewBranch: true,
+			Name:      name,
+			Dir:       filepath.Join(repoDir, ".workspaces", name),
+			Ephemeral: true,
+			TTL:       time.Second,
+		}); err != nil {
+			t.Fatalf("Create(%s) failed: %v", name, err)
+		}
+	}
+
+	tagged := core.ParseSelector("name:tagged")
+	if err := engine.AddLabels(tagged, []string{"scratch", "review", "scratch"}); err != nil {
+		t.Fatalf("AddLabels() failed: %v", err)
+	}
+	if err := engine.RemoveLabels(tagged, []string{"review"}); err != nil {
+		t.Fatalf("RemoveLabels() failed: %v", err)
+	}
+	if err := engine.AddLabels(tagged, []string{"-bad"}); err == nil {
+		t.Error("AddLabels() should reject an invalid label")
+	}
+
+	fields := map[string]interface{}{
+		"ticket":   core.ParseFieldValue("ABC-123"),
+		"estimate": core.ParseFieldValue("5"),
+		"build":    core.ParseFieldValue("007"),
+	}
+	if err := engine.SetFields(tagged, fields); err != nil {
+		t.Fatalf("SetFields() failed: %v", err)
+	}
+	if err := engine.UnsetFields(tagged, []string{"build"}); err != nil {
+		t.Fatalf("UnsetFields() failed: %v", err)
+	}
+
+	ws, err := engine.Get(tagged)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if len(ws.Labels) != 1 || ws.Labels[0] != "scratch" {
+		t.Errorf("Labels = %v, want [scratch]", ws.Labels)
+	}
+	if ws.Fields["estimate"] != 5.0 || ws.Fields["ticket"] != "ABC-123" {
+		t.Errorf("Fields = %v", ws.Fields)
+	}
+	if _, ok := ws.Fields["build"]; ok {
+		t.Error("build field should have been unset")
+	}
+
+	for _, expr := range []string{"label:scr*", "meta.ticket=ABC-123", "meta.estimate>3"} {
+		workspaces, err := engine.List(core.ListOptions{Filter: expr})
+		if err != nil {
+			t.Fatalf("List(%q) failed: %v", expr, err)
+		}
+		if len(workspaces) != 1 || workspaces[0].Name != "tagged" {
+			t.Errorf("List(%q) returned %d workspaces, want only tagged", expr, len(workspaces))
+		}
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+
+	plan, err := engine.Cleanup(core.CleanupOptions{Policy: "scratch", DryRun: true})
+	if err != nil {
+		t.Fatalf("Cleanup() failed: %v", err)
+	}
+	if len(plan.Actions) != 1 || plan.Actions[0].Workspace.Name != "tagged" {
+		t.Errorf("Expected only the labeled workspace in the plan, got %d actions", len(plan.Actions))
+	}
+}
+
+func TestCreateBootstrapsUntrackedFiles(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	writeRepoConfig(t, repoDir, `
+[workspace.bootstrap]
+copy = [".env*", ".idea"]
+symlink = ["certs"]
+hardlink = ["local.db"]
+`)
+
+	files := map[string]string{
+		".env":                "TOKEN=1\n",
+		".env.local":          "DEBUG=1\n",
+		".idea/workspace.xml": "<project/>\n",
+		"certs/dev.pem":       "cert\n",
+		"local.db":            "data\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repoDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	wsDir := filepath.Join(t.TempDir(), "boot")
+	result, err := engine.Create(core.CreateOptions{
+		Target: "feature-test",
+		Name:   "boot",
+		Dir:    wsDir,
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Unexpected warnings: %v", result.Warnings)
+	}
+	if len(result.Bootstrap) != 5 {
+		t.Fatalf("Expected 5 bootstrapped paths, got %+v", result.Bootstrap)
+	}
+
+	for _, name := range []string{".env", ".env.local", ".idea/workspace.xml", "local.db"} {
+		data, err := os.ReadFile(filepath.Join(wsDir, name))
+		if err != nil || string(data) != files[name] {
+			t.Errorf("%s = %q, %v; want %q", name, data, err, files[name])
+		}
+	}
+	if link, err := os.Readlink(filepath.Join(wsDir, "certs")); err != nil || link != filepath.Join(repoDir, "certs") {
+		t.Errorf("certs symlink = %q, %v", link, err)
+	}
+
+	// Existing files are kept unless forced
+	if err := os.WriteFile(filepath.Join(repoDir, ".env"), []byte("TOKEN=2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	selector := core.ParseSelector("name:boot")
+
+	rerun, err := engine.Bootstrap(selector, core.BootstrapOptions{})
+	if err != nil {
+		t.Fatalf("Bootstrap() failed: %v", err)
+	}
+	for _, file := range rerun.Files {
+		if !file.Skipped {
+			t.Errorf("%s should have been skipped", file.Path)
+		}
+	}
+
+	if _, err := engine.Bootstrap(selector, core.BootstrapOptions{Force: true}); err != nil {
+		t.Fatalf("Bootstrap(Force) failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(wsDir, ".env")); string(data) != "TOKEN=2\n" {
+		t.Errorf(".env = %q after forced bootstrap", data)
+	}
+
+	if _, err := engine.Bootstrap(core.ParseSelector("name:boot"), core.BootstrapOptions{From: "name:boot"}); err == nil {
+		t.Error("Bootstrap() should refuse to use a workspace as its own source")
+	}
+}
+
+func TestCreateSeedsBuildCaches(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	writeRepoConfig(t, repoDir, `
+[workspace.seed]
+dirs = ["node_modules", "target"]
+`)
+
+	cache := filepath.Join(repoDir, "node_modules", "left-pad", "index.js")
+	if err := os.MkdirAll(filepath.Dir(cache), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cache, []byte("module.exports = pad\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	// Seeding runs after the engine lock is released, so other writers
+	// are not kept waiting
+	writer, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	var progress []core.SeedProgress
+	wsDir := filepath.Join(t.TempDir(), "seeded")
+	result, err := engine.Create(core.CreateOptions{
+		Target: "feature-test",
+		Name:   "seeded",
+		Dir:    wsDir,
+		SeedProgress: func(p core.SeedProgress) {
+			progress = append(progress, p)
+			if err := writer.Pin(core.ParseSelector("name:seeded")); err != nil {
+				t.Errorf("Pin() during seeding failed: %v", err)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	if len(result.Seed) != 1 || result.Seed[0].Path != "node_modules" || result.Seed[0].Files != 1 {
+		t.Fatalf("Seed = %+v, want node_modules with 1 file", result.Seed)
+	}
+	if len(progress) != 1 || progress[0].Bytes != progress[0].Total {
+		t.Errorf("Progress = %+v", progress)
+	}
+	data, err := os.ReadFile(filepath.Join(wsDir, "node_modules", "left-pad", "index.js"))
+	if err != nil || string(data) != "module.exports = pad\n" {
+		t.Errorf("Seeded file = %q, %v", data, err)
+	}
+
+	ws, err := engine.Get(core.ParseSelector("name:seeded"))
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if ws.Seed == nil || ws.Seed.FromName != filepath.Base(repoDir) || ws.Seed.Bytes != result.Seed[0].Bytes {
+		t.Errorf("Seed metadata = %+v", ws.Seed)
+	}
+
+	// Seed another workspace from the first one
+	if _, err := engine.Create(core.CreateOptions{
+		Target:    "seed-other",
+		NewBranch: true,
+		Name:      "other",
+		Dir:       filepath.Join(t.TempDir(), "other"),
+	}); err != nil {
+		t.Fatalf("Create(other) failed: %v", err)
+	}
+
+	reseeded, err := engine.Seed(core.ParseSelector("name:other"), core.SeedOptions{From: "name:seeded", Force: true})
+	if err != nil {
+		t.Fatalf("Seed() failed: %v", err)
+	}
+	if reseeded.Source.Name != "seeded" || reseeded.Workspace.Seed == nil || reseeded.Workspace.Seed.FromName != "seeded" {
+		t.Errorf("Seed() result = %+v", reseeded)
+	}
+
+	skipped, err := engine.Seed(core.ParseSelector("name:other"), core.SeedOptions{From: "name:seeded"})
+	if err != nil {
+		t.Fatalf("Seed() failed: %v", err)
+	}
+	if len(skipped.Dirs) != 1 || !skipped.Dirs[0].Skipped {
+		t.Errorf("Expected existing node_modules to be skipped, got %+v", skipped.Dirs)
+	}
+
+	// Nothing is written outside the workspace, whether through the
+	// pattern or through a symlink in the destination
+	outside := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(wsDir, "vendor", "cache"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	otherDir := reseeded.Workspace.Path
+	if err := os.Symlink(outside, filepath.Join(otherDir, "vendor")); err != nil {
+		t.Fatal(err)
+	}
+
+	escaped, err := engine.Seed(core.ParseSelector("name:other"), core.SeedOptions{
+		From: "name:seeded",
+		Dirs: []string{"../*", "vendor/*"},
+	})
+	if err != nil {
+		t.Fatalf("Seed() failed: %v", err)
+	}
+	if len(escaped.Dirs) != 0 || len(escaped.Warnings) != 2 {
+		t.Errorf("Expected both patterns to be refused, got %+v and %+v", escaped.Dirs, escaped.Warnings)
+	}
+	if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+		t.Errorf("Seed wrote outside the workspace: %v", entries)
+	}
+}
+
+func TestCreateSparseWorkspace(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	for _, path := range []string{"apps/web/index.html", "apps/api/main.go", "libs/ui/button.js"} {
+		full := filepath.Join(repoDir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(path+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := runCommand(repoDir, "git", "add", "."); err != nil {
+		t.Fatal(err)
+	}
+	if err := runCommand(repoDir, "git", "commit", "-m", "Add monorepo layout"); err != nil {
+		t.Fatal(err)
+	}
+
+	writeRepoConfig(t, repoDir, `
+[sparse.profiles.frontend]
+patterns = ["apps/web"]
+`)
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	wsDir := filepath.Join(t.TempDir(), "ui")
+	result, err := engine.Create(core.CreateOptions{
+		Target:        "sparse-ui",
+		NewBranch:     true,
+		Name:          "ui",
+		Dir:           wsDir,
+		SparseProfile: "frontend",
+		Sparse:        []string{"libs/ui"},
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	sparse := result.Workspace.Sparse
+	if sparse == nil || sparse.Profile != "frontend" || !sparse.Cone || len(sparse.Patterns) != 2 {
+		t.Fatalf("Sparse = %+v, want frontend profile with 2 patterns", sparse)
+	}
+	exists := func(path string) bool {
+		_, err := os.Stat(filepath.Join(wsDir, path))
+		return err == nil
+	}
+	if !exists("apps/web/index.html") || !exists("libs/ui/button.js") || exists("apps/api/main.go") {
+		t.Error("Sparse workspace has the wrong files")
+	}
+	if result.Workspace.Status.Dirty {
+		t.Error("Sparse workspace should be clean")
+	}
+
+	selector := core.ParseSelector("name:ui")
+	info, err := engine.AddSparsePatterns(selector, []string{"apps/api"})
+	if err != nil {
+		t.Fatalf("AddSparsePatterns() failed: %v", err)
+	}
+	if len(info.Patterns) != 3 || !exists("apps/api/main.go") {
+		t.Errorf("After add: %+v", info)
+	}
+
+	if _, err := engine.RemoveSparsePatterns(selector, []string{"apps/web"}); err != nil {
+		t.Fatalf("RemoveSparsePatterns() failed: %v", err)
+	}
+	if exists("apps/web/index.html") {
+		t.Error("apps/web should be gone after remove")
+	}
+
+	listed, err := engine.SparsePatterns(selector)
+	if err != nil {
+		t.Fatalf("SparsePatterns() failed: %v", err)
+	}
+	if listed == nil || len(listed.Patterns) != 2 || listed.Profile != "frontend" {
+		t.Errorf("SparsePatterns() = %+v", listed)
+	}
+
+	ws, err := engine.Get(selector)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if ws.Sparse == nil || len(ws.Sparse.Patterns) != 2 {
+		t.Errorf("Metadata not updated: %+v", ws.Sparse)
+	}
+
+	// Unknown profiles are rejected before anything is created
+	_, err = engine.Create(core.CreateOptions{Target: "feature-test", SparseProfile: "backend", Dir: filepath.Join(t.TempDir(), "x")})
+	if coreErr, ok := err.(*core.Error); !ok || coreErr.Code != core.ErrConfig {
+		t.Errorf("Expected ErrConfig for an unknown profile, got %v", err)
+	}
+
+	// Full checkouts report no sparse setup and cannot have patterns removed
+	primary := core.ParseSelector(repoDir)
+	if info, err := engine.SparsePatterns(primary); err != nil || info != nil {
+		t.Errorf("SparsePatterns(primary) = %+v, %v", info, err)
+	}
+	if _, err := engine.RemoveSparsePatterns(primary, []string{"apps"}); err == nil {
+		t.Error("RemoveSparsePatterns() should fail on a full checkout")
+	}
+}
+
+func TestCreateRollsBackNewBranch(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	// A directory in place of the temporary file makes saving the metadata
+	// fail after the worktree and its new branch exist
+	tmpPath := filepath.Join(repoDir, ".git", "yagwt", "meta.json.tmp")
+	if err := os.MkdirAll(tmpPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	wsDir := filepath.Join(t.TempDir(), "rollback")
+	opts := core.CreateOptions{Target: "rollback-branch", NewBranch: true, Dir: wsDir}
+	if _, err := engine.Create(opts); err == nil {
+		t.Fatal("Create() succeeded although the metadata could not be saved")
+	}
+	if _, err := os.Stat(wsDir); !os.IsNotExist(err) {
+		t.Errorf("Worktree left behind after failed create: %v", err)
+	}
+	if err := runCommand(repoDir, "git", "rev-parse", "--verify", "refs/heads/rollback-branch"); err == nil {
+		t.Error("New branch left behind after failed create")
+	}
+
+	if _, err := engine.Create(opts); err != nil {
+		t.Fatalf("Retried Create() failed: %v", err)
+	}
+}
+
+func TestCreateNoCheckout(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	wsDir := filepath.Join(t.TempDir(), "empty")
+	if _, err := engine.Create(core.CreateOptions{Target: "feature-test", Dir: wsDir, NoCheckout: true}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(wsDir, "README.md")); !os.IsNotExist(err) {
+		t.Error("NoCheckout workspace should have no files")
+	}
+}
+
+func TestCreateUpdatesSubmodules(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+	libDir, libCleanup := setupTestRepo(t)
+	defer libCleanup()
+
+	// Local submodule URLs need file transport, which git disables by default
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	if err := runCommand(repoDir, "git", "submodule", "add", libDir, "libs/shared"); err != nil {
+		t.Fatalf("Failed to add submodule: %v", err)
+	}
+	if err := runCommand(repoDir, "git", "commit", "-m", "Add submodule"); err != nil {
+		t.Fatalf("Failed to commit submodule: %v", err)
+	}
+
+	writeRepoConfig(t, repoDir, `
+[workspace.submodules]
+update = true
+recursive = true
+`)
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	wsDir := filepath.Join(t.TempDir(), "with-subs")
+	result, err := engine.Create(core.CreateOptions{Target: "with-subs", NewBranch: true, Name: "with-subs", Dir: wsDir})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if len(result.Warnings) > 0 {
+		t.Errorf("Unexpected warnings: %+v", result.Warnings)
+	}
+
+	readme := filepath.Join(wsDir, "libs", "shared", "README.md")
+	if _, err := os.Stat(readme); err != nil {
+		t.Fatalf("Submodule was not checked out: %v", err)
+	}
+
+	// A change inside the submodule is reported separately
+	if err := os.WriteFile(readme, []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ws, err := engine.Get(core.ParseSelector("name:with-subs"))
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	status := ws.Status
+	if !status.Dirty || !status.SubmoduleOnly {
+		t.Errorf("Status = %+v, want dirty from submodules only", status)
+	}
+	if len(status.Submodules) != 1 || status.Submodules[0].Path != "libs/shared" || !status.Submodules[0].Modified {
+		t.Errorf("Submodules = %+v", status.Submodules)
+	}
+
+	// The primary worktree is dirty from the untracked config file
+	names := func(filter string) []string {
+		workspaces, err := engine.List(core.ListOptions{Filter: "name:with-subs " + filter})
+		if err != nil {
+			t.Fatalf("List(%q) failed: %v", filter, err)
+		}
+		var names []string
+		for _, ws := range workspaces {
+			names = append(names, ws.Name)
+		}
+		return names
+	}
+	if got := names("status:dirty"); len(got) != 1 || got[0] != "with-subs" {
+		t.Errorf("status:dirty = %v, want [with-subs]", got)
+	}
+	if got := names("status:dirty-ignore-submodules"); len(got) != 0 {
+		t.Errorf("status:dirty-ignore-submodules = %v, want none", got)
+	}
+
+	// File changes in the workspace itself still count
+	if err := os.WriteFile(filepath.Join(wsDir, "notes.txt"), []byte("todo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := names("status:dirty-ignore-submodules"); len(got) != 1 {
+		t.Errorf("status:dirty-ignore-submodules = %v, want [with-subs]", got)
+	}
+
+	// Submodule git dirs live in the worktree's admin directory, which the
+	// trash does not keep, so trashing is refused and nothing moves
+	_, err = engine.Remove(core.ParseSelector("name:with-subs"), core.RemoveOptions{Trash: true})
+	if err == nil {
+		t.Fatal("Remove(Trash) succeeded for a workspace with submodules")
+	}
+	if yerr, ok := err.(*core.Error); !ok || yerr.Code != core.ErrConflict {
+		t.Errorf("Remove(Trash) error = %v, want ErrConflict", err)
+	}
+	if _, err := os.Stat(readme); err != nil {
+		t.Errorf("Workspace files moved after refused trash: %v", err)
+	}
+	if _, err := engine.Get(core.ParseSelector("name:with-subs")); err != nil {
+		t.Errorf("Workspace lost after refused trash: %v", err)
+	}
+}
+
+func TestCreateLFSPullFailureIsWarning(t *testing.T) {
+	if _, err := exec.LookPath("git-lfs"); err == nil {
+		t.Skip("git-lfs is installed")
+	}
+
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	writeRepoConfig(t, repoDir, `
+[workspace.lfs]
+include = ["assets/**"]
+`)
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	result, err := engine.Create(core.CreateOptions{Target: "feature-test", Dir: filepath.Join(t.TempDir(), "lfs")})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Code != "lfs_failed" {
+		t.Errorf("Warnings = %+v, want one lfs_failed", result.Warnings)
+	}
+}
+
+func TestListInvalidFilterAndFields(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	for _, opts := range []core.ListOptions{
+		{Filter: "flag:shiny"},
+		{Filter: "nonsense"},
+		{Fields: []string{"name", "colour"}},
+	} {
+		_, err := engine.List(opts)
+		coreErr, ok := err.(*core.Error)
+		if !ok || coreErr.Code != core.ErrConfig {
+			t.Errorf("List(%+v): expected ErrConfig, got %v", opts, err)
+		}
+	}
+}
+
+func TestUndoRemoveRestoresWorkspace(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	wsDir := filepath.Join(repoDir, ".workspaces", "undo-me")
+	result, err := engine.Create(core.CreateOptions{Target: "feature-test", Name: "undo-me", Dir: wsDir})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	selector := core.Selector{Type: core.SelectorID, Value: result.Workspace.ID}
+
+	readme := filepath.Join(wsDir, "README.md")
+	if err := os.WriteFile(readme, []byte("# Unsaved work\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = engine.Remove(selector, core.RemoveOptions{OnDirty: "stash", DeleteBranch: true})
+	if err != nil {
+		t.Fatalf("Remove() failed: %v", err)
+	}
+
+	entries, err := engine.Log(core.LogOptions{})
+	if err != nil {
+		t.Fatalf("Log() failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 journal entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Operation != "remove" || entry.StashRef == "" || !entry.DeletedBranch || entry.Previous == nil {
+		t.Fatalf("Unexpected journal entry: %+v", entry)
+	}
+
+	// A failed metadata restore rolls the checkout back, so undo can be retried
+	store, _ := metadata.NewStore(filepath.Join(repoDir, ".git"))
+	squatter := metadata.WorkspaceMetadata{ID: entry.Previous.ID, Name: "squatter", Path: t.TempDir()}
+	if err := store.Set(squatter.ID, squatter); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.Undo(0); err == nil {
+		t.Fatal("Undo() succeeded with conflicting metadata")
+	}
+	if _, err := os.Stat(wsDir); !os.IsNotExist(err) {
+		t.Errorf("Worktree left behind after failed undo: %v", err)
+	}
+	if err := runCommand(repoDir, "git", "rev-parse", "--verify", "refs/heads/feature-test"); err == nil {
+		t.Error("Recreated branch left behind after failed undo")
+	}
+	if err := store.Delete(squatter.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	undo, err := engine.Undo(0)
+	if err != nil {
+		t.Fatalf("Undo() failed: %v", err)
+	}
+	for _, w := range undo.Warnings {
+		t.Errorf("Unexpected warning: %s", w.Message)
+	}
+
+	ws, err := engine.Get(selector)
+	if err != nil {
+		t.Fatalf("Restored workspace not found by ID: %v", err)
+	}
+	if ws.Name != "undo-me" || ws.Target.Short != "feature-test" {
+		t.Errorf("Restored workspace = %s on %s", ws.Name, ws.Target.Short)
+	}
+	content, _ := os.ReadFile(readme)
+	if string(content) != "# Unsaved work\n" {
+		t.Errorf("Stashed changes not reapplied, README.md = %q", content)
+	}
+
+	// The entry is now marked undone and cannot be undone twice
+	entries, _ = engine.Log(core.LogOptions{})
+	if len(entries) != 2 || entries[1].UndoneBy != entries[0].Seq {
+		t.Errorf("Expected the remove entry to be undone by the newest entry, got %+v", entries)
+	}
+	_, err = engine.Undo(entry.Seq)
+	if coreErr, ok := err.(*core.Error); !ok || coreErr.Code != core.ErrConflict {
+		t.Errorf("Second Undo() = %v, want ErrConflict", err)
+	}
+}
+
+func TestRemovePatchFilesDoNotCollide(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	// Two workspaces with the same name, removed one after the other, must
+	// leave separate patches so undoing the first applies its own changes
+	patchDir := t.TempDir()
+	wsDir := filepath.Join(repoDir, ".workspaces", "scratch")
+	for _, content := range []string{"# First\n", "# Second\n"} {
+		result, err := engine.Create(core.CreateOptions{Target: "HEAD", Name: "scratch", Dir: wsDir})
+		if err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(wsDir, "README.md"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		selector := core.Selector{Type: core.SelectorID, Value: result.Workspace.ID}
+		if _, err := engine.Remove(selector, core.RemoveOptions{OnDirty: "patch", PatchDir: patchDir}); err != nil {
+			t.Fatalf("Remove() failed: %v", err)
+		}
+	}
+
+	entries, err := engine.Log(core.LogOptions{})
+	if err != nil {
+		t.Fatalf("Log() failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 journal entries, got %d", len(entries))
+	}
+	// Log lists the newest entry first
+	first, second := entries[1], entries[0]
+	if first.PatchFile == "" || first.PatchFile == second.PatchFile {
+		t.Fatalf("Expected distinct patch files, got %q and %q", first.PatchFile, second.PatchFile)
+	}
+	patch, err := os.ReadFile(first.PatchFile)
+	if err != nil {
+		t.Fatalf("First patch file missing: %v", err)
+	}
+	if !strings.Contains(string(patch), "+# First") {
+		t.Errorf("First patch was overwritten:\n%s", patch)
+	}
+}
+
+func TestUndoRename(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	result, err := engine.Create(core.CreateOptions{
+		Target: "feature-test",
+		Name:   "before",
+		Dir:    filepath.Join(repoDir, ".workspaces", "before"),
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	selector := core.Selector{Type: core.SelectorID, Value: result.Workspace.ID}
+
+	if err := engine.Rename(selector, "after"); err != nil {
+		t.Fatalf("Rename() failed: %v", err)
+	}
+
+	entries, _ := engine.Log(core.LogOptions{Limit: 1})
+	if len(entries) != 1 || entries[0].Operation != "rename" || entries[0].NewName != "after" {
+		t.Fatalf("Unexpected journal entries: %+v", entries)
+	}
+
+	if _, err := engine.Undo(entries[0].Seq); err != nil {
+		t.Fatalf("Undo() failed: %v", err)
+	}
+
+	ws, _ := engine.Get(selector)
+	if ws.Name != "before" {
+		t.Errorf("Name = %q after undo, want %q", ws.Name, "before")
+	}
+
+	// Nothing is left to undo
+	_, err = engine.Undo(0)
+	if coreErr, ok := err.(*core.Error); !ok || coreErr.Code != core.ErrNotFound {
+		t.Errorf("Undo() with empty backlog = %v, want ErrNotFound", err)
+	}
+}
+
+func TestTrashRestoreAndEmpty(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	engine, err := core.NewEngine(repoDir)
+	if err != nil {
+		t.Fatalf("NewEngine() failed: %v", err)
+	}
+
+	wsDir := filepath.Join(repoDir, ".workspaces", "trash-me")
+	result, err := engine.Create(core.CreateOptions{Target: "feature-test", Name: "trash-me", Dir: wsDir})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	selector := core.Selector{Type: core.SelectorID, Value: result.Workspace.ID}
+
+	// Untracked files survive the trash without an on-dirty strategy
+	scratch := filepath.Join(wsDir, "scratch.txt")
+	if err := os.WriteFile(scratch, []byte("notes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := engine.Remove(selector, core.RemoveOptions{Trash: true, DeleteBranch: true})
+	if err != nil {
+		t.Fatalf("Remove() failed: %v", err)
+	}
+	if !removed.Trashed || removed.DeletedBranch != "feature-test" {
+		t.Errorf("Remove() = trashed %v, deleted branch %q", removed.Trashed, removed.DeletedBranch)
+	}
+	if _, err := os.Stat(wsDir); !os.IsNotExist(err) {
+		t.Errorf("Workspace directory still exists after trashing")
+	}
+
+	// Trashed workspaces are hidden from List and listed by ListTrash
+	workspaces, _ := engine.List(core.ListOptions{})
+	for _, ws := range workspaces {
+		if ws.ID == result.Workspace.ID {
+			t.Errorf("Trashed workspace still listed")
+		}
+	}
+	trashed, err := engine.ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash() failed: %v", err)
+	}
+	if len(trashed) != 1 || trashed[0].Name != "trash-me" || trashed[0].Trash == nil {
+		t.Fatalf("ListTrash() = %+v", trashed)
+	}
+
+	restored, err := engine.Restore(core.Selector{Type: core.SelectorName, Value: "trash-me"})
+	if err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+	if restored.ID != result.Workspace.ID || restored.Target.Short != "feature-test" {
+		t.Errorf("Restore() = %s on %s", restored.ID, restored.Target.Short)
+	}
+	content, _ := os.ReadFile(scratch)
+	if string(content) != "notes\n" {
+		t.Errorf("Untracked file not restored, scratch.txt = %q", content)
+	}
+
+	// Trash again, then purge everything
+	if _, err := engine.Remove(selector, core.RemoveOptions{Trash: true}); err != nil {
+		t.Fatalf("Remove() failed: %v", err)
+	}
+	plan, err := engine.EmptyTrash(core.EmptyTrashOptions{DryRun: true})
+	if err != nil || len(plan.Purged) != 1 {
+		t.Fatalf("EmptyTrash(dry-run) = %+v, %v", plan, err)
+	}
+	emptied, err := engine.EmptyTrash(core.EmptyTrashOptions{})
+	if err != nil {
+		t.Fatalf("EmptyTrash() failed: %v", err)
+	}
+	if len(emptied.Purged) != 1 || len(emptied.Warnings) != 0 {
+		t.Errorf("EmptyTrash() = %+v", emptied)
+	}
+	if trashed, _ := engine.ListTrash(); len(trashed) != 0 {
+		t.Errorf("Trash not empty after EmptyTrash(): %+v", trashed)
+	}
+}
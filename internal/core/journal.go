@@ -0,0 +1,256 @@
+package core
+
+import (
+	"os"
+	"time"
+
+	"github.com/bmf/yagwt/internal/git"
+	"github.com/bmf/yagwt/internal/journal"
+	"github.com/bmf/yagwt/internal/metadata"
+)
+
+// appendJournal records entry, turning a failure into a warning: the
+// operation itself has already happened and must not be reported as failed
+func (e *engine) appendJournal(entry journal.Entry, warnings *[]Warning) {
+	if _, err := e.journal.Append(entry); err != nil && warnings != nil {
+		*warnings = append(*warnings, Warning{
+			Code:    "journal_failed",
+			Message: "Failed to record '" + entry.Operation + "' in the journal: " + err.Error(),
+		})
+	}
+}
+
+// Log returns journal entries, newest first
+func (e *engine) Log(opts LogOptions) ([]JournalEntry, error) {
+	entries, err := e.journal.List()
+	if err != nil {
+		return nil, err
+	}
+
+	newest := make([]JournalEntry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		newest = append(newest, entries[i])
+		if opts.Limit > 0 && len(newest) == opts.Limit {
+			break
+		}
+	}
+
+	return newest, nil
+}
+
+// Undo reverses a journal entry, or the newest undoable one when seq is 0
+func (e *engine) Undo(seq int) (result UndoResult, err error) {
+	err = e.withLock(writeLockTimeout, func() error {
+		result, err = e.undo(seq)
+		return err
+	})
+	return result, err
+}
+
+// undo implements Undo; the caller must hold the engine lock
+func (e *engine) undo(seq int) (UndoResult, error) {
+	entry, err := e.undoTarget(seq)
+	if err != nil {
+		return UndoResult{}, err
+	}
+
+	result := UndoResult{Entry: entry}
+
+	switch entry.Operation {
+	case journal.OpRemove, journal.OpCleanup:
+		err = e.undoRemove(entry, &result)
+	case journal.OpRename:
+		err = e.undoRename(entry)
+	case journal.OpForgetMissing:
+		err = e.restoreMetadata(entry)
+	default:
+		err = NewError(ErrPolicy, "operation cannot be undone").
+			WithDetail("entry", entry.Seq).
+			WithDetail("operation", entry.Operation)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	e.appendJournal(journal.Entry{
+		Operation:   journal.OpUndo,
+		WorkspaceID: entry.WorkspaceID,
+		Name:        entry.Name,
+		Path:        entry.Path,
+		Undoes:      entry.Seq,
+	}, &result.Warnings)
+
+	// Untracked worktrees come back without metadata, so look up by path
+	if ws, err := e.Locate(Selector{Type: SelectorPath, Value: entry.Path}); err == nil {
+		result.Workspace = ws
+	}
+
+	return result, nil
+}
+
+// undoTarget returns the entry to undo: seq itself, or the newest entry
+// that has not been undone when seq is 0
+func (e *engine) undoTarget(seq int) (JournalEntry, error) {
+	if seq == 0 {
+		entries, err := e.journal.List()
+		if err != nil {
+			return JournalEntry{}, err
+		}
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].Undoable() {
+				return entries[i], nil
+			}
+		}
+		return JournalEntry{}, NewError(ErrNotFound, "nothing to undo").
+			WithHint("List journal entries", "yagwt log")
+	}
+
+	entry, err := e.journal.Get(seq)
+	if err != nil {
+		return JournalEntry{}, err
+	}
+
+	if entry.Operation == journal.OpUndo {
+		return entry, NewError(ErrPolicy, "undo entries cannot be undone").
+			WithDetail("entry", seq)
+	}
+	if entry.UndoneBy != 0 {
+		return entry, NewError(ErrConflict, "journal entry was already undone").
+			WithDetail("entry", seq).
+			WithDetail("undoneBy", entry.UndoneBy)
+	}
+
+	return entry, nil
+}
+
+// undoRemove recreates a removed worktree at its old path, restores its
+// metadata and reapplies changes saved by the on-dirty strategy
+func (e *engine) undoRemove(entry JournalEntry, result *UndoResult) error {
+	// Trashed workspaces still have their files, so just restore them
+	if entry.TrashRef != "" {
+		_, err := e.restore(trashedID(entry.TrashRef))
+		return err
+	}
+
+	if entry.HeadSHA == "" {
+		return NewError(ErrBroken, "journal entry has no commit to restore").
+			WithDetail("entry", entry.Seq)
+	}
+
+	if _, err := os.Stat(entry.Path); err == nil {
+		return NewError(ErrConflict, "target path already exists").
+			WithDetail("path", entry.Path).
+			WithHint("Move or delete the directory, then retry", "")
+	}
+
+	if entry.Previous != nil {
+		if err := e.checkNameAvailable(entry.Previous.Name, false); err != nil {
+			return err
+		}
+	}
+
+	// Check out the branch again, recreating it at the old HEAD if it was
+	// deleted with the workspace
+	ref, addOpts := entry.HeadSHA, git.AddOptions{Detach: true}
+	if entry.Branch != "" {
+		ref, addOpts = entry.Branch, git.AddOptions{}
+		if _, err := e.repo.ResolveRef("refs/heads/" + entry.Branch); err != nil {
+			addOpts = git.AddOptions{NewBranch: true, Base: entry.HeadSHA}
+		}
+	}
+	if err := e.repo.AddWorktree(entry.Path, ref, addOpts); err != nil {
+		return err
+	}
+
+	if entry.Previous != nil {
+		if err := e.restoreMetadata(entry); err != nil {
+			// Undo the checkout too, so the entry can be retried
+			_ = e.repo.RemoveWorktree(entry.Path, true)
+			if addOpts.NewBranch {
+				_ = e.repo.DeleteBranch(entry.Branch, true)
+			}
+			return err
+		}
+	}
+
+	if err := e.reapplyChanges(entry); err != nil {
+		result.Warnings = append(result.Warnings, Warning{
+			Code:    "restore_failed",
+			Message: "Worktree restored but saved changes were not reapplied: " + err.Error(),
+		})
+	}
+
+	return nil
+}
+
+// reapplyChanges restores uncommitted changes saved when the workspace
+// was removed
+func (e *engine) reapplyChanges(entry JournalEntry) error {
+	switch {
+	case entry.StashRef != "":
+		return e.repo.ApplyStash(entry.Path, entry.StashRef)
+
+	case entry.PatchFile != "":
+		return e.repo.ApplyPatch(entry.Path, entry.PatchFile)
+
+	case entry.WIPCommit != "":
+		// Only unwind the WIP commit if nothing was committed on top of it
+		head := entry.HeadSHA
+		if entry.Branch != "" {
+			var err error
+			if head, err = e.repo.ResolveRef("refs/heads/" + entry.Branch); err != nil {
+				return err
+			}
+		}
+		if head != entry.WIPCommit {
+			return NewError(ErrConflict, "branch has moved past the WIP commit").
+				WithDetail("branch", entry.Branch).
+				WithDetail("wipCommit", entry.WIPCommit)
+		}
+		return e.repo.ResetMixed(entry.Path, entry.WIPCommit+"^")
+	}
+
+	return nil
+}
+
+// undoRename gives a renamed workspace its previous name back
+func (e *engine) undoRename(entry JournalEntry) error {
+	return e.store.Update(func(m *metadata.Metadata) error {
+		meta, ok := m.Workspaces[entry.WorkspaceID]
+		if !ok {
+			return NewError(ErrNotFound, "workspace not found").
+				WithDetail("id", entry.WorkspaceID)
+		}
+
+		if id, taken := m.Index.ByName[entry.Name]; taken && id != entry.WorkspaceID {
+			return NewError(ErrConflict, "workspace name already in use").
+				WithDetail("name", entry.Name).
+				WithDetail("existingId", id)
+		}
+
+		meta.Name = entry.Name
+		meta.UpdatedAt = time.Now()
+		m.SetWorkspace(meta)
+		return nil
+	})
+}
+
+// restoreMetadata puts a journaled workspace's previous metadata back
+func (e *engine) restoreMetadata(entry JournalEntry) error {
+	if entry.Previous == nil {
+		return NewError(ErrBroken, "journal entry has no metadata to restore").
+			WithDetail("entry", entry.Seq)
+	}
+
+	return e.store.Update(func(m *metadata.Metadata) error {
+		if _, exists := m.Workspaces[entry.Previous.ID]; exists {
+			return NewError(ErrConflict, "workspace metadata already exists").
+				WithDetail("id", entry.Previous.ID)
+		}
+
+		meta := *entry.Previous
+		meta.UpdatedAt = time.Now()
+		m.SetWorkspace(meta)
+		return nil
+	})
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/bmf/yagwt/internal/metadata"
+)
+
+var (
+	labelPattern    = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_./-]*$`)
+	fieldKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+)
+
+// ParseFieldValue converts a command-line value into a custom field value:
+// true/false become booleans, plain numbers become float64 and everything
+// else stays a string
+func ParseFieldValue(s string) interface{} {
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	// Only treat canonical numbers as numeric so "007" or "1e3" keep their text
+	if n, err := strconv.ParseFloat(s, 64); err == nil && strconv.FormatFloat(n, 'f', -1, 64) == s {
+		return n
+	}
+	return s
+}
+
+// AddLabels adds labels to a workspace, ignoring ones it already has
+func (e *engine) AddLabels(selector Selector, labels []string) error {
+	for _, label := range labels {
+		if !labelPattern.MatchString(label) {
+			return NewError(ErrConfig, "invalid label").
+				WithDetail("label", label).
+				WithHint("Labels start with a letter or digit and may contain letters, digits, '_', '.', '/' and '-'", "")
+		}
+	}
+
+	return e.updateMetadata(selector, func(ws Workspace, meta *metadata.WorkspaceMetadata) error {
+		set := make(map[string]bool, len(meta.Labels)+len(labels))
+		for _, label := range meta.Labels {
+			set[label] = true
+		}
+		for _, label := range labels {
+			set[label] = true
+		}
+		meta.Labels = sortedKeys(set)
+		return nil
+	})
+}
+
+// RemoveLabels removes labels from a workspace; missing labels are ignored
+func (e *engine) RemoveLabels(selector Selector, labels []string) error {
+	return e.updateMetadata(selector, func(ws Workspace, meta *metadata.WorkspaceMetadata) error {
+		set := make(map[string]bool, len(meta.Labels))
+		for _, label := range meta.Labels {
+			set[label] = true
+		}
+		for _, label := range labels {
+			delete(set, label)
+		}
+		meta.Labels = sortedKeys(set)
+		return nil
+	})
+}
+
+// SetFields sets custom fields on a workspace, replacing existing values
+func (e *engine) SetFields(selector Selector, fields map[string]interface{}) error {
+	for key, value := range fields {
+		if !fieldKeyPattern.MatchString(key) {
+			return NewError(ErrConfig, "invalid field name").
+				WithDetail("field", key).
+				WithHint("Field names may contain letters, digits, '_', '.' and '-'", "")
+		}
+		switch value.(type) {
+		case string, float64, bool:
+		default:
+			return NewError(ErrConfig, "unsupported field value").
+				WithDetail("field", key).
+				WithDetail("value", value)
+		}
+	}
+
+	return e.updateMetadata(selector, func(ws Workspace, meta *metadata.WorkspaceMetadata) error {
+		if meta.Fields == nil {
+			meta.Fields = make(map[string]interface{}, len(fields))
+		}
+		for key, value := range fields {
+			meta.Fields[key] = value
+		}
+		return nil
+	})
+}
+
+// UnsetFields removes custom fields from a workspace
+func (e *engine) UnsetFields(selector Selector, keys []string) error {
+	return e.updateMetadata(selector, func(ws Workspace, meta *metadata.WorkspaceMetadata) error {
+		for _, key := range keys {
+			delete(meta.Fields, key)
+		}
+		if len(meta.Fields) == 0 {
+			meta.Fields = nil
+		}
+		return nil
+	})
+}
+
+// sortedKeys returns the keys of set in order, or nil when it is empty
+func sortedKeys(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"github.com/bmf/yagwt/internal/hooks"
+	"github.com/bmf/yagwt/internal/journal"
+	"github.com/bmf/yagwt/internal/metadata"
+)
+
+// HookResult describes a lifecycle hook that ran during an operation
+type HookResult = hooks.Result
+
+// JournalEntry records a destructive operation and how to reverse it
+type JournalEntry = journal.Entry
+
+// MigrationPlan describes an upgrade of the metadata file to the current schema
+type MigrationPlan = metadata.MigrationPlan
+
+// CreateResult describes the outcome of creating a workspace
+type CreateResult struct {
+	Workspace Workspace
+	Bootstrap []BootstrapFile // files brought in by [workspace.bootstrap]
+	Seed      []SeededDir     // directories cloned by [workspace.seed]
+	Hooks     []HookResult
+	Warnings  []Warning
+}
+
+// TouchResult describes the outcome of recording that a workspace was opened
+type TouchResult struct {
+	Workspace Workspace
+	Hooks     []HookResult
+	Warnings  []Warning
+}
+
+// EnsureResult describes the outcome of ensuring a workspace exists.
+// Bootstrap, Seed, Hooks and Warnings are only set when the workspace was
+// created.
+type EnsureResult struct {
+	Workspace Workspace
+	Created   bool
+	Bootstrap []BootstrapFile
+	Seed      []SeededDir
+	Hooks     []HookResult
+	Warnings  []Warning
+}
+
+// RemoveResult describes the outcome of removing a workspace
+type RemoveResult struct {
+	Workspace     Workspace
+	DeletedBranch string // empty unless the branch was deleted
+	Trashed       bool   // moved to the trash instead of deleted
+	Hooks         []HookResult
+	Warnings      []Warning
+}
+
+// EmptyTrashResult describes the workspaces purged from the trash
+type EmptyTrashResult struct {
+	Purged   []Workspace
+	Warnings []Warning
+}
+
+// UndoResult describes the outcome of reversing a journal entry
+type UndoResult struct {
+	Entry     JournalEntry // the entry that was undone
+	Workspace Workspace    // the restored workspace, when it could be located
+	Warnings  []Warning
+}
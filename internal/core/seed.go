@@ -0,0 +1,179 @@
+package core
+
+import (
+	"os"
+	"time"
+
+	"github.com/bmf/yagwt/internal/metadata"
+	"github.com/bmf/yagwt/internal/seed"
+)
+
+// SeedOptions specifies parameters for seeding a workspace
+type SeedOptions struct {
+	From     string   // selector of the source workspace; overrides workspace.seed.from
+	Dirs     []string // globs to seed; overrides workspace.seed.dirs
+	Force    bool     // replace directories that already exist in the workspace
+	Progress func(SeedProgress)
+}
+
+// SeedProgress reports how far seeding one directory has got
+type SeedProgress struct {
+	Dir   string // directory being seeded, relative to the workspace root
+	Bytes int64  // bytes of Dir done so far
+	Total int64  // bytes in Dir
+}
+
+// SeededDir describes one directory cloned into a workspace
+type SeededDir struct {
+	Path    string // relative to the workspace root
+	Files   int
+	Bytes   int64
+	Method  string // reflink, hardlink or copy (mostly)
+	Skipped bool   // already present in the workspace, left untouched
+}
+
+// SeedResult describes the outcome of seeding a workspace
+type SeedResult struct {
+	Workspace Workspace
+	Source    Workspace
+	Dirs      []SeededDir
+	Bytes     int64
+	Warnings  []Warning
+}
+
+// Seed clones build caches and dependency directories from another
+// workspace, using reflinks where the filesystem supports them. Like the
+// seeding in Create it runs without the engine lock, since copies can take
+// minutes; it only writes inside the workspace and through the store.
+func (e *engine) Seed(selector Selector, opts SeedOptions) (SeedResult, error) {
+	ws, err := e.Locate(selector)
+	if err != nil {
+		return SeedResult{}, err
+	}
+
+	if len(opts.Dirs) == 0 && len(e.config.Workspace.Seed.Dirs) == 0 {
+		return SeedResult{}, NewError(ErrConfig, "no directories to seed").
+			WithHint("Set workspace.seed.dirs in config or pass --dir", "yagwt seed "+ws.Name+" --dir node_modules")
+	}
+
+	return e.seed(ws, opts)
+}
+
+// seed implements Seed and records the source in metadata. It does not
+// need the engine lock.
+func (e *engine) seed(ws Workspace, opts SeedOptions) (SeedResult, error) {
+	result := SeedResult{Workspace: ws}
+
+	cfg := e.config.Workspace.Seed
+	patterns := opts.Dirs
+	if len(patterns) == 0 {
+		patterns = cfg.Dirs
+	}
+	if len(patterns) == 0 {
+		return result, nil
+	}
+
+	from := opts.From
+	if from == "" {
+		from = cfg.From
+	}
+	source, err := e.sourceWorkspace(from)
+	if err != nil {
+		return result, err
+	}
+	if normalizePath(source.Path) == normalizePath(ws.Path) {
+		return result, NewError(ErrConfig, "cannot seed a workspace from itself").
+			WithDetail("name", ws.Name).
+			WithHint("Pick another source workspace", "yagwt seed "+ws.Name+" --from <selector>")
+	}
+	result.Source = source
+
+	var total seed.Stats
+	var seeded []string
+
+	paths, warnings := matchWorkspacePaths("seed", source.Path, ws.Path, patterns, opts.Force, make(map[string]bool))
+	result.Warnings = append(result.Warnings, warnings...)
+
+	for _, p := range paths {
+		rel := p.Rel
+		dir := SeededDir{Path: rel, Skipped: p.Skipped}
+		if p.Skipped {
+			result.Dirs = append(result.Dirs, dir)
+			continue
+		}
+
+		seedOpts := seed.Options{Fallback: cfg.Fallback}
+		if opts.Progress != nil {
+			seedOpts.Progress = func(progress seed.Progress) {
+				opts.Progress(SeedProgress{Dir: rel, Bytes: progress.Bytes, Total: progress.Total})
+			}
+		}
+
+		stats, err := seed.Tree(p.Src, p.Dst, seedOpts)
+		if err != nil {
+			// Leave nothing half-seeded behind
+			_ = os.RemoveAll(p.Dst)
+			result.Warnings = append(result.Warnings, pathWarning("seed", rel, err))
+			continue
+		}
+
+		dir.Files = stats.Files
+		dir.Bytes = stats.Bytes
+		dir.Method = stats.Method()
+		result.Dirs = append(result.Dirs, dir)
+		result.Bytes += stats.Bytes
+
+		total.Files += stats.Files
+		total.Bytes += stats.Bytes
+		total.Reflinked += stats.Reflinked
+		total.Hardlinked += stats.Hardlinked
+		total.Copied += stats.Copied
+		seeded = append(seeded, rel)
+	}
+
+	if len(seeded) == 0 {
+		return result, nil
+	}
+
+	record := &metadata.SeedMetadata{
+		FromID:   source.ID,
+		FromName: source.Name,
+		Dirs:     seeded,
+		Bytes:    total.Bytes,
+		Method:   total.Method(),
+		SeededAt: time.Now(),
+	}
+	err = e.store.Update(func(m *metadata.Metadata) error {
+		meta, ok := m.Workspaces[ws.ID]
+		if !ok {
+			return nil
+		}
+		meta.Seed = record
+		m.SetWorkspace(meta)
+		return nil
+	})
+	if err != nil {
+		result.Warnings = append(result.Warnings, Warning{
+			Code:    "seed_failed",
+			Message: "Failed to record the seed source: " + err.Error(),
+		})
+	}
+	result.Workspace.Seed = seedInfo(metadata.WorkspaceMetadata{Seed: record})
+
+	return result, nil
+}
+
+// seedInfo converts recorded seed metadata, if any
+func seedInfo(meta metadata.WorkspaceMetadata) *SeedInfo {
+	if meta.Seed == nil {
+		return nil
+	}
+	return &SeedInfo{
+		FromID:   meta.Seed.FromID,
+		FromName: meta.Seed.FromName,
+		Dirs:     meta.Seed.Dirs,
+		Bytes:    meta.Seed.Bytes,
+		Method:   meta.Seed.Method,
+		SeededAt: meta.Seed.SeededAt,
+	}
+}
@@ -0,0 +1,158 @@
+package core
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/bmf/yagwt/internal/metadata"
+)
+
+// sparseSpec returns the sparse-checkout setup requested by opts, or nil
+// for a full checkout
+func (e *engine) sparseSpec(opts CreateOptions) (*metadata.SparseMetadata, error) {
+	if opts.SparseProfile == "" && len(opts.Sparse) == 0 {
+		return nil, nil
+	}
+
+	spec := &metadata.SparseMetadata{Cone: !opts.NoCone}
+	if opts.SparseProfile != "" {
+		profile, ok := e.config.Sparse.Profiles[opts.SparseProfile]
+		if !ok {
+			names := make([]string, 0, len(e.config.Sparse.Profiles))
+			for name := range e.config.Sparse.Profiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			return nil, NewError(ErrConfig, "unknown sparse profile").
+				WithDetail("profile", opts.SparseProfile).
+				WithDetail("available", strings.Join(names, ", ")).
+				WithHint("Define it under [sparse.profiles."+opts.SparseProfile+"] in .yagwt/config.toml", "")
+		}
+		spec.Profile = opts.SparseProfile
+		spec.Patterns = append(spec.Patterns, profile.Patterns...)
+		spec.Cone = profile.IsCone() && !opts.NoCone
+	}
+	spec.Patterns = mergePatterns(spec.Patterns, opts.Sparse)
+
+	return spec, nil
+}
+
+// applySparse sets the sparse-checkout patterns of the worktree at path and,
+// if populate is set, checks out the files they select
+func (e *engine) applySparse(path string, spec *metadata.SparseMetadata, populate bool) error {
+	if err := e.repo.SparseCheckoutSet(path, spec.Patterns, spec.Cone); err != nil {
+		return err
+	}
+	if !populate {
+		return nil
+	}
+	return e.repo.PopulateWorktree(path)
+}
+
+// SparsePatterns returns the sparse-checkout setup of a workspace as git
+// reports it, or nil if the workspace is a full checkout
+func (e *engine) SparsePatterns(selector Selector) (*SparseInfo, error) {
+	ws, err := e.Locate(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns, err := e.repo.SparseCheckoutList(ws.Path)
+	if err != nil || patterns == nil {
+		return nil, err
+	}
+
+	info := &SparseInfo{Patterns: patterns, Cone: true}
+	if ws.Sparse != nil {
+		info.Profile = ws.Sparse.Profile
+		info.Cone = ws.Sparse.Cone
+	}
+	return info, nil
+}
+
+// AddSparsePatterns adds patterns to a workspace's sparse checkout, making a
+// full checkout sparse (in cone mode) if needed
+func (e *engine) AddSparsePatterns(selector Selector, patterns []string) (*SparseInfo, error) {
+	return e.updateSparse(selector, patterns, false)
+}
+
+// RemoveSparsePatterns removes patterns from a workspace's sparse checkout
+func (e *engine) RemoveSparsePatterns(selector Selector, patterns []string) (*SparseInfo, error) {
+	return e.updateSparse(selector, patterns, true)
+}
+
+// updateSparse adds or removes sparse-checkout patterns of a workspace and
+// records the result in metadata
+func (e *engine) updateSparse(selector Selector, patterns []string, remove bool) (*SparseInfo, error) {
+	var info *SparseInfo
+
+	err := e.updateMetadata(selector, func(ws Workspace, meta *metadata.WorkspaceMetadata) error {
+		current, err := e.repo.SparseCheckoutList(ws.Path)
+		if err != nil {
+			return err
+		}
+
+		spec := metadata.SparseMetadata{Cone: true}
+		if meta.Sparse != nil {
+			spec = *meta.Sparse
+		}
+
+		if remove {
+			if current == nil {
+				return NewError(ErrConfig, "workspace is not sparse").
+					WithDetail("name", ws.Name).
+					WithHint("Add patterns to make it sparse", "yagwt sparse "+ws.Name+" add <pattern>...")
+			}
+			drop := make(map[string]bool, len(patterns))
+			for _, p := range patterns {
+				drop[p] = true
+			}
+			spec.Patterns = nil
+			for _, p := range current {
+				if !drop[p] {
+					spec.Patterns = append(spec.Patterns, p)
+				}
+			}
+		} else {
+			spec.Patterns = mergePatterns(current, patterns)
+		}
+
+		if err := e.applySparse(ws.Path, &spec, false); err != nil {
+			return err
+		}
+
+		meta.Sparse = &spec
+		info = sparseInfo(*meta)
+		return nil
+	})
+
+	return info, err
+}
+
+// mergePatterns appends the patterns in add that are not already in base
+func mergePatterns(base, add []string) []string {
+	seen := make(map[string]bool, len(base))
+	for _, p := range base {
+		seen[p] = true
+	}
+	for _, p := range add {
+		if !seen[p] {
+			seen[p] = true
+			base = append(base, p)
+		}
+	}
+	return base
+}
+
+// sparseInfo converts recorded sparse-checkout metadata, if any
+func sparseInfo(meta metadata.WorkspaceMetadata) *SparseInfo {
+	if meta.Sparse == nil {
+		return nil
+	}
+	return &SparseInfo{
+		Profile:  meta.Sparse.Profile,
+		Patterns: meta.Sparse.Patterns,
+		Cone:     meta.Sparse.Cone,
+	}
+}
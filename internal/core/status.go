@@ -0,0 +1,115 @@
+package core
+
+import (
+	"time"
+
+	"github.com/bmf/yagwt/internal/cleanup"
+)
+
+// Lifecycle states reported by the status dashboard
+const (
+	StateActive    = "active"
+	StateIdle      = "idle"
+	StatePinned    = "pinned"
+	StateEphemeral = "ephemeral"
+	StateLocked    = "locked"
+	StateBroken    = "broken"
+)
+
+// LifecycleStates lists all lifecycle states in display order
+var LifecycleStates = []string{StateActive, StateIdle, StatePinned, StateEphemeral, StateLocked, StateBroken}
+
+// defaultIdleThreshold is used when the default cleanup policy has none
+const defaultIdleThreshold = 30 * 24 * time.Hour
+
+// Status builds a summary of all (or selected) workspaces
+func (e *engine) Status(opts StatusOptions) (StatusReport, error) {
+	var workspaces []Workspace
+	var err error
+	if opts.Selector != "" {
+		workspaces, err = e.Resolve(opts.Selector)
+		if err != nil {
+			return StatusReport{}, err
+		}
+		if len(workspaces) == 0 {
+			return StatusReport{}, NewError(ErrNotFound, "workspace not found").
+				WithDetail("selector", opts.Selector)
+		}
+	} else {
+		workspaces, err = e.List(ListOptions{})
+		if err != nil {
+			return StatusReport{}, err
+		}
+	}
+
+	policy, err := cleanup.GetPolicy("default", e.config.Cleanup.Policies)
+	if err != nil {
+		return StatusReport{}, err
+	}
+
+	idleThreshold := time.Duration(e.config.Cleanup.Policies["default"].IdleThreshold)
+	if idleThreshold <= 0 {
+		idleThreshold = defaultIdleThreshold
+	}
+
+	window := opts.ExpiringWithin
+	if window <= 0 {
+		window = 24 * time.Hour
+	}
+
+	report := buildStatusReport(workspaces, idleThreshold, window, time.Now())
+	report.CleanupCandidates, _ = generateCleanupPlan(workspaces, policy)
+
+	return report, nil
+}
+
+// buildStatusReport aggregates workspace state into a StatusReport
+func buildStatusReport(workspaces []Workspace, idleThreshold, window time.Duration, now time.Time) StatusReport {
+	report := StatusReport{
+		Total:  len(workspaces),
+		States: make(map[string]int),
+	}
+
+	for _, ws := range workspaces {
+		report.States[lifecycleState(ws, idleThreshold, now)]++
+
+		report.Ahead += ws.Status.Ahead
+		report.Behind += ws.Status.Behind
+
+		if ws.Status.Dirty {
+			report.Dirty = append(report.Dirty, ws)
+		}
+		if ws.Status.Conflicts {
+			report.Conflicted = append(report.Conflicted, ws)
+		}
+		if ws.Flags.Broken {
+			report.Broken = append(report.Broken, ws)
+		}
+		if ws.Flags.Ephemeral && ws.Ephemeral != nil && ws.Ephemeral.ExpiresAt.Before(now.Add(window)) {
+			report.ExpiringSoon = append(report.ExpiringSoon, ws)
+		}
+	}
+
+	return report
+}
+
+// lifecycleState classifies a workspace into a single lifecycle state,
+// preferring the most restrictive one
+func lifecycleState(ws Workspace, idleThreshold time.Duration, now time.Time) string {
+	switch {
+	case ws.Flags.Broken:
+		return StateBroken
+	case ws.Flags.Locked:
+		return StateLocked
+	case ws.Flags.Pinned:
+		return StatePinned
+	case ws.Flags.Ephemeral:
+		return StateEphemeral
+	}
+
+	if last := ws.Activity.LastActiveAt(); last != nil && now.Sub(*last) > idleThreshold {
+		return StateIdle
+	}
+
+	return StateActive
+}
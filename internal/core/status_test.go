@@ -0,0 +1,118 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLifecycleState(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-time.Hour)
+	old := now.Add(-60 * 24 * time.Hour)
+
+	tests := []struct {
+		name string
+		ws   Workspace
+		want string
+	}{
+		{
+			name: "recent activity is active",
+			ws:   Workspace{Activity: ActivityInfo{LastGitActivityAt: &recent}},
+			want: StateActive,
+		},
+		{
+			name: "no activity is active",
+			ws:   Workspace{},
+			want: StateActive,
+		},
+		{
+			name: "old activity is idle",
+			ws:   Workspace{Activity: ActivityInfo{LastGitActivityAt: &old}},
+			want: StateIdle,
+		},
+		{
+			name: "recent open overrides old git activity",
+			ws:   Workspace{Activity: ActivityInfo{LastGitActivityAt: &old, LastOpenedAt: &recent}},
+			want: StateActive,
+		},
+		{
+			name: "broken wins over everything",
+			ws:   Workspace{Flags: WorkspaceFlags{Broken: true, Locked: true, Pinned: true}},
+			want: StateBroken,
+		},
+		{
+			name: "locked wins over pinned",
+			ws:   Workspace{Flags: WorkspaceFlags{Locked: true, Pinned: true}},
+			want: StateLocked,
+		},
+		{
+			name: "pinned idle workspace is pinned",
+			ws:   Workspace{Flags: WorkspaceFlags{Pinned: true}, Activity: ActivityInfo{LastGitActivityAt: &old}},
+			want: StatePinned,
+		},
+		{
+			name: "ephemeral",
+			ws:   Workspace{Flags: WorkspaceFlags{Ephemeral: true}},
+			want: StateEphemeral,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lifecycleState(tt.ws, 30*24*time.Hour, now); got != tt.want {
+				t.Errorf("lifecycleState() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildStatusReport(t *testing.T) {
+	now := time.Now()
+
+	workspaces := []Workspace{
+		{Name: "clean"},
+		{Name: "dirty", Status: StatusInfo{Dirty: true, Ahead: 2}},
+		{Name: "conflicted", Status: StatusInfo{Dirty: true, Conflicts: true, Behind: 5}},
+		{
+			Name:      "expiring",
+			Flags:     WorkspaceFlags{Ephemeral: true},
+			Ephemeral: &EphemeralInfo{ExpiresAt: now.Add(2 * time.Hour)},
+		},
+		{
+			Name:      "not-expiring",
+			Flags:     WorkspaceFlags{Ephemeral: true},
+			Ephemeral: &EphemeralInfo{ExpiresAt: now.Add(7 * 24 * time.Hour)},
+		},
+		{Name: "broken", Flags: WorkspaceFlags{Broken: true}},
+	}
+
+	report := buildStatusReport(workspaces, 30*24*time.Hour, 24*time.Hour, now)
+
+	if report.Total != 6 {
+		t.Errorf("Total = %d, want 6", report.Total)
+	}
+
+	if report.States[StateActive] != 3 || report.States[StateEphemeral] != 2 || report.States[StateBroken] != 1 {
+		t.Errorf("States = %v", report.States)
+	}
+
+	if report.Ahead != 2 || report.Behind != 5 {
+		t.Errorf("Ahead/Behind = %d/%d, want 2/5", report.Ahead, report.Behind)
+	}
+
+	if len(report.Dirty) != 2 {
+		t.Errorf("Dirty = %d, want 2", len(report.Dirty))
+	}
+
+	if len(report.Conflicted) != 1 || report.Conflicted[0].Name != "conflicted" {
+		t.Errorf("Conflicted = %v", report.Conflicted)
+	}
+
+	if len(report.ExpiringSoon) != 1 || report.ExpiringSoon[0].Name != "expiring" {
+		t.Errorf("ExpiringSoon = %v", report.ExpiringSoon)
+	}
+
+	if len(report.Broken) != 1 {
+		t.Errorf("Broken = %d, want 1", len(report.Broken))
+	}
+}
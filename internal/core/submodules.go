@@ -0,0 +1,55 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/bmf/yagwt/internal/git"
+)
+
+// checkoutExtras updates submodules and pulls Git LFS files in a new
+// worktree as configured under [workspace.submodules] and [workspace.lfs],
+// returning warnings for whatever failed
+func (e *engine) checkoutExtras(path string) []Warning {
+	var warnings []Warning
+
+	cfg := e.config.Workspace
+	if cfg.Submodules.Update {
+		if _, err := os.Stat(filepath.Join(path, ".gitmodules")); err == nil {
+			if err := e.repo.UpdateSubmodules(path, cfg.Submodules.Recursive); err != nil {
+				warnings = append(warnings, Warning{
+					Code:    "submodule_failed",
+					Message: "Submodule update failed: " + err.Error(),
+				})
+			}
+		}
+	}
+
+	if len(cfg.LFS.Include) > 0 {
+		if err := e.repo.LFSPull(path, cfg.LFS.Include); err != nil {
+			warnings = append(warnings, Warning{
+				Code:    "lfs_failed",
+				Message: "Git LFS pull failed: " + err.Error(),
+			})
+		}
+	}
+
+	return warnings
+}
+
+// submoduleStatuses converts git's per-submodule status
+func submoduleStatuses(subs []git.SubmoduleStatus) []SubmoduleStatus {
+	if len(subs) == 0 {
+		return nil
+	}
+	statuses := make([]SubmoduleStatus, len(subs))
+	for i, sub := range subs {
+		statuses[i] = SubmoduleStatus{
+			Path:          sub.Path,
+			CommitChanged: sub.CommitChanged,
+			Modified:      sub.Modified,
+			Untracked:     sub.Untracked,
+		}
+	}
+	return statuses
+}
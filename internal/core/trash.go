@@ -0,0 +1,321 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bmf/yagwt/internal/git"
+	"github.com/bmf/yagwt/internal/journal"
+	"github.com/bmf/yagwt/internal/metadata"
+	"github.com/google/uuid"
+)
+
+// trashRefPrefix namespaces the refs that keep trashed commits reachable
+const trashRefPrefix = "refs/yagwt/trash/"
+
+// trashDir returns the directory trashed workspaces are moved into
+func (e *engine) trashDir() string {
+	dir := e.config.Trash.Dir
+	if dir == "" {
+		return filepath.Join(e.repo.CommonDir(), "yagwt", "trash")
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(e.repo.Root(), dir)
+	}
+	return dir
+}
+
+// trashWorkspace moves ws into the trash instead of deleting it. Its
+// metadata is kept with a trashed state and its HEAD is pinned by a trash
+// ref, so the branch can be deleted safely. It returns the ref.
+func (e *engine) trashWorkspace(ws Workspace) (string, error) {
+	now := time.Now()
+
+	meta, err := e.store.Get(ws.ID)
+	if err != nil {
+		if yerr, ok := err.(*Error); !ok || yerr.Code != ErrNotFound {
+			return "", err
+		}
+		// Untracked worktrees get a record so they can be restored
+		meta = metadata.WorkspaceMetadata{
+			ID:        uuid.New().String(),
+			Name:      ws.Name,
+			Path:      ws.Path,
+			Flags:     make(map[string]bool),
+			CreatedAt: now,
+		}
+	}
+
+	ref := trashRefPrefix + meta.ID
+	if err := e.repo.UpdateRef(ref, ws.Target.HeadSHA); err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(e.trashDir(), meta.ID)
+	if err := e.repo.DetachWorktree(ws.Path, dir); err != nil {
+		e.repo.DeleteRef(ref)
+		if yerr, ok := err.(*Error); ok {
+			if yerr.Code == ErrConflict {
+				yerr.WithHint("Remove it without the trash", "yagwt rm path:"+ws.Path+" --no-trash")
+			} else {
+				yerr.WithHint("Set trash.dir to a directory on the same filesystem as the workspace", "")
+			}
+		}
+		return "", err
+	}
+
+	meta.Trash = &metadata.TrashMetadata{
+		TrashedAt: now,
+		Dir:       dir,
+		Ref:       ref,
+		HeadSHA:   ws.Target.HeadSHA,
+	}
+	if ws.Target.Type == "branch" {
+		meta.Trash.Branch = ws.Target.Short
+	}
+	meta.UpdatedAt = now
+
+	err = e.store.Update(func(m *metadata.Metadata) error {
+		m.SetWorkspace(meta)
+		return nil
+	})
+	if err != nil {
+		// Without metadata the trashed files could not be restored, so
+		// put the worktree back where it was
+		target, addOpts := ws.Target.HeadSHA, git.AddOptions{Detach: true}
+		if meta.Trash.Branch != "" {
+			target, addOpts = meta.Trash.Branch, git.AddOptions{}
+		}
+		if attachErr := e.repo.AttachWorktree(dir, ws.Path, target, addOpts); attachErr == nil {
+			e.repo.DeleteRef(meta.Trash.Ref)
+		}
+		return "", err
+	}
+
+	return ref, nil
+}
+
+// ListTrash returns trashed workspaces, most recently trashed first
+func (e *engine) ListTrash() ([]Workspace, error) {
+	meta, err := e.store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	trashed := []Workspace{}
+	for _, wsMeta := range meta.Workspaces {
+		if wsMeta.Trash != nil {
+			trashed = append(trashed, trashedWorkspace(wsMeta))
+		}
+	}
+
+	sort.Slice(trashed, func(i, j int) bool {
+		return trashed[i].Trash.TrashedAt.After(trashed[j].Trash.TrashedAt)
+	})
+
+	return trashed, nil
+}
+
+// trashedWorkspace builds a Workspace from trashed metadata. Path is where
+// the workspace lived and will be restored to.
+func trashedWorkspace(meta metadata.WorkspaceMetadata) Workspace {
+	target := Target{Type: "commit", Ref: meta.Trash.HeadSHA, HeadSHA: meta.Trash.HeadSHA}
+	if meta.Trash.Branch != "" {
+		target.Type = "branch"
+		target.Ref = "refs/heads/" + meta.Trash.Branch
+		target.Short = meta.Trash.Branch
+	} else if len(meta.Trash.HeadSHA) >= 7 {
+		target.Short = meta.Trash.HeadSHA[:7]
+	}
+
+	return Workspace{
+		ID:     meta.ID,
+		Name:   meta.Name,
+		Path:   meta.Path,
+		Target: target,
+		Flags: WorkspaceFlags{
+			Pinned:    meta.Flags["pinned"],
+			Ephemeral: meta.Flags["ephemeral"],
+			Locked:    meta.Flags["locked"],
+		},
+		Ephemeral: ephemeralInfo(meta),
+		Activity: ActivityInfo{
+			LastOpenedAt:      meta.Activity.LastOpenedAt,
+			LastGitActivityAt: meta.Activity.LastGitActivityAt,
+		},
+		CreatedAt: createdAt(meta),
+		Labels:    meta.Labels,
+		Fields:    meta.Fields,
+		Seed:      seedInfo(meta),
+		Sparse:    sparseInfo(meta),
+		Trash: &TrashInfo{
+			TrashedAt: meta.Trash.TrashedAt,
+			Dir:       meta.Trash.Dir,
+		},
+	}
+}
+
+// Restore moves a trashed workspace back to its original path
+func (e *engine) Restore(selector Selector) (ws Workspace, err error) {
+	err = e.withLock(writeLockTimeout, func() error {
+		trashed, err := e.ListTrash()
+		if err != nil {
+			return err
+		}
+
+		matches := matchSelector(trashed, selector)
+		if len(matches) == 0 {
+			return NewError(ErrNotFound, "workspace not found in trash").
+				WithDetail("selector", selectorToString(selector)).
+				WithHint("List trashed workspaces", "yagwt trash ls")
+		}
+		if len(matches) > 1 {
+			return NewError(ErrAmbiguous, "selector matches multiple trashed workspaces").
+				WithDetail("selector", selectorToString(selector)).
+				WithDetail("count", len(matches)).
+				WithHint("Use the workspace ID", "yagwt trash ls")
+		}
+
+		ws, err = e.restore(matches[0].ID)
+		return err
+	})
+	return ws, err
+}
+
+// restore implements Restore; the caller must hold the engine lock
+func (e *engine) restore(id string) (Workspace, error) {
+	meta, err := e.store.Get(id)
+	if err != nil {
+		return Workspace{}, err
+	}
+	trash := meta.Trash
+	if trash == nil {
+		return Workspace{}, NewError(ErrConflict, "workspace is not in the trash").
+			WithDetail("id", id)
+	}
+
+	if _, err := os.Stat(meta.Path); err == nil {
+		return Workspace{}, NewError(ErrConflict, "target path already exists").
+			WithDetail("path", meta.Path).
+			WithHint("Move or delete the directory, then retry", "")
+	}
+	if _, err := os.Stat(trash.Dir); err != nil {
+		return Workspace{}, WrapError(ErrBroken, "trashed files are missing", err).
+			WithDetail("id", id).
+			WithDetail("dir", trash.Dir).
+			WithHint("Purge the entry from the trash", "yagwt trash empty")
+	}
+	if err := e.checkNameAvailable(meta.Name, false); err != nil {
+		return Workspace{}, err
+	}
+
+	// Check out the branch again, recreating it from the trash ref if it
+	// was deleted after the workspace was trashed
+	ref, addOpts := trash.HeadSHA, git.AddOptions{Detach: true}
+	if trash.Branch != "" {
+		ref, addOpts = trash.Branch, git.AddOptions{}
+		if _, err := e.repo.ResolveRef("refs/heads/" + trash.Branch); err != nil {
+			addOpts = git.AddOptions{NewBranch: true, Base: trash.Ref}
+		}
+	}
+	if err := e.repo.AttachWorktree(trash.Dir, meta.Path, ref, addOpts); err != nil {
+		return Workspace{}, err
+	}
+
+	meta.Trash = nil
+	meta.UpdatedAt = time.Now()
+	err = e.store.Update(func(m *metadata.Metadata) error {
+		m.SetWorkspace(meta)
+		return nil
+	})
+	if err != nil {
+		return Workspace{}, err
+	}
+
+	// A leftover ref only keeps commits alive, so failure is harmless
+	e.repo.DeleteRef(trash.Ref)
+
+	return e.Locate(Selector{Type: SelectorID, Value: id})
+}
+
+// EmptyTrash permanently deletes trashed workspaces
+func (e *engine) EmptyTrash(opts EmptyTrashOptions) (result EmptyTrashResult, err error) {
+	err = e.withLock(writeLockTimeout, func() error {
+		result, err = e.emptyTrash(opts)
+		return err
+	})
+	return result, err
+}
+
+// emptyTrash implements EmptyTrash; the caller must hold the engine lock
+func (e *engine) emptyTrash(opts EmptyTrashOptions) (EmptyTrashResult, error) {
+	result := EmptyTrashResult{Purged: []Workspace{}}
+
+	trashed, err := e.ListTrash()
+	if err != nil {
+		return result, err
+	}
+
+	cutoff := time.Now().Add(-opts.OlderThan)
+	for _, ws := range trashed {
+		if ws.Trash.TrashedAt.After(cutoff) {
+			continue
+		}
+
+		if !opts.DryRun {
+			if err := e.purge(ws.ID, &result.Warnings); err != nil {
+				result.Warnings = append(result.Warnings, Warning{
+					Code:    "purge_failed",
+					Message: "Failed to purge '" + ws.Name + "': " + err.Error(),
+				})
+				continue
+			}
+		}
+
+		result.Purged = append(result.Purged, ws)
+	}
+
+	return result, nil
+}
+
+// purge deletes a trashed workspace's files, trash ref and metadata
+func (e *engine) purge(id string, warnings *[]Warning) error {
+	meta, err := e.store.Get(id)
+	if err != nil {
+		return err
+	}
+	if meta.Trash == nil {
+		return NewError(ErrConflict, "workspace is not in the trash").
+			WithDetail("id", id)
+	}
+
+	if err := os.RemoveAll(meta.Trash.Dir); err != nil {
+		return WrapError(ErrConfig, "failed to delete trashed files", err).
+			WithDetail("dir", meta.Trash.Dir)
+	}
+	if err := e.repo.DeleteRef(meta.Trash.Ref); err != nil {
+		return err
+	}
+	if err := e.store.Delete(id); err != nil {
+		return err
+	}
+
+	e.appendJournal(journal.Entry{
+		Operation:   journal.OpPurge,
+		WorkspaceID: id,
+		Name:        meta.Name,
+		Path:        meta.Path,
+		Previous:    &meta,
+		Branch:      meta.Trash.Branch,
+		HeadSHA:     meta.Trash.HeadSHA,
+	}, warnings)
+	return nil
+}
+
+// trashedID returns the workspace ID a trash ref belongs to
+func trashedID(ref string) string {
+	return strings.TrimPrefix(ref, trashRefPrefix)
+}
@@ -6,15 +6,21 @@ import (
 
 // Workspace represents a git worktree with metadata
 type Workspace struct {
-	ID        string         `json:"id"`
-	Name      string         `json:"name"`
-	Path      string         `json:"path"`
-	IsPrimary bool           `json:"isPrimary"`
-	Target    Target         `json:"target"`
-	Flags     WorkspaceFlags `json:"flags"`
-	Ephemeral *EphemeralInfo `json:"ephemeral,omitempty"`
-	Activity  ActivityInfo   `json:"activity"`
-	Status    StatusInfo     `json:"status"`
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	Path      string                 `json:"path"`
+	IsPrimary bool                   `json:"isPrimary"`
+	Target    Target                 `json:"target"`
+	Flags     WorkspaceFlags         `json:"flags"`
+	Labels    []string               `json:"labels,omitempty"`
+	Fields    map[string]interface{} `json:"fields,omitempty"` // string, float64 or bool values
+	Ephemeral *EphemeralInfo         `json:"ephemeral,omitempty"`
+	Seed      *SeedInfo              `json:"seed,omitempty"`
+	Sparse    *SparseInfo            `json:"sparse,omitempty"` // nil for full checkouts
+	Activity  ActivityInfo           `json:"activity"`
+	Status    StatusInfo             `json:"status"`
+	CreatedAt *time.Time             `json:"createdAt,omitempty"`
+	Trash     *TrashInfo             `json:"trash,omitempty"`
 }
 
 // Target represents the ref a workspace is tracking
@@ -38,6 +44,30 @@ type WorkspaceFlags struct {
 type EphemeralInfo struct {
 	TTLSeconds int       `json:"ttlSeconds"`
 	ExpiresAt  time.Time `json:"expiresAt"`
+	Sliding    bool      `json:"sliding,omitempty"`
+}
+
+// SeedInfo records where a workspace's build caches were last cloned from
+type SeedInfo struct {
+	FromID   string    `json:"fromId"`
+	FromName string    `json:"fromName"`
+	Dirs     []string  `json:"dirs"`
+	Bytes    int64     `json:"bytes"`
+	Method   string    `json:"method"` // reflink, hardlink or copy
+	SeededAt time.Time `json:"seededAt"`
+}
+
+// SparseInfo describes the sparse-checkout setup of a workspace
+type SparseInfo struct {
+	Profile  string   `json:"profile,omitempty"`
+	Patterns []string `json:"patterns"`
+	Cone     bool     `json:"cone"`
+}
+
+// TrashInfo describes a workspace that was moved to the trash
+type TrashInfo struct {
+	TrashedAt time.Time `json:"trashedAt"`
+	Dir       string    `json:"dir"` // where the workspace files are kept
 }
 
 // ActivityInfo tracks workspace usage
@@ -46,12 +76,30 @@ type ActivityInfo struct {
 	LastGitActivityAt *time.Time `json:"lastGitActivityAt,omitempty"`
 }
 
+// LastActiveAt returns the later of LastOpenedAt and LastGitActivityAt
+func (a ActivityInfo) LastActiveAt() *time.Time {
+	if a.LastOpenedAt != nil && (a.LastGitActivityAt == nil || a.LastOpenedAt.After(*a.LastGitActivityAt)) {
+		return a.LastOpenedAt
+	}
+	return a.LastGitActivityAt
+}
+
 // StatusInfo contains git status information
 type StatusInfo struct {
-	Dirty     bool   `json:"dirty"`
-	Conflicts bool   `json:"conflicts"`
-	Ahead     int    `json:"ahead"`
-	Behind    int    `json:"behind"`
-	Branch    string `json:"branch,omitempty"`
-	Detached  bool   `json:"detached"`
+	Dirty         bool              `json:"dirty"`
+	Conflicts     bool              `json:"conflicts"`
+	Ahead         int               `json:"ahead"`
+	Behind        int               `json:"behind"`
+	Branch        string            `json:"branch,omitempty"`
+	Detached      bool              `json:"detached"`
+	Submodules    []SubmoduleStatus `json:"submodules,omitempty"` // submodules with changes
+	SubmoduleOnly bool              `json:"submoduleOnly"`        // Dirty only because of Submodules
+}
+
+// SubmoduleStatus describes the changes in one submodule of a workspace
+type SubmoduleStatus struct {
+	Path          string `json:"path"`
+	CommitChanged bool   `json:"commitChanged"` // checked out at a different commit than recorded
+	Modified      bool   `json:"modified"`      // tracked files changed inside the submodule
+	Untracked     bool   `json:"untracked"`     // untracked files inside the submodule
 }
@@ -19,6 +19,7 @@ const (
 	ErrConflict  ErrorCode = "E_CONFLICT"
 	ErrTimeout   ErrorCode = "E_TIMEOUT"
 	ErrConfig    ErrorCode = "E_CONFIG"
+	ErrHook      ErrorCode = "E_HOOK"
 )
 
 // Error represents a structured error with hints
@@ -88,7 +89,7 @@ func (e *Error) ExitCode() int {
 		return 5
 	case ErrAmbiguous:
 		return 2
-	case ErrLocked:
+	case ErrLocked, ErrHook:
 		return 3
 	default:
 		return 1
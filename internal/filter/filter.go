@@ -1,19 +1,60 @@
 package filter
 
 import (
+	"cmp"
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/bmf/yagwt/internal/core"
-	"github.com/bmf/yagwt/internal/errors"
 )
 
 // Filter represents a filter that can match workspaces
 type Filter interface {
-	Match(ws core.Workspace) bool
+	Match(ws Workspace) bool
+}
+
+// Workspace represents a workspace for filter evaluation
+type Workspace interface {
+	GetName() string
+	GetTarget() Target
+	GetFlags() Flags
+	GetStatus() Status
+	GetActivity() Activity
+	GetCreatedAt() *time.Time // nil if unknown
+	GetExpiresAt() *time.Time // nil unless ephemeral
+	GetLabels() []string
+	GetField(key string) (interface{}, bool) // string, float64 or bool
+}
+
+// Target represents the ref a workspace is tracking
+type Target interface {
+	GetType() string  // "branch" or "commit"
+	GetShort() string // short branch name or SHA
+}
+
+// Flags represents workspace flags
+type Flags interface {
+	IsPinned() bool
+	IsEphemeral() bool
+	IsLocked() bool
+	IsBroken() bool
+}
+
+// Status represents workspace git status
+type Status interface {
+	IsDirty() bool
+	IsSubmoduleOnly() bool // dirty only because of changes inside submodules
+	HasConflicts() bool
+	IsDetached() bool
+	GetAhead() int
+	GetBehind() int
+}
+
+// Activity represents workspace activity
+type Activity interface {
+	GetLastActiveAt() *time.Time
 }
 
 // FilterExpr represents a composite filter expression
@@ -23,7 +64,7 @@ type FilterExpr struct {
 }
 
 // Match evaluates the filter expression against a workspace
-func (f *FilterExpr) Match(ws core.Workspace) bool {
+func (f *FilterExpr) Match(ws Workspace) bool {
 	if len(f.Filters) == 0 {
 		return true
 	}
@@ -47,21 +88,31 @@ func (f *FilterExpr) Match(ws core.Workspace) bool {
 	return true
 }
 
+// NotExpr negates a filter
+type NotExpr struct {
+	Filter Filter
+}
+
+// Match reports whether the inner filter does not match
+func (f *NotExpr) Match(ws Workspace) bool {
+	return !f.Filter.Match(ws)
+}
+
 // FlagFilter filters by workspace flags
 type FlagFilter struct {
 	Flag string
 }
 
-func (f *FlagFilter) Match(ws core.Workspace) bool {
+func (f *FlagFilter) Match(ws Workspace) bool {
 	switch f.Flag {
 	case "pinned":
-		return ws.Flags.Pinned
+		return ws.GetFlags().IsPinned()
 	case "ephemeral":
-		return ws.Flags.Ephemeral
+		return ws.GetFlags().IsEphemeral()
 	case "locked":
-		return ws.Flags.Locked
+		return ws.GetFlags().IsLocked()
 	case "broken":
-		return ws.Flags.Broken
+		return ws.GetFlags().IsBroken()
 	default:
 		return false
 	}
@@ -72,14 +123,16 @@ type StatusFilter struct {
 	Status string
 }
 
-func (f *StatusFilter) Match(ws core.Workspace) bool {
+func (f *StatusFilter) Match(ws Workspace) bool {
 	switch f.Status {
 	case "dirty":
-		return ws.Status.Dirty
+		return ws.GetStatus().IsDirty()
+	case "dirty-ignore-submodules":
+		return ws.GetStatus().IsDirty() && !ws.GetStatus().IsSubmoduleOnly()
 	case "clean":
-		return !ws.Status.Dirty
+		return !ws.GetStatus().IsDirty()
 	case "conflicts":
-		return ws.Status.Conflicts
+		return ws.GetStatus().HasConflicts()
 	default:
 		return false
 	}
@@ -90,12 +143,12 @@ type TargetFilter struct {
 	Type string
 }
 
-func (f *TargetFilter) Match(ws core.Workspace) bool {
+func (f *TargetFilter) Match(ws Workspace) bool {
 	switch f.Type {
 	case "branch":
-		return ws.Target.Type == "branch"
+		return ws.GetTarget().GetType() == "branch"
 	case "detached":
-		return ws.Target.Type == "commit" || ws.Status.Detached
+		return ws.GetTarget().GetType() == "commit" || ws.GetStatus().IsDetached()
 	default:
 		return false
 	}
@@ -106,7 +159,7 @@ type ActivityFilter struct {
 	Condition string // e.g., "idle>30d", "active<1h"
 }
 
-func (f *ActivityFilter) Match(ws core.Workspace) bool {
+func (f *ActivityFilter) Match(ws Workspace) bool {
 	// Parse condition: "idle>30d" or "active<1h"
 	if strings.HasPrefix(f.Condition, "idle>") {
 		durationStr := strings.TrimPrefix(f.Condition, "idle>")
@@ -115,13 +168,14 @@ func (f *ActivityFilter) Match(ws core.Workspace) bool {
 			return false
 		}
 
-		// Check if workspace is idle (no git activity) for longer than duration
-		if ws.Activity.LastGitActivityAt == nil {
+		// Check if workspace is idle (not opened or used) for longer than duration
+		lastActive := ws.GetActivity().GetLastActiveAt()
+		if lastActive == nil {
 			// No activity recorded, consider very idle
 			return true
 		}
 
-		idleTime := time.Since(*ws.Activity.LastGitActivityAt)
+		idleTime := time.Since(*lastActive)
 		return idleTime > duration
 	}
 
@@ -133,12 +187,13 @@ func (f *ActivityFilter) Match(ws core.Workspace) bool {
 		}
 
 		// Check if workspace was active within duration
-		if ws.Activity.LastGitActivityAt == nil {
+		lastActive := ws.GetActivity().GetLastActiveAt()
+		if lastActive == nil {
 			// No activity recorded
 			return false
 		}
 
-		idleTime := time.Since(*ws.Activity.LastGitActivityAt)
+		idleTime := time.Since(*lastActive)
 		return idleTime < duration
 	}
 
@@ -150,8 +205,8 @@ type NameFilter struct {
 	Pattern string
 }
 
-func (f *NameFilter) Match(ws core.Workspace) bool {
-	matched, err := filepath.Match(f.Pattern, ws.Name)
+func (f *NameFilter) Match(ws Workspace) bool {
+	matched, err := filepath.Match(f.Pattern, ws.GetName())
 	if err != nil {
 		return false
 	}
@@ -163,142 +218,178 @@ type BranchFilter struct {
 	Pattern string
 }
 
-func (f *BranchFilter) Match(ws core.Workspace) bool {
-	if ws.Target.Type != "branch" {
+func (f *BranchFilter) Match(ws Workspace) bool {
+	if ws.GetTarget().GetType() != "branch" {
 		return false
 	}
 
 	// Match against short branch name
-	matched, err := filepath.Match(f.Pattern, ws.Target.Short)
+	matched, err := filepath.Match(f.Pattern, ws.GetTarget().GetShort())
 	if err != nil {
 		return false
 	}
 	return matched
 }
 
-// ParseFilter parses a filter expression string
-func ParseFilter(expr string) (Filter, error) {
-	if expr == "" {
-		return &FilterExpr{}, nil
-	}
+// RegexFilter matches the name or branch against a regular expression
+type RegexFilter struct {
+	Field string // "name" or "branch"
+	Re    *regexp.Regexp
+}
 
-	// Check for OR logic (pipe separator)
-	if strings.Contains(expr, "|") {
-		parts := strings.Split(expr, "|")
-		var filters []Filter
-		for _, part := range parts {
-			f, err := parseSingleFilter(strings.TrimSpace(part))
-			if err != nil {
-				return nil, err
-			}
-			filters = append(filters, f)
+func (f *RegexFilter) Match(ws Workspace) bool {
+	if f.Field == "branch" {
+		if ws.GetTarget().GetType() != "branch" {
+			return false
 		}
-		return &FilterExpr{
-			Filters: filters,
-			Logic:   "or",
-		}, nil
+		return f.Re.MatchString(ws.GetTarget().GetShort())
 	}
+	return f.Re.MatchString(ws.GetName())
+}
 
-	// Check for AND logic (comma separator)
-	if strings.Contains(expr, ",") {
-		parts := strings.Split(expr, ",")
-		var filters []Filter
-		for _, part := range parts {
-			f, err := parseSingleFilter(strings.TrimSpace(part))
-			if err != nil {
-				return nil, err
-			}
-			filters = append(filters, f)
-		}
-		return &FilterExpr{
-			Filters: filters,
-			Logic:   "and",
-		}, nil
+// CountFilter compares commit counts, e.g. "ahead>3"
+type CountFilter struct {
+	Field string // "ahead" or "behind"
+	Op    string // =, !=, >, >=, <, <=
+	Value int
+}
+
+func (f *CountFilter) Match(ws Workspace) bool {
+	n := ws.GetStatus().GetAhead()
+	if f.Field == "behind" {
+		n = ws.GetStatus().GetBehind()
 	}
+	return compare(f.Op, int64(n), int64(f.Value))
+}
 
-	// Single filter
-	return parseSingleFilter(expr)
+// AgeFilter compares durations, e.g. "age>14d" (time since creation) or
+// "ttl<2d" (time left before an ephemeral workspace expires)
+type AgeFilter struct {
+	Field string // "age" or "ttl"
+	Op    string
+	Value time.Duration
 }
 
-// parseSingleFilter parses a single filter (e.g., "flag:pinned")
-func parseSingleFilter(expr string) (Filter, error) {
-	parts := strings.SplitN(expr, ":", 2)
-	if len(parts) != 2 {
-		return nil, errors.NewError(errors.ErrConfig, "invalid filter syntax").
-			WithDetail("filter", expr).
-			WithHint("Use format 'type:value' (e.g., flag:pinned)", "")
+func (f *AgeFilter) Match(ws Workspace) bool {
+	if f.Field == "ttl" {
+		expiresAt := ws.GetExpiresAt()
+		if expiresAt == nil {
+			return false
+		}
+		return compare(f.Op, int64(time.Until(*expiresAt)), int64(f.Value))
 	}
 
-	filterType := parts[0]
-	filterValue := parts[1]
+	createdAt := ws.GetCreatedAt()
+	if createdAt == nil {
+		return false
+	}
+	return compare(f.Op, int64(time.Since(*createdAt)), int64(f.Value))
+}
 
-	switch filterType {
-	case "flag":
-		validFlags := map[string]bool{
-			"pinned":    true,
-			"ephemeral": true,
-			"locked":    true,
-			"broken":    true,
-		}
-		if !validFlags[filterValue] {
-			return nil, errors.NewError(errors.ErrConfig, "invalid flag filter value").
-				WithDetail("value", filterValue).
-				WithHint("Valid flags: pinned, ephemeral, locked, broken", "")
-		}
-		return &FlagFilter{Flag: filterValue}, nil
+// LabelFilter matches workspaces with a label matching a glob pattern
+type LabelFilter struct {
+	Pattern string
+}
 
-	case "status":
-		validStatuses := map[string]bool{
-			"dirty":     true,
-			"clean":     true,
-			"conflicts": true,
-		}
-		if !validStatuses[filterValue] {
-			return nil, errors.NewError(errors.ErrConfig, "invalid status filter value").
-				WithDetail("value", filterValue).
-				WithHint("Valid statuses: dirty, clean, conflicts", "")
+func (f *LabelFilter) Match(ws Workspace) bool {
+	for _, label := range ws.GetLabels() {
+		if matched, err := filepath.Match(f.Pattern, label); err == nil && matched {
+			return true
 		}
-		return &StatusFilter{Status: filterValue}, nil
+	}
+	return false
+}
 
-	case "target":
-		validTargets := map[string]bool{
-			"branch":   true,
-			"detached": true,
-		}
-		if !validTargets[filterValue] {
-			return nil, errors.NewError(errors.ErrConfig, "invalid target filter value").
-				WithDetail("value", filterValue).
-				WithHint("Valid targets: branch, detached", "")
-		}
-		return &TargetFilter{Type: filterValue}, nil
-
-	case "activity":
-		// Validate activity condition format
-		if !strings.HasPrefix(filterValue, "idle>") && !strings.HasPrefix(filterValue, "active<") {
-			return nil, errors.NewError(errors.ErrConfig, "invalid activity filter condition").
-				WithDetail("value", filterValue).
-				WithHint("Use format 'idle>30d' or 'active<1h'", "")
-		}
-		return &ActivityFilter{Condition: filterValue}, nil
+// FieldFilter compares a custom field, e.g. "meta.ticket=ABC-123" or
+// "meta.estimate>3". Numbers compare numerically when the value is a
+// number too, everything else as text. Workspaces without the field never
+// match.
+type FieldFilter struct {
+	Key   string
+	Op    string // ":" (glob), "~" (regex) or a comparison
+	Value string
+	Re    *regexp.Regexp // set for "~"
+}
 
-	case "name":
-		if filterValue == "" {
-			return nil, errors.NewError(errors.ErrConfig, "name filter cannot be empty").
-				WithHint("Use a name pattern (e.g., name:feature-*)", "")
-		}
-		return &NameFilter{Pattern: filterValue}, nil
+func (f *FieldFilter) Match(ws Workspace) bool {
+	value, ok := ws.GetField(f.Key)
+	if !ok {
+		return false
+	}
+	text := FieldString(value)
+
+	switch f.Op {
+	case ":":
+		matched, err := filepath.Match(f.Value, text)
+		return err == nil && matched
+	case "~":
+		return f.Re.MatchString(text)
+	}
 
-	case "branch":
-		if filterValue == "" {
-			return nil, errors.NewError(errors.ErrConfig, "branch filter cannot be empty").
-				WithHint("Use a branch pattern (e.g., branch:main)", "")
+	if n, isNumber := value.(float64); isNumber {
+		if want, err := strconv.ParseFloat(f.Value, 64); err == nil {
+			return compare(f.Op, int64(cmp.Compare(n, want)), 0)
 		}
-		return &BranchFilter{Pattern: filterValue}, nil
+	}
+	return compare(f.Op, int64(cmp.Compare(text, f.Value)), 0)
+}
+
+// FieldString renders a custom field value the way it was set
+func FieldString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// compare applies a comparison operator
+func compare(op string, a, b int64) bool {
+	switch op {
+	case "=", ":":
+		return a == b
+	case "!=":
+		return a != b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	default:
+		return false
+	}
+}
 
+// NeedsGitState reports whether evaluating f requires git status or
+// activity, so callers can skip collecting them otherwise
+func NeedsGitState(f Filter) bool {
+	switch f := f.(type) {
+	case *FilterExpr:
+		for _, sub := range f.Filters {
+			if NeedsGitState(sub) {
+				return true
+			}
+		}
+		return false
+	case *NotExpr:
+		return NeedsGitState(f.Filter)
+	case *StatusFilter, *ActivityFilter, *CountFilter:
+		return true
+	case *AgeFilter:
+		// A sliding TTL moves with activity
+		return f.Field == "ttl"
+	case *TargetFilter:
+		return f.Type == "detached"
 	default:
-		return nil, errors.NewError(errors.ErrConfig, "unknown filter type").
-			WithDetail("type", filterType).
-			WithHint("Valid types: flag, status, target, activity, name, branch", "")
+		return false
 	}
 }
 
@@ -3,60 +3,119 @@ package filter
 import (
 	"testing"
 	"time"
-
-	"github.com/bmf/yagwt/internal/core"
 )
 
+// mockWorkspace implements Workspace (and its sub-interfaces) for testing
+type mockWorkspace struct {
+	name          string
+	targetType    string
+	branch        string
+	pinned        bool
+	ephemeral     bool
+	locked        bool
+	broken        bool
+	dirty         bool
+	conflicts     bool
+	submoduleOnly bool
+	detached      bool
+	ahead         int
+	behind        int
+	lastActivity  *time.Time
+	createdAt     *time.Time
+	expiresAt     *time.Time
+	labels        []string
+	fields        map[string]interface{}
+}
+
+func (w *mockWorkspace) GetName() string             { return w.name }
+func (w *mockWorkspace) GetTarget() Target           { return w }
+func (w *mockWorkspace) GetFlags() Flags             { return w }
+func (w *mockWorkspace) GetStatus() Status           { return w }
+func (w *mockWorkspace) GetActivity() Activity       { return w }
+func (w *mockWorkspace) GetType() string             { return w.targetType }
+func (w *mockWorkspace) GetShort() string            { return w.branch }
+func (w *mockWorkspace) IsPinned() bool              { return w.pinned }
+func (w *mockWorkspace) IsEphemeral() bool           { return w.ephemeral }
+func (w *mockWorkspace) IsLocked() bool              { return w.locked }
+func (w *mockWorkspace) IsBroken() bool              { return w.broken }
+func (w *mockWorkspace) IsDirty() bool               { return w.dirty }
+func (w *mockWorkspace) IsSubmoduleOnly() bool       { return w.submoduleOnly }
+func (w *mockWorkspace) HasConflicts() bool          { return w.conflicts }
+func (w *mockWorkspace) GetAhead() int               { return w.ahead }
+func (w *mockWorkspace) GetBehind() int              { return w.behind }
+func (w *mockWorkspace) GetCreatedAt() *time.Time    { return w.createdAt }
+func (w *mockWorkspace) GetExpiresAt() *time.Time    { return w.expiresAt }
+func (w *mockWorkspace) IsDetached() bool            { return w.detached }
+func (w *mockWorkspace) GetLastActiveAt() *time.Time { return w.lastActivity }
+func (w *mockWorkspace) GetLabels() []string         { return w.labels }
+
+func (w *mockWorkspace) GetField(key string) (interface{}, bool) {
+	value, ok := w.fields[key]
+	return value, ok
+}
+
 // Test helper to create test workspaces
-func makeTestWorkspace(opts map[string]interface{}) core.Workspace {
-	ws := core.Workspace{
-		ID:   "test-id",
-		Name: "test-workspace",
-		Path: "/test/path",
-		Target: core.Target{
-			Type:  "branch",
-			Short: "main",
-		},
-		Flags: core.WorkspaceFlags{},
-		Status: core.StatusInfo{
-			Dirty: false,
-		},
-		Activity: core.ActivityInfo{},
+func makeTestWorkspace(opts map[string]interface{}) Workspace {
+	ws := &mockWorkspace{
+		name:       "test-workspace",
+		targetType: "branch",
+		branch:     "main",
 	}
 
 	// Apply options
 	if name, ok := opts["name"].(string); ok {
-		ws.Name = name
+		ws.name = name
 	}
 	if pinned, ok := opts["pinned"].(bool); ok {
-		ws.Flags.Pinned = pinned
+		ws.pinned = pinned
 	}
 	if ephemeral, ok := opts["ephemeral"].(bool); ok {
-		ws.Flags.Ephemeral = ephemeral
+		ws.ephemeral = ephemeral
 	}
 	if locked, ok := opts["locked"].(bool); ok {
-		ws.Flags.Locked = locked
+		ws.locked = locked
 	}
 	if broken, ok := opts["broken"].(bool); ok {
-		ws.Flags.Broken = broken
+		ws.broken = broken
 	}
 	if dirty, ok := opts["dirty"].(bool); ok {
-		ws.Status.Dirty = dirty
+		ws.dirty = dirty
 	}
 	if conflicts, ok := opts["conflicts"].(bool); ok {
-		ws.Status.Conflicts = conflicts
+		ws.conflicts = conflicts
+	}
+	if submoduleOnly, ok := opts["submoduleOnly"].(bool); ok {
+		ws.submoduleOnly = submoduleOnly
 	}
 	if targetType, ok := opts["targetType"].(string); ok {
-		ws.Target.Type = targetType
+		ws.targetType = targetType
 	}
 	if detached, ok := opts["detached"].(bool); ok {
-		ws.Status.Detached = detached
+		ws.detached = detached
 	}
 	if branch, ok := opts["branch"].(string); ok {
-		ws.Target.Short = branch
+		ws.branch = branch
 	}
 	if lastActivity, ok := opts["lastActivity"].(time.Time); ok {
-		ws.Activity.LastGitActivityAt = &lastActivity
+		ws.lastActivity = &lastActivity
+	}
+	if ahead, ok := opts["ahead"].(int); ok {
+		ws.ahead = ahead
+	}
+	if behind, ok := opts["behind"].(int); ok {
+		ws.behind = behind
+	}
+	if createdAt, ok := opts["createdAt"].(time.Time); ok {
+		ws.createdAt = &createdAt
+	}
+	if expiresAt, ok := opts["expiresAt"].(time.Time); ok {
+		ws.expiresAt = &expiresAt
+	}
+	if labels, ok := opts["labels"].([]string); ok {
+		ws.labels = labels
+	}
+	if fields, ok := opts["fields"].(map[string]interface{}); ok {
+		ws.fields = fields
 	}
 
 	return ws
@@ -95,6 +154,7 @@ func TestParseStatusFilter(t *testing.T) {
 		{"dirty", "status:dirty", false},
 		{"clean", "status:clean", false},
 		{"conflicts", "status:conflicts", false},
+		{"dirty ignoring submodules", "status:dirty-ignore-submodules", false},
 		{"invalid status", "status:invalid", true},
 	}
 
@@ -214,6 +274,34 @@ func TestParseAndLogic(t *testing.T) {
 	}
 }
 
+func TestNeedsGitState(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"", false},
+		{"flag:pinned", false},
+		{"name:feature-*,branch:main", false},
+		{"target:branch", false},
+		{"target:detached", true},
+		{"status:dirty", true},
+		{"activity:idle>30d", true},
+		{"flag:pinned|status:dirty", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			filter, err := ParseFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseFilter() error = %v", err)
+			}
+			if got := NeedsGitState(filter); got != tt.want {
+				t.Errorf("NeedsGitState(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestParseOrLogic(t *testing.T) {
 	filter, err := ParseFilter("flag:pinned|flag:ephemeral")
 	if err != nil {
@@ -256,7 +344,7 @@ func TestFlagFilterMatch(t *testing.T) {
 	tests := []struct {
 		name      string
 		filter    string
-		ws        core.Workspace
+		ws        Workspace
 		wantMatch bool
 	}{
 		{
@@ -309,7 +397,7 @@ func TestStatusFilterMatch(t *testing.T) {
 	tests := []struct {
 		name      string
 		filter    string
-		ws        core.Workspace
+		ws        Workspace
 		wantMatch bool
 	}{
 		{
@@ -330,6 +418,24 @@ func TestStatusFilterMatch(t *testing.T) {
 			ws:        makeTestWorkspace(map[string]interface{}{"conflicts": true}),
 			wantMatch: true,
 		},
+		{
+			name:      "dirty matches submodule-only changes",
+			filter:    "status:dirty",
+			ws:        makeTestWorkspace(map[string]interface{}{"dirty": true, "submoduleOnly": true}),
+			wantMatch: true,
+		},
+		{
+			name:      "dirty-ignore-submodules skips submodule-only changes",
+			filter:    "status:dirty-ignore-submodules",
+			ws:        makeTestWorkspace(map[string]interface{}{"dirty": true, "submoduleOnly": true}),
+			wantMatch: false,
+		},
+		{
+			name:      "dirty-ignore-submodules matches file changes",
+			filter:    "status:dirty-ignore-submodules",
+			ws:        makeTestWorkspace(map[string]interface{}{"dirty": true}),
+			wantMatch: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -350,7 +456,7 @@ func TestTargetFilterMatch(t *testing.T) {
 	tests := []struct {
 		name      string
 		filter    string
-		ws        core.Workspace
+		ws        Workspace
 		wantMatch bool
 	}{
 		{
@@ -393,7 +499,7 @@ func TestActivityFilterMatch(t *testing.T) {
 	tests := []struct {
 		name      string
 		filter    string
-		ws        core.Workspace
+		ws        Workspace
 		wantMatch bool
 	}{
 		{
@@ -452,7 +558,7 @@ func TestNameFilterMatch(t *testing.T) {
 	tests := []struct {
 		name      string
 		filter    string
-		ws        core.Workspace
+		ws        Workspace
 		wantMatch bool
 	}{
 		{
@@ -493,7 +599,7 @@ func TestBranchFilterMatch(t *testing.T) {
 	tests := []struct {
 		name      string
 		filter    string
-		ws        core.Workspace
+		ws        Workspace
 		wantMatch bool
 	}{
 		{
@@ -538,7 +644,7 @@ func TestFilterExprAndLogic(t *testing.T) {
 
 	tests := []struct {
 		name      string
-		ws        core.Workspace
+		ws        Workspace
 		wantMatch bool
 	}{
 		{
@@ -580,7 +686,7 @@ func TestFilterExprOrLogic(t *testing.T) {
 
 	tests := []struct {
 		name      string
-		ws        core.Workspace
+		ws        Workspace
 		wantMatch bool
 	}{
 		{
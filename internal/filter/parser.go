@@ -0,0 +1,509 @@
+package filter
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bmf/yagwt/internal/errors"
+)
+
+// Grammar (keywords are case-insensitive; "," is an alias for "and", "|"
+// for "or" and "!" for "not"; adjacent terms are joined with "and"):
+//
+//	expr  := and { "or" and }
+//	and   := unary { ["and"] unary }
+//	unary := "not" unary | "(" expr ")" | term
+//	term  := key op value
+//	op    := ":" | "~" | "=" | "!=" | ">" | ">=" | "<" | "<="
+//	value := bare-word | "double quoted" | 'single quoted'
+
+// tokenKind identifies a lexical token in a filter expression
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+	tokTerm
+)
+
+// token is a lexical token; terms carry their key, operator and value.
+// Columns are 1-based.
+type token struct {
+	kind     tokenKind
+	col      int
+	text     string
+	key      string
+	op       string
+	value    string
+	valueCol int
+}
+
+// lexer splits a filter expression into tokens
+type lexer struct {
+	expr string
+	pos  int
+}
+
+// operators in match order (two-character operators first)
+var operators = []string{">=", "<=", "!=", ":", "~", "=", ">", "<"}
+
+func isKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '-' || c == '.'
+}
+
+func isValueEnd(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '(' || c == ')' || c == ',' || c == '|'
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.expr) && (l.expr[l.pos] == ' ' || l.expr[l.pos] == '\t' || l.expr[l.pos] == '\n') {
+		l.pos++
+	}
+	if l.pos >= len(l.expr) {
+		return token{kind: tokEOF, col: l.pos + 1, text: "end of expression"}, nil
+	}
+
+	start := l.pos
+	col := start + 1
+
+	switch l.expr[l.pos] {
+	case '(':
+		l.pos++
+		return token{kind: tokLParen, col: col, text: "("}, nil
+	case ')':
+		l.pos++
+		return token{kind: tokRParen, col: col, text: ")"}, nil
+	case ',':
+		l.pos++
+		return token{kind: tokAnd, col: col, text: ","}, nil
+	case '|':
+		l.pos++
+		return token{kind: tokOr, col: col, text: "|"}, nil
+	case '!':
+		l.pos++
+		return token{kind: tokNot, col: col, text: "!"}, nil
+	}
+
+	if !isKeyChar(l.expr[l.pos]) {
+		return token{}, syntaxError(l.expr, col, fmt.Sprintf("unexpected %q", l.expr[l.pos]))
+	}
+
+	for l.pos < len(l.expr) && isKeyChar(l.expr[l.pos]) {
+		l.pos++
+	}
+	key := l.expr[start:l.pos]
+
+	op := ""
+	for _, candidate := range operators {
+		if strings.HasPrefix(l.expr[l.pos:], candidate) {
+			op = candidate
+			break
+		}
+	}
+
+	if op == "" {
+		switch strings.ToLower(key) {
+		case "and":
+			return token{kind: tokAnd, col: col, text: key}, nil
+		case "or":
+			return token{kind: tokOr, col: col, text: key}, nil
+		case "not":
+			return token{kind: tokNot, col: col, text: key}, nil
+		}
+		return token{}, syntaxError(l.expr, l.pos+1, fmt.Sprintf("expected an operator after %q", key)).
+			WithHint("Use key:value or a comparison (e.g., flag:pinned, ahead>3, name~^feat)", "")
+	}
+	l.pos += len(op)
+
+	valueCol := l.pos + 1
+	value, err := l.value()
+	if err != nil {
+		return token{}, err
+	}
+	if value == "" {
+		return token{}, syntaxError(l.expr, valueCol, fmt.Sprintf("missing value after %q", key+op))
+	}
+
+	return token{
+		kind:     tokTerm,
+		col:      col,
+		text:     l.expr[start:l.pos],
+		key:      key,
+		op:       op,
+		value:    value,
+		valueCol: valueCol,
+	}, nil
+}
+
+// value reads a bare or quoted term value
+func (l *lexer) value() (string, error) {
+	if l.pos >= len(l.expr) {
+		return "", nil
+	}
+
+	quote := l.expr[l.pos]
+	if quote != '"' && quote != '\'' {
+		start := l.pos
+		for l.pos < len(l.expr) && !isValueEnd(l.expr[l.pos]) {
+			l.pos++
+		}
+		return l.expr[start:l.pos], nil
+	}
+
+	openCol := l.pos + 1
+	l.pos++
+	var b strings.Builder
+	for l.pos < len(l.expr) {
+		c := l.expr[l.pos]
+		switch {
+		case c == '\\' && l.pos+1 < len(l.expr):
+			b.WriteByte(l.expr[l.pos+1])
+			l.pos += 2
+		case c == quote:
+			l.pos++
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+
+	return "", syntaxError(l.expr, openCol, "unterminated quoted value")
+}
+
+// parser builds a Filter tree from tokens by recursive descent
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+// ParseFilter parses a filter expression such as
+// `not flag:pinned and (status:dirty or ahead>0)`. An empty expression
+// matches every workspace. Errors are ErrConfig and report the column.
+func ParseFilter(expr string) (Filter, error) {
+	if strings.TrimSpace(expr) == "" {
+		return &FilterExpr{}, nil
+	}
+
+	p := &parser{lex: &lexer{expr: expr}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind != tokEOF {
+		return nil, syntaxError(expr, p.tok.col, fmt.Sprintf("unexpected %q", p.tok.text))
+	}
+
+	return f, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) parseOr() (Filter, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	filters := []Filter{first}
+	for p.tok.kind == tokOr {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, next)
+	}
+
+	if len(filters) == 1 {
+		return first, nil
+	}
+	return &FilterExpr{Filters: filters, Logic: "or"}, nil
+}
+
+func (p *parser) parseAnd() (Filter, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	filters := []Filter{first}
+	for {
+		switch p.tok.kind {
+		case tokAnd:
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		case tokTerm, tokNot, tokLParen:
+			// Adjacent terms are joined with "and"
+		default:
+			if len(filters) == 1 {
+				return first, nil
+			}
+			return &FilterExpr{Filters: filters, Logic: "and"}, nil
+		}
+
+		next, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, next)
+	}
+}
+
+func (p *parser) parseUnary() (Filter, error) {
+	switch p.tok.kind {
+	case tokNot:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotExpr{Filter: inner}, nil
+
+	case tokLParen:
+		openCol := p.tok.col
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, syntaxError(p.lex.expr, p.tok.col, fmt.Sprintf("expected ')' to close '(' at column %d", openCol))
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return inner, nil
+
+	case tokTerm:
+		tok := p.tok
+		f, err := buildTerm(p.lex.expr, tok)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return f, nil
+
+	case tokEOF:
+		return nil, syntaxError(p.lex.expr, p.tok.col, "unexpected end of expression")
+
+	default:
+		return nil, syntaxError(p.lex.expr, p.tok.col, fmt.Sprintf("unexpected %q", p.tok.text))
+	}
+}
+
+// buildTerm turns a term token into a filter, validating its key, operator
+// and value
+func buildTerm(expr string, tok token) (Filter, error) {
+	key, op, value := strings.ToLower(tok.key), tok.op, tok.value
+
+	requireOp := func(allowed ...string) *errors.Error {
+		for _, a := range allowed {
+			if op == a {
+				return nil
+			}
+		}
+		return syntaxError(expr, tok.col+len(tok.key), fmt.Sprintf("operator %q is not supported for %s", op, key)).
+			WithDetail("supported", strings.Join(allowed, " "))
+	}
+
+	// Custom field keys keep their case
+	if strings.HasPrefix(key, "meta.") {
+		field := tok.key[len("meta."):]
+		if field == "" {
+			return nil, syntaxError(expr, tok.col, "missing field name after \"meta.\"").
+				WithHint("Use meta.<key>=<value>, e.g. meta.ticket=ABC-123", "")
+		}
+		if err := requireOp(":", "~", "=", "!=", ">", ">=", "<", "<="); err != nil {
+			return nil, err
+		}
+		f := &FieldFilter{Key: field, Op: op, Value: value}
+		switch op {
+		case "~":
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return nil, syntaxError(expr, tok.valueCol, "invalid regular expression").
+					WithDetail("value", value).
+					WithDetail("reason", err.Error())
+			}
+			f.Re = re
+		case ":":
+			if _, err := filepath.Match(value, ""); err != nil {
+				return nil, syntaxError(expr, tok.valueCol, "invalid glob pattern").
+					WithDetail("value", value)
+			}
+		}
+		return f, nil
+	}
+
+	switch key {
+	case "label":
+		if err := requireOp(":"); err != nil {
+			return nil, err
+		}
+		if _, err := filepath.Match(value, ""); err != nil {
+			return nil, syntaxError(expr, tok.valueCol, "invalid glob pattern").
+				WithDetail("value", value)
+		}
+		return &LabelFilter{Pattern: value}, nil
+
+	case "flag":
+		if err := requireOp(":"); err != nil {
+			return nil, err
+		}
+		validFlags := map[string]bool{
+			"pinned":    true,
+			"ephemeral": true,
+			"locked":    true,
+			"broken":    true,
+		}
+		if !validFlags[value] {
+			return nil, syntaxError(expr, tok.valueCol, "invalid flag filter value").
+				WithDetail("value", value).
+				WithHint("Valid flags: pinned, ephemeral, locked, broken", "")
+		}
+		return &FlagFilter{Flag: value}, nil
+
+	case "status":
+		if err := requireOp(":"); err != nil {
+			return nil, err
+		}
+		validStatuses := map[string]bool{
+			"dirty":                   true,
+			"dirty-ignore-submodules": true,
+			"clean":                   true,
+			"conflicts":               true,
+		}
+		if !validStatuses[value] {
+			return nil, syntaxError(expr, tok.valueCol, "invalid status filter value").
+				WithDetail("value", value).
+				WithHint("Valid statuses: dirty, dirty-ignore-submodules, clean, conflicts", "")
+		}
+		return &StatusFilter{Status: value}, nil
+
+	case "target":
+		if err := requireOp(":"); err != nil {
+			return nil, err
+		}
+		if value != "branch" && value != "detached" {
+			return nil, syntaxError(expr, tok.valueCol, "invalid target filter value").
+				WithDetail("value", value).
+				WithHint("Valid targets: branch, detached", "")
+		}
+		return &TargetFilter{Type: value}, nil
+
+	case "activity":
+		if err := requireOp(":"); err != nil {
+			return nil, err
+		}
+		condition, durationStr, ok := strings.Cut(value, ">")
+		if !ok || condition != "idle" {
+			condition, durationStr, ok = strings.Cut(value, "<")
+			if !ok || condition != "active" {
+				return nil, syntaxError(expr, tok.valueCol, "invalid activity filter condition").
+					WithDetail("value", value).
+					WithHint("Use format 'idle>30d' or 'active<1h'", "")
+			}
+		}
+		if _, err := parseDuration(durationStr); err != nil {
+			return nil, syntaxError(expr, tok.valueCol+len(condition)+1, "invalid duration").
+				WithDetail("value", durationStr).
+				WithDetail("reason", err.Error())
+		}
+		return &ActivityFilter{Condition: value}, nil
+
+	case "name", "branch":
+		if err := requireOp(":", "~"); err != nil {
+			return nil, err
+		}
+		if op == "~" {
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return nil, syntaxError(expr, tok.valueCol, "invalid regular expression").
+					WithDetail("value", value).
+					WithDetail("reason", err.Error())
+			}
+			return &RegexFilter{Field: key, Re: re}, nil
+		}
+		if _, err := filepath.Match(value, ""); err != nil {
+			return nil, syntaxError(expr, tok.valueCol, "invalid glob pattern").
+				WithDetail("value", value)
+		}
+		if key == "branch" {
+			return &BranchFilter{Pattern: value}, nil
+		}
+		return &NameFilter{Pattern: value}, nil
+
+	case "ahead", "behind":
+		if err := requireOp(":", "=", "!=", ">", ">=", "<", "<="); err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, syntaxError(expr, tok.valueCol, "expected a commit count").
+				WithDetail("value", value)
+		}
+		return &CountFilter{Field: key, Op: op, Value: n}, nil
+
+	case "age", "ttl":
+		if err := requireOp("=", "!=", ">", ">=", "<", "<="); err != nil {
+			return nil, err
+		}
+		d, err := parseDuration(value)
+		if err != nil {
+			return nil, syntaxError(expr, tok.valueCol, "invalid duration").
+				WithDetail("value", value).
+				WithDetail("reason", err.Error())
+		}
+		return &AgeFilter{Field: key, Op: op, Value: d}, nil
+
+	default:
+		return nil, syntaxError(expr, tok.col, "unknown filter type").
+			WithDetail("type", tok.key).
+			WithHint("Valid types: flag, status, target, activity, name, branch, label, meta.<key>, ahead, behind, age, ttl", "")
+	}
+}
+
+// syntaxError reports a problem at a 1-based column of the expression
+func syntaxError(expr string, col int, message string) *errors.Error {
+	near := ""
+	if col-1 < len(expr) {
+		near = expr[col-1:]
+	}
+	err := errors.NewError(errors.ErrConfig, message).
+		WithDetail("filter", expr).
+		WithDetail("column", col)
+	if near != "" {
+		err = err.WithDetail("near", near)
+	}
+	return err
+}
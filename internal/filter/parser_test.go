@@ -0,0 +1,140 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bmf/yagwt/internal/errors"
+)
+
+func TestParseExpressionMatch(t *testing.T) {
+	now := time.Now()
+
+	pinnedDirty := makeTestWorkspace(map[string]interface{}{"pinned": true, "dirty": true})
+	dirty := makeTestWorkspace(map[string]interface{}{"dirty": true})
+	ahead := makeTestWorkspace(map[string]interface{}{"ahead": 4})
+	clean := makeTestWorkspace(map[string]interface{}{})
+	old := makeTestWorkspace(map[string]interface{}{"createdAt": now.Add(-20 * 24 * time.Hour)})
+	expiring := makeTestWorkspace(map[string]interface{}{"expiresAt": now.Add(24 * time.Hour)})
+	feature := makeTestWorkspace(map[string]interface{}{"name": "feat-login", "branch": "feature/ABC-12"})
+	tagged := makeTestWorkspace(map[string]interface{}{
+		"labels": []string{"backend", "urgent"},
+		"fields": map[string]interface{}{"ticket": "ABC-123", "estimate": float64(5), "Reviewer": "sam", "flaky": true},
+	})
+
+	tests := []struct {
+		expr string
+		ws   Workspace
+		want bool
+	}{
+		{"not flag:pinned and (status:dirty or ahead>0)", dirty, true},
+		{"not flag:pinned and (status:dirty or ahead>0)", ahead, true},
+		{"not flag:pinned and (status:dirty or ahead>0)", pinnedDirty, false},
+		{"not flag:pinned and (status:dirty or ahead>0)", clean, false},
+		{"NOT flag:pinned AND status:dirty", dirty, true},
+		{"!flag:pinned", pinnedDirty, false},
+		{"flag:pinned status:dirty", pinnedDirty, true},
+		{"flag:pinned status:dirty", dirty, false},
+		{"flag:pinned or status:dirty and ahead>0", pinnedDirty, true},
+		{"(flag:pinned or status:dirty) and ahead>0", pinnedDirty, false},
+		{"flag:pinned,status:dirty|ahead>3", ahead, true},
+		{"ahead>=4", ahead, true},
+		{"ahead>4", ahead, false},
+		{"ahead=4", ahead, true},
+		{"ahead!=4", ahead, false},
+		{"behind>=10", ahead, false},
+		{"age>14d", old, true},
+		{"age<14d", old, false},
+		{"age>14d", clean, false},
+		{"ttl<2d", expiring, true},
+		{"ttl>2d", expiring, false},
+		{"ttl<2d", clean, false},
+		{"name~^feat-", feature, true},
+		{"name~'^feat-(login|logout)$'", feature, true},
+		{"branch~ABC-[0-9]+", feature, true},
+		{"branch~^main$", feature, false},
+		{`name:"feat-*"`, feature, true},
+		{"label:backend", tagged, true},
+		{"label:back*", tagged, true},
+		{"label:frontend", tagged, false},
+		{"label:backend", clean, false},
+		{"meta.ticket=ABC-123", tagged, true},
+		{"meta.ticket!=ABC-123", tagged, false},
+		{"meta.ticket:ABC-*", tagged, true},
+		{"meta.ticket~^ABC-[0-9]+$", tagged, true},
+		{"meta.estimate>3", tagged, true},
+		{"meta.estimate>10", tagged, false},
+		{"meta.estimate=5.0", tagged, true},
+		{"meta.Reviewer=sam", tagged, true},
+		{"meta.reviewer=sam", tagged, false},
+		{"meta.flaky=true", tagged, true},
+		{"meta.ticket=ABC-123", clean, false},
+		{"not meta.ticket=ABC-123", clean, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := ParseFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseFilter(%q) error = %v", tt.expr, err)
+			}
+			if got := f.Match(tt.ws); got != tt.want {
+				t.Errorf("ParseFilter(%q).Match() = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		expr   string
+		column int
+	}{
+		{"flag:pinned and", 16},
+		{"(flag:pinned or status:dirty", 29},
+		{"flag:pinned)", 12},
+		{"flag:pinned and and status:dirty", 17},
+		{"status:dirty and invalid", 25},
+		{"flag:shiny", 6},
+		{"name~[a-", 6},
+		{"ahead>lots", 7},
+		{"age>soon", 5},
+		{"name>3", 5},
+		{`name:"unterminated`, 6},
+		{"colour:red", 1},
+		{"flag:pinned # comment", 13},
+		{"label>3", 6},
+		{"meta.=x", 1},
+		{"meta.ticket~[a-", 13},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseFilter(tt.expr)
+			if err == nil {
+				t.Fatalf("ParseFilter(%q) should return error", tt.expr)
+			}
+
+			coreErr, ok := err.(*errors.Error)
+			if !ok {
+				t.Fatalf("Expected *errors.Error, got %T", err)
+			}
+			if coreErr.Code != errors.ErrConfig {
+				t.Errorf("Expected error code %s, got %s", errors.ErrConfig, coreErr.Code)
+			}
+			if coreErr.Details["column"] != tt.column {
+				t.Errorf("ParseFilter(%q) column = %v, want %d (%s)", tt.expr, coreErr.Details["column"], tt.column, coreErr.Message)
+			}
+		})
+	}
+}
+
+func TestNotExprNeedsGitState(t *testing.T) {
+	f, err := ParseFilter("not (flag:pinned or ahead>0)")
+	if err != nil {
+		t.Fatalf("ParseFilter() error = %v", err)
+	}
+	if !NeedsGitState(f) {
+		t.Error("Expected negated ahead comparison to need git state")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/bmf/yagwt/internal/errors"
 )
@@ -103,6 +104,130 @@ func TestNewRepository(t *testing.T) {
 	if !pathsEqual(t, repo2.Root(), repoDir) {
 		t.Errorf("Expected root %q from subdir, got %q", repoDir, repo2.Root())
 	}
+
+	// Linked worktrees have their own git dir but share the common dir
+	wtPath := filepath.Join(t.TempDir(), "linked")
+	runGit(t, repoDir, "worktree", "add", "--detach", wtPath)
+
+	repo3, err := NewRepository(wtPath)
+	if err != nil {
+		t.Fatalf("Failed to create repository from linked worktree: %v", err)
+	}
+
+	if !pathsEqual(t, repo3.CommonDir(), expectedGitDir) {
+		t.Errorf("Expected commonDir %q, got %q", expectedGitDir, repo3.CommonDir())
+	}
+	if pathsEqual(t, repo3.GitDir(), expectedGitDir) {
+		t.Errorf("Expected a per-worktree gitDir, got %q", repo3.GitDir())
+	}
+
+	// The root is the main worktree, not the one we were opened from
+	if !pathsEqual(t, repo3.Root(), repoDir) {
+		t.Errorf("Expected root %q from linked worktree, got %q", repoDir, repo3.Root())
+	}
+}
+
+func TestNewRepository_Bare(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	bareDir := filepath.Join(t.TempDir(), "repo.git")
+	runGit(t, "", "clone", "--bare", repoDir, bareDir)
+
+	repo, err := NewRepository(bareDir)
+	if err != nil {
+		t.Fatalf("Failed to open bare repository: %v", err)
+	}
+	if !repo.IsBare() {
+		t.Error("Expected IsBare() to be true")
+	}
+	if !pathsEqual(t, repo.Root(), bareDir) || !pathsEqual(t, repo.CommonDir(), bareDir) {
+		t.Errorf("Expected root and commonDir %q, got %q and %q", bareDir, repo.Root(), repo.CommonDir())
+	}
+
+	wtPath := filepath.Join(filepath.Dir(bareDir), "feature")
+	if err := repo.AddWorktree(wtPath, "HEAD", AddOptions{Detach: true}); err != nil {
+		t.Fatalf("Failed to add worktree: %v", err)
+	}
+
+	// Opened from its linked worktree, the repository is still bare
+	fromLinked, err := NewRepository(wtPath)
+	if err != nil {
+		t.Fatalf("Failed to open linked worktree: %v", err)
+	}
+	if !fromLinked.IsBare() || !pathsEqual(t, fromLinked.Root(), bareDir) {
+		t.Errorf("Expected a bare repository rooted at %q, got bare=%v root %q", bareDir, fromLinked.IsBare(), fromLinked.Root())
+	}
+
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		t.Fatalf("Failed to list worktrees: %v", err)
+	}
+	if len(worktrees) != 2 || !worktrees[0].Bare {
+		t.Fatalf("Expected the bare entry and one worktree, got %+v", worktrees)
+	}
+	for _, wt := range worktrees {
+		if wt.Main {
+			t.Errorf("Bare repository has no main worktree, got %+v", wt)
+		}
+	}
+
+	// A non-bare repository's checkout is main, its linked worktrees are not
+	main, _ := NewRepository(repoDir)
+	linked := filepath.Join(t.TempDir(), "linked")
+	runGit(t, repoDir, "worktree", "add", "--detach", linked)
+	worktrees, err = main.ListWorktrees()
+	if err != nil {
+		t.Fatalf("Failed to list worktrees: %v", err)
+	}
+	for _, wt := range worktrees {
+		if wantMain := pathsEqual(t, wt.Path, repoDir); wt.Main != wantMain {
+			t.Errorf("Worktree %s: expected main=%v", wt.Path, wantMain)
+		}
+	}
+}
+
+func TestListWorktrees_SeparateGitDir(t *testing.T) {
+	// The main worktree's .git is a file pointing at the real git dir
+	base := t.TempDir()
+	repoDir := filepath.Join(base, "work")
+	runGit(t, "", "init", "--separate-git-dir", filepath.Join(base, "repo.git"), repoDir)
+	runGit(t, repoDir, "config", "user.email", "test@example.com")
+	runGit(t, repoDir, "config", "user.name", "Test User")
+	runGit(t, repoDir, "commit", "--allow-empty", "-m", "Initial commit")
+
+	linked := filepath.Join(base, "linked")
+	runGit(t, repoDir, "worktree", "add", "--detach", linked)
+
+	// From the main worktree its real path is known
+	repo, err := NewRepository(repoDir)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		t.Fatalf("Failed to list worktrees: %v", err)
+	}
+	if len(worktrees) != 2 {
+		t.Fatalf("Expected 2 worktrees, got %+v", worktrees)
+	}
+	for _, wt := range worktrees {
+		if wantMain := pathsEqual(t, wt.Path, repoDir); wt.Main != wantMain {
+			t.Errorf("Worktree %s: expected main=%v", wt.Path, wantMain)
+		}
+	}
+
+	// From a linked worktree git only knows the git dir, but the entry is
+	// still the main worktree
+	repo, err = NewRepository(linked)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	worktrees, err = repo.ListWorktrees()
+	if err != nil {
+		t.Fatalf("Failed to list worktrees: %v", err)
+	}
+	if len(worktrees) != 2 || !worktrees[0].Main || worktrees[1].Main {
+		t.Errorf("Expected only the first worktree to be main, got %+v", worktrees)
+	}
 }
 
 func TestNewRepository_NotGitRepo(t *testing.T) {
@@ -347,6 +472,47 @@ func TestGetStatus(t *testing.T) {
 	}
 }
 
+func TestLastActivity(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	repo, _ := NewRepository(repoDir)
+
+	before, err := repo.LastActivity(repoDir)
+	if err != nil {
+		t.Fatalf("LastActivity() failed: %v", err)
+	}
+	if before.IsZero() {
+		t.Fatal("Expected activity from the initial commit")
+	}
+
+	// Status must not count as activity
+	if _, err := repo.GetStatus(repoDir); err != nil {
+		t.Fatalf("Failed to get status: %v", err)
+	}
+	after, err := repo.LastActivity(repoDir)
+	if err != nil {
+		t.Fatalf("LastActivity() failed: %v", err)
+	}
+	if after.After(before.Add(time.Second)) {
+		t.Errorf("GetStatus() changed activity from %v to %v", before, after)
+	}
+
+	// Staging a file bumps the index mtime
+	future := time.Now().Add(time.Hour)
+	writeFile(t, filepath.Join(repoDir, "staged.txt"), "staged\n")
+	runGit(t, repoDir, "add", "staged.txt")
+	if err := os.Chtimes(filepath.Join(repoDir, ".git", "index"), future, future); err != nil {
+		t.Fatalf("Failed to set index mtime: %v", err)
+	}
+
+	latest, err := repo.LastActivity(repoDir)
+	if err != nil {
+		t.Fatalf("LastActivity() failed: %v", err)
+	}
+	if latest.Before(future.Add(-time.Second)) {
+		t.Errorf("LastActivity() = %v, want index mtime %v", latest, future)
+	}
+}
+
 func TestRemoveWorktree_Dirty(t *testing.T) {
 	repoDir := setupTestRepo(t)
 	repo, _ := NewRepository(repoDir)
@@ -421,3 +587,320 @@ func TestGetBranch(t *testing.T) {
 	// Note: In a fresh test repo without remotes, upstream tracking might not work as expected
 	// so we just verify the branch info was retrieved
 }
+
+func TestMoveWorktree(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	repo, _ := NewRepository(repoDir)
+
+	runGit(t, repoDir, "branch", "move-branch")
+	baseDir, _ := filepath.EvalSymlinks(t.TempDir())
+	wtDir := filepath.Join(baseDir, "before")
+	newDir := filepath.Join(baseDir, "after")
+
+	if err := repo.AddWorktree(wtDir, "move-branch", AddOptions{}); err != nil {
+		t.Fatalf("Failed to add worktree: %v", err)
+	}
+
+	if err := repo.MoveWorktree(wtDir, newDir); err != nil {
+		t.Fatalf("Failed to move worktree: %v", err)
+	}
+
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		t.Fatalf("Failed to list worktrees: %v", err)
+	}
+
+	var found bool
+	for _, wt := range worktrees {
+		if pathsEqual(t, wt.Path, newDir) {
+			found = true
+		}
+		if pathsEqual(t, wt.Path, wtDir) {
+			t.Errorf("Old worktree path %q still listed", wtDir)
+		}
+	}
+
+	if !found {
+		t.Errorf("Moved worktree not found at %q", newDir)
+	}
+}
+
+func TestIsMerged(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	repo, _ := NewRepository(repoDir)
+
+	base := strings.TrimSpace(runGit(t, repoDir, "rev-parse", "--abbrev-ref", "HEAD"))
+
+	runGit(t, repoDir, "branch", "merged-branch")
+	runGit(t, repoDir, "checkout", "-b", "unmerged-branch")
+	writeFile(t, filepath.Join(repoDir, "new.txt"), "new\n")
+	runGit(t, repoDir, "add", "new.txt")
+	runGit(t, repoDir, "commit", "-m", "Unmerged work")
+	runGit(t, repoDir, "checkout", base)
+
+	merged, err := repo.IsMerged("merged-branch", base)
+	if err != nil {
+		t.Fatalf("IsMerged() failed: %v", err)
+	}
+	if !merged {
+		t.Error("Expected merged-branch to be merged")
+	}
+
+	merged, err = repo.IsMerged("unmerged-branch", base)
+	if err != nil {
+		t.Fatalf("IsMerged() failed: %v", err)
+	}
+	if merged {
+		t.Error("Expected unmerged-branch not to be merged")
+	}
+
+	if _, err := repo.IsMerged("no-such-branch", base); err == nil {
+		t.Error("Expected error for unknown branch")
+	}
+}
+
+func TestDeleteBranch(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	repo, _ := NewRepository(repoDir)
+
+	base := strings.TrimSpace(runGit(t, repoDir, "rev-parse", "--abbrev-ref", "HEAD"))
+
+	runGit(t, repoDir, "checkout", "-b", "unmerged-branch")
+	writeFile(t, filepath.Join(repoDir, "new.txt"), "new\n")
+	runGit(t, repoDir, "add", "new.txt")
+	runGit(t, repoDir, "commit", "-m", "Unmerged work")
+	runGit(t, repoDir, "checkout", base)
+
+	// Unmerged branch is refused without force
+	err := repo.DeleteBranch("unmerged-branch", false)
+	if err == nil {
+		t.Fatal("Expected error deleting unmerged branch")
+	}
+	if yerr, ok := err.(*errors.Error); !ok || yerr.Code != errors.ErrPolicy {
+		t.Errorf("Expected ErrPolicy, got %v", err)
+	}
+
+	// Force deletes it
+	if err := repo.DeleteBranch("unmerged-branch", true); err != nil {
+		t.Fatalf("DeleteBranch(force) failed: %v", err)
+	}
+	if _, err := repo.ResolveRef("refs/heads/unmerged-branch"); err == nil {
+		t.Error("Branch should have been deleted")
+	}
+
+	// Branch checked out in a worktree is refused even with force
+	runGit(t, repoDir, "branch", "wt-branch")
+	wtDir := filepath.Join(t.TempDir(), "wt")
+	if err := repo.AddWorktree(wtDir, "wt-branch", AddOptions{}); err != nil {
+		t.Fatalf("Failed to add worktree: %v", err)
+	}
+
+	err = repo.DeleteBranch("wt-branch", true)
+	if err == nil {
+		t.Fatal("Expected error deleting checked-out branch")
+	}
+	if yerr, ok := err.(*errors.Error); !ok || yerr.Code != errors.ErrConflict {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+}
+
+func TestStashAndApply(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	repo, _ := NewRepository(repoDir)
+
+	// Nothing to stash yields no SHA
+	sha, err := repo.Stash(repoDir, "empty")
+	if err != nil {
+		t.Fatalf("Stash() on clean tree failed: %v", err)
+	}
+	if sha != "" {
+		t.Errorf("Stash() on clean tree = %q, want empty", sha)
+	}
+
+	writeFile(t, filepath.Join(repoDir, "README.md"), "# Changed\n")
+	sha, err = repo.Stash(repoDir, "save work")
+	if err != nil {
+		t.Fatalf("Stash() failed: %v", err)
+	}
+	if len(sha) != 40 {
+		t.Fatalf("Stash() = %q, want a commit SHA", sha)
+	}
+	if status := runGit(t, repoDir, "status", "--porcelain"); status != "" {
+		t.Fatalf("Tree should be clean after stash, got %q", status)
+	}
+
+	// The SHA still applies after the stash is dropped
+	runGit(t, repoDir, "stash", "drop")
+	if err := repo.ApplyStash(repoDir, sha); err != nil {
+		t.Fatalf("ApplyStash() failed: %v", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(repoDir, "README.md"))
+	if string(content) != "# Changed\n" {
+		t.Errorf("README.md = %q after ApplyStash", content)
+	}
+}
+
+func TestWIPCommitAndReset(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	repo, _ := NewRepository(repoDir)
+	base := strings.TrimSpace(runGit(t, repoDir, "rev-parse", "HEAD"))
+
+	writeFile(t, filepath.Join(repoDir, "wip.txt"), "wip\n")
+	sha, err := repo.CreateWIPCommit(repoDir, "WIP")
+	if err != nil {
+		t.Fatalf("CreateWIPCommit() failed: %v", err)
+	}
+	if head := strings.TrimSpace(runGit(t, repoDir, "rev-parse", "HEAD")); head != sha {
+		t.Errorf("CreateWIPCommit() = %q, HEAD is %q", sha, head)
+	}
+
+	if err := repo.ResetMixed(repoDir, sha+"^"); err != nil {
+		t.Fatalf("ResetMixed() failed: %v", err)
+	}
+	if head := strings.TrimSpace(runGit(t, repoDir, "rev-parse", "HEAD")); head != base {
+		t.Errorf("HEAD = %q after reset, want %q", head, base)
+	}
+	if status := runGit(t, repoDir, "status", "--porcelain"); !strings.Contains(status, "wip.txt") {
+		t.Errorf("WIP changes should be back in the working tree, status %q", status)
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	repo, _ := NewRepository(repoDir)
+
+	writeFile(t, filepath.Join(repoDir, "README.md"), "# Patched\n")
+	patchFile := filepath.Join(t.TempDir(), "changes.patch")
+	if err := repo.CreatePatch(repoDir, patchFile); err != nil {
+		t.Fatalf("CreatePatch() failed: %v", err)
+	}
+	runGit(t, repoDir, "checkout", "--", "README.md")
+
+	if err := repo.ApplyPatch(repoDir, patchFile); err != nil {
+		t.Fatalf("ApplyPatch() failed: %v", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(repoDir, "README.md"))
+	if string(content) != "# Patched\n" {
+		t.Errorf("README.md = %q after ApplyPatch", content)
+	}
+}
+
+func TestDetachAttachWorktree(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	repo, _ := NewRepository(repoDir)
+
+	runGit(t, repoDir, "branch", "trash-branch")
+	base := t.TempDir()
+	wtDir := filepath.Join(base, "wt")
+	trashDir := filepath.Join(base, "trash", "wt")
+	if err := repo.AddWorktree(wtDir, "trash-branch", AddOptions{}); err != nil {
+		t.Fatalf("AddWorktree() failed: %v", err)
+	}
+	writeFile(t, filepath.Join(wtDir, "README.md"), "# Uncommitted\n")
+	writeFile(t, filepath.Join(wtDir, "untracked.txt"), "keep me\n")
+
+	if err := repo.DetachWorktree(wtDir, trashDir); err != nil {
+		t.Fatalf("DetachWorktree() failed: %v", err)
+	}
+	if _, err := os.Stat(wtDir); !os.IsNotExist(err) {
+		t.Error("Worktree directory should have moved")
+	}
+	worktrees, _ := repo.ListWorktrees()
+	if len(worktrees) != 1 {
+		t.Errorf("Detached worktree should be unregistered, got %d worktrees", len(worktrees))
+	}
+
+	// The branch is free again, so it can be deleted and recreated
+	if err := repo.AttachWorktree(trashDir, wtDir, "trash-branch", AddOptions{}); err != nil {
+		t.Fatalf("AttachWorktree() failed: %v", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(wtDir, "README.md"))
+	if string(content) != "# Uncommitted\n" {
+		t.Errorf("README.md = %q after attach", content)
+	}
+	status := runGit(t, wtDir, "status", "--porcelain")
+	if !strings.Contains(status, " M README.md") || !strings.Contains(status, "?? untracked.txt") {
+		t.Errorf("Unexpected status after attach: %q", status)
+	}
+	if branch := strings.TrimSpace(runGit(t, wtDir, "branch", "--show-current")); branch != "trash-branch" {
+		t.Errorf("Branch = %q, want trash-branch", branch)
+	}
+}
+
+func TestUpdateDeleteRef(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	repo, _ := NewRepository(repoDir)
+	head := strings.TrimSpace(runGit(t, repoDir, "rev-parse", "HEAD"))
+
+	if err := repo.UpdateRef("refs/yagwt/trash/test", head); err != nil {
+		t.Fatalf("UpdateRef() failed: %v", err)
+	}
+	if sha, err := repo.ResolveRef("refs/yagwt/trash/test"); err != nil || sha != head {
+		t.Errorf("ResolveRef() = %q, %v; want %q", sha, err, head)
+	}
+
+	if err := repo.DeleteRef("refs/yagwt/trash/test"); err != nil {
+		t.Fatalf("DeleteRef() failed: %v", err)
+	}
+	if _, err := repo.ResolveRef("refs/yagwt/trash/test"); err == nil {
+		t.Error("Ref should be deleted")
+	}
+}
+
+func TestSparseCheckout(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	repo, _ := NewRepository(repoDir)
+
+	for _, dir := range []string{"web", "api"} {
+		if err := os.MkdirAll(filepath.Join(repoDir, "apps", dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(repoDir, "apps", "web", "index.html"), "web\n")
+	writeFile(t, filepath.Join(repoDir, "apps", "api", "main.go"), "api\n")
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-m", "Add apps")
+
+	if patterns, err := repo.SparseCheckoutList(repoDir); err != nil || patterns != nil {
+		t.Errorf("SparseCheckoutList() on a full checkout = %v, %v; want nil", patterns, err)
+	}
+
+	wtDir := filepath.Join(t.TempDir(), "sparse")
+	if err := repo.AddWorktree(wtDir, "HEAD", AddOptions{Detach: true, NoCheckout: true}); err != nil {
+		t.Fatalf("AddWorktree() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(wtDir, "README.md")); !os.IsNotExist(err) {
+		t.Error("NoCheckout worktree should have no files")
+	}
+
+	if err := repo.SparseCheckoutSet(wtDir, []string{"apps/web"}, true); err != nil {
+		t.Fatalf("SparseCheckoutSet() failed: %v", err)
+	}
+	if err := repo.PopulateWorktree(wtDir); err != nil {
+		t.Fatalf("PopulateWorktree() failed: %v", err)
+	}
+
+	for path, want := range map[string]bool{
+		"README.md":           true, // top-level files are always included in cone mode
+		"apps/web/index.html": true,
+		"apps/api/main.go":    false,
+	} {
+		_, err := os.Stat(filepath.Join(wtDir, path))
+		if got := err == nil; got != want {
+			t.Errorf("%s present = %v, want %v", path, got, want)
+		}
+	}
+
+	patterns, err := repo.SparseCheckoutList(wtDir)
+	if err != nil || len(patterns) != 1 || patterns[0] != "apps/web" {
+		t.Errorf("SparseCheckoutList() = %v, %v; want [apps/web]", patterns, err)
+	}
+	if status := runGit(t, wtDir, "status", "--porcelain"); status != "" {
+		t.Errorf("Sparse worktree should be clean, got %q", status)
+	}
+
+	// The main worktree stays a full checkout
+	if patterns, _ := repo.SparseCheckoutList(repoDir); patterns != nil {
+		t.Errorf("Main worktree became sparse: %v", patterns)
+	}
+}
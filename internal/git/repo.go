@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bmf/yagwt/internal/errors"
 )
@@ -18,41 +19,86 @@ type Repository interface {
 	ListWorktrees() ([]Worktree, error)
 	AddWorktree(path, ref string, opts AddOptions) error
 	RemoveWorktree(path string, force bool) error
+	MoveWorktree(path, newPath string) error
+	DetachWorktree(path, dest string) error
+	AttachWorktree(dir, path, ref string, opts AddOptions) error
+
+	// Sparse checkout. Patterns are directories in cone mode and
+	// gitignore-style patterns otherwise. SparseCheckoutList returns nil
+	// for a worktree that is not sparse.
+	SparseCheckoutSet(path string, patterns []string, cone bool) error
+	SparseCheckoutList(path string) ([]string, error)
+	PopulateWorktree(path string) error
+
+	// Submodules and Git LFS. LFSPull needs the git-lfs extension and
+	// fetches only paths matching include.
+	UpdateSubmodules(path string, recursive bool) error
+	LFSPull(path string, include []string) error
 
 	// Status operations
 	GetStatus(path string) (Status, error)
+	LastActivity(path string) (time.Time, error)
 
 	// Reference operations
 	ResolveRef(ref string) (string, error) // Returns full SHA
 	GetBranch(ref string) (Branch, error)
-
-	// Dirty workspace operations
-	Stash(path, message string) error
+	IsMerged(branch, into string) (bool, error)
+	DeleteBranch(name string, force bool) error
+	UpdateRef(ref, sha string) error
+	DeleteRef(ref string) error
+
+	// Dirty workspace operations. Stash and CreateWIPCommit return the SHA
+	// of the commit they created so the changes can be found again.
+	Stash(path, message string) (string, error)
 	CreatePatch(path, patchFile string) error
-	CreateWIPCommit(path, message string) error
-
-	// Repository info
+	CreateWIPCommit(path, message string) (string, error)
+
+	// Restoring saved changes
+	ApplyStash(path, stash string) error
+	ApplyPatch(path, patchFile string) error
+	ResetMixed(path, ref string) error
+
+	// Repository info. GitDir is the git directory of the worktree the
+	// repository was opened from (.git/worktrees/<name> in a linked
+	// worktree); CommonDir is the directory shared by all worktrees. Root
+	// is the top level of the main worktree, whichever worktree the
+	// repository was opened from, or CommonDir for a bare repository.
+	// IsBare is also true in the linked worktrees of a bare repository.
 	Root() string
 	GitDir() string
+	CommonDir() string
+	IsBare() bool
 }
 
 // Worktree represents a git worktree
 type Worktree struct {
 	Path     string
-	HEAD     string // SHA
+	HEAD     string // SHA; empty for a bare repository
 	Branch   string // Empty if detached
+	Bare     bool   // The bare repository itself, not a checkout
+	Main     bool   // The main worktree rather than a linked one
 	Locked   bool
 	Prunable bool
 }
 
 // Status represents git status output
 type Status struct {
-	Dirty     bool
-	Conflicts bool
-	Branch    string
-	Detached  bool
-	Ahead     int
-	Behind    int
+	Dirty         bool
+	Conflicts     bool
+	Branch        string
+	Detached      bool
+	Ahead         int
+	Behind        int
+	Submodules    []SubmoduleStatus // submodules with changes
+	SubmoduleOnly bool              // Dirty only because of Submodules
+}
+
+// SubmoduleStatus describes the changes in one submodule
+type SubmoduleStatus struct {
+	Path          string
+	CommitChanged bool // checked out at a different commit than recorded
+	Modified      bool // tracked files changed inside the submodule
+	Untracked     bool // untracked files inside the submodule
 }
 
 // Branch represents a git branch
@@ -64,24 +110,32 @@ type Branch struct {
 
 // AddOptions specifies options for adding a worktree
 type AddOptions struct {
-	NewBranch bool
-	Detach    bool
-	Force     bool
-	Checkout  bool
-	Track     string // Upstream branch for --track
-	Base      string // Base commit/branch when creating new branch
+	NewBranch  bool
+	Detach     bool
+	Force      bool
+	NoCheckout bool   // register the worktree without populating its files
+	Track      string // Upstream branch for --track
+	Base       string // Base commit/branch when creating new branch
 }
 
 // repo implements Repository interface
 type repo struct {
-	root   string
-	gitDir string
+	root      string
+	gitDir    string
+	commonDir string
+	bare      bool
 }
 
 // NewRepository creates a new Repository for the given path
 func NewRepository(path string) (Repository, error) {
-	// Find the repository root
-	cmd := exec.Command("git", "-C", path, "rev-parse", "--show-toplevel")
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, errors.WrapError(errors.ErrGit, "failed to resolve path", err).
+			WithDetail("path", path)
+	}
+
+	// Find the per-worktree and shared git directories
+	cmd := exec.Command("git", "-C", absPath, "rev-parse", "--is-bare-repository", "--git-dir", "--git-common-dir")
 	output, err := cmd.Output()
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
@@ -96,26 +150,70 @@ func NewRepository(path string) (Repository, error) {
 			WithDetail("path", path)
 	}
 
-	root := strings.TrimSpace(string(output))
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 3 {
+		return nil, errors.NewError(errors.ErrGit, "unexpected git rev-parse output").
+			WithDetail("path", path).
+			WithDetail("output", string(output))
+	}
 
-	// Find the git directory
-	cmd = exec.Command("git", "-C", root, "rev-parse", "--git-dir")
-	output, err = cmd.Output()
-	if err != nil {
-		return nil, errors.WrapError(errors.ErrGit, "failed to find git directory", err).
-			WithDetail("root", root)
+	// Relative directories are relative to the path git ran in
+	dirs := lines[1:]
+	for i, dir := range dirs {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(absPath, dir)
+		}
+		dirs[i] = filepath.Clean(dir)
 	}
 
-	gitDir := strings.TrimSpace(string(output))
-	// Make gitDir absolute if it's relative
-	if !filepath.IsAbs(gitDir) {
-		gitDir = filepath.Join(root, gitDir)
+	r := &repo{
+		gitDir:    dirs[0],
+		commonDir: dirs[1],
+		bare:      lines[0] == "true",
 	}
 
-	return &repo{
-		root:   root,
-		gitDir: gitDir,
-	}, nil
+	// The root is the main worktree whichever worktree we were opened from,
+	// so config, hooks and derived paths don't depend on the current
+	// directory. From a linked worktree, git lists the main worktree first.
+	// With --separate-git-dir git only knows its git dir, so there the
+	// current worktree has to do.
+	top := absPath
+	if !r.bare && !sameDir(r.gitDir, r.commonDir) {
+		cmd = exec.Command("git", "-C", absPath, "worktree", "list", "--porcelain")
+		output, err = cmd.Output()
+		if err != nil {
+			return nil, errors.WrapError(errors.ErrGit, "failed to list worktrees", err).
+				WithDetail("path", path)
+		}
+		worktrees, err := parseWorktreeList(output)
+		if err != nil {
+			return nil, err
+		}
+		if len(worktrees) > 0 {
+			switch main := worktrees[0]; {
+			case main.Bare:
+				r.bare = true
+			case !sameDir(main.Path, r.commonDir):
+				top = main.Path
+			}
+		}
+	}
+
+	// A bare repository has no working tree; its git dir stands in for the root
+	if r.bare {
+		r.root = r.commonDir
+		return r, nil
+	}
+
+	cmd = exec.Command("git", "-C", top, "rev-parse", "--show-toplevel")
+	output, err = cmd.Output()
+	if err != nil {
+		return nil, errors.WrapError(errors.ErrGit, "failed to find repository root", err).
+			WithDetail("path", path)
+	}
+	r.root = strings.TrimSpace(string(output))
+
+	return r, nil
 }
 
 // ListWorktrees lists all worktrees
@@ -126,7 +224,36 @@ func (r *repo) ListWorktrees() ([]Worktree, error) {
 		return nil, errors.WrapError(errors.ErrGit, "failed to list worktrees", err)
 	}
 
-	return parseWorktreeList(output)
+	worktrees, err := parseWorktreeList(output)
+	if err != nil {
+		return nil, err
+	}
+
+	// git lists the main worktree first; for a bare repository that entry
+	// is the repository itself and there is no main worktree. With
+	// --separate-git-dir the main worktree's .git is a file and git reports
+	// the git dir as its path, so correct it when we were opened from there.
+	if len(worktrees) > 0 && !worktrees[0].Bare {
+		worktrees[0].Main = true
+		if sameDir(r.gitDir, r.commonDir) {
+			worktrees[0].Path = r.root
+		}
+	}
+
+	return worktrees, nil
+}
+
+// sameDir reports whether two paths name the same directory
+func sameDir(a, b string) bool {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(infoA, infoB)
 }
 
 // parseWorktreeList parses the porcelain output of git worktree list
@@ -177,6 +304,10 @@ func parseWorktreeList(output []byte) ([]Worktree, error) {
 			if current != nil {
 				current.Branch = ""
 			}
+		case "bare":
+			if current != nil {
+				current.Bare = true
+			}
 		case "locked":
 			if current != nil {
 				current.Locked = true
@@ -212,6 +343,10 @@ func (r *repo) AddWorktree(path, ref string, opts AddOptions) error {
 		args = append(args, "--detach")
 	}
 
+	if opts.NoCheckout {
+		args = append(args, "--no-checkout")
+	}
+
 	if opts.NewBranch {
 		// git worktree add -b <new-branch> <path> [<commit-ish>]
 		args = append(args, "-b", ref, path)
@@ -241,6 +376,109 @@ func (r *repo) AddWorktree(path, ref string, opts AddOptions) error {
 	return nil
 }
 
+// SparseCheckoutSet makes the worktree at path sparse with the given
+// patterns, replacing any it had, and updates its files to match
+func (r *repo) SparseCheckoutSet(path string, patterns []string, cone bool) error {
+	mode := "--cone"
+	if !cone {
+		mode = "--no-cone"
+	}
+
+	// Patterns go through stdin so ones starting with "-" are not flags
+	cmd := exec.Command("git", "-C", path, "sparse-checkout", "set", mode, "--stdin")
+	cmd.Stdin = strings.NewReader(strings.Join(patterns, "\n") + "\n")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return errors.WrapError(errors.ErrGit, "failed to set sparse-checkout patterns", err).
+			WithDetail("path", path).
+			WithDetail("stderr", stderr.String())
+	}
+
+	return nil
+}
+
+// SparseCheckoutList returns the sparse-checkout patterns of the worktree at
+// path, or nil if it is not sparse
+func (r *repo) SparseCheckoutList(path string) ([]string, error) {
+	cmd := exec.Command("git", "-C", path, "sparse-checkout", "list")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if strings.Contains(stderr.String(), "not sparse") {
+			return nil, nil
+		}
+		return nil, errors.WrapError(errors.ErrGit, "failed to list sparse-checkout patterns", err).
+			WithDetail("path", path).
+			WithDetail("stderr", stderr.String())
+	}
+
+	var patterns []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			patterns = append(patterns, line)
+		}
+	}
+	return patterns, nil
+}
+
+// PopulateWorktree checks out HEAD into a worktree added with NoCheckout,
+// honoring its sparse-checkout patterns
+func (r *repo) PopulateWorktree(path string) error {
+	cmd := exec.Command("git", "-C", path, "read-tree", "-mu", "HEAD")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return errors.WrapError(errors.ErrGit, "failed to check out worktree", err).
+			WithDetail("path", path).
+			WithDetail("stderr", stderr.String())
+	}
+
+	return nil
+}
+
+// UpdateSubmodules initializes and checks out the submodules of the worktree
+// at path at their recorded commits
+func (r *repo) UpdateSubmodules(path string, recursive bool) error {
+	args := []string{"-C", path, "submodule", "update", "--init"}
+	if recursive {
+		args = append(args, "--recursive")
+	}
+
+	cmd := exec.Command("git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return errors.WrapError(errors.ErrGit, "failed to update submodules", err).
+			WithDetail("path", path).
+			WithDetail("stderr", stderr.String())
+	}
+
+	return nil
+}
+
+// LFSPull downloads the Git LFS objects matching include and replaces the
+// pointer files in the worktree at path with their contents
+func (r *repo) LFSPull(path string, include []string) error {
+	cmd := exec.Command("git", "-C", path, "lfs", "pull", "--include="+strings.Join(include, ","))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return errors.WrapError(errors.ErrGit, "failed to pull Git LFS files", err).
+			WithDetail("path", path).
+			WithDetail("stderr", stderr.String()).
+			WithHint("Check that git-lfs is installed", "git lfs install")
+	}
+
+	return nil
+}
+
 // RemoveWorktree removes a worktree
 func (r *repo) RemoveWorktree(path string, force bool) error {
 	args := []string{"-C", r.root, "worktree", "remove"}
@@ -279,9 +517,144 @@ func (r *repo) RemoveWorktree(path string, force bool) error {
 	return nil
 }
 
+// MoveWorktree moves a worktree to a new location
+func (r *repo) MoveWorktree(path, newPath string) error {
+	cmd := exec.Command("git", "-C", r.root, "worktree", "move", path, newPath)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		errMsg := stderr.String()
+
+		// Check for specific error conditions
+		if strings.Contains(errMsg, "is locked") {
+			return errors.NewError(errors.ErrLocked, "worktree is locked").
+				WithDetail("path", path).
+				WithHint("Unlock the worktree first", "git worktree unlock "+path)
+		}
+
+		if strings.Contains(errMsg, "already exists") {
+			return errors.NewError(errors.ErrConflict, "target path already exists").
+				WithDetail("path", path).
+				WithDetail("newPath", newPath).
+				WithHint("Choose a different directory", "")
+		}
+
+		return errors.WrapError(errors.ErrGit, "failed to move worktree", err).
+			WithDetail("path", path).
+			WithDetail("newPath", newPath).
+			WithDetail("stderr", errMsg)
+	}
+
+	return nil
+}
+
+// DetachWorktree moves the worktree at path to dest and unregisters it
+// from git. Every file, including uncommitted changes, is kept; the index
+// is dropped with the administrative directory. Worktrees with initialized
+// submodules are refused, since their git dirs live in the administrative
+// directory. On failure the worktree is left at path.
+func (r *repo) DetachWorktree(path, dest string) error {
+	adminDir, err := worktreeAdminDir(path)
+	if err != nil {
+		return err
+	}
+
+	if entries, _ := os.ReadDir(filepath.Join(adminDir, "modules")); len(entries) > 0 {
+		return errors.NewError(errors.ErrConflict, "worktree has initialized submodules").
+			WithDetail("path", path).
+			WithDetail("adminDir", adminDir)
+	}
+
+	gitFile := filepath.Join(path, ".git")
+	gitFileData, err := os.ReadFile(gitFile)
+	if err != nil {
+		return errors.WrapError(errors.ErrGit, "failed to read .git file", err).
+			WithDetail("path", path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return errors.WrapError(errors.ErrGit, "failed to create directory", err).
+			WithDetail("path", filepath.Dir(dest))
+	}
+
+	if err := os.Rename(path, dest); err != nil {
+		return errors.WrapError(errors.ErrGit, "failed to move worktree directory", err).
+			WithDetail("path", path).
+			WithDetail("dest", dest)
+	}
+
+	// The .git file points at the admin directory removed below
+	os.Remove(filepath.Join(dest, ".git"))
+
+	if err := os.RemoveAll(adminDir); err != nil {
+		// Put the worktree back; git worktree repair can fix a partially
+		// removed admin directory, a lost worktree cannot be found again
+		os.WriteFile(filepath.Join(dest, ".git"), gitFileData, 0644)
+		os.Rename(dest, path)
+		return errors.WrapError(errors.ErrGit, "failed to unregister worktree", err).
+			WithDetail("path", path).
+			WithDetail("adminDir", adminDir)
+	}
+
+	return nil
+}
+
+// AttachWorktree registers dir, a directory detached by DetachWorktree,
+// as a worktree at path checked out to ref. Files in dir are kept as they
+// are; only the index is rebuilt from ref.
+func (r *repo) AttachWorktree(dir, path, ref string, opts AddOptions) error {
+	opts.NoCheckout = true
+	if err := r.AddWorktree(path, ref, opts); err != nil {
+		return err
+	}
+
+	// Swap the empty checkout for dir, keeping the new .git file
+	gitFile := filepath.Join(path, ".git")
+	if err := os.Rename(gitFile, filepath.Join(dir, ".git")); err != nil {
+		return errors.WrapError(errors.ErrGit, "failed to attach worktree", err).
+			WithDetail("dir", dir).
+			WithDetail("path", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return errors.WrapError(errors.ErrGit, "failed to attach worktree", err).
+			WithDetail("path", path)
+	}
+	if err := os.Rename(dir, path); err != nil {
+		return errors.WrapError(errors.ErrGit, "failed to move worktree directory", err).
+			WithDetail("dir", dir).
+			WithDetail("path", path)
+	}
+
+	return r.ResetMixed(path, "HEAD")
+}
+
+// worktreeAdminDir returns the administrative directory of a linked
+// worktree from the gitdir line of its .git file
+func worktreeAdminDir(path string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(path, ".git"))
+	if err != nil {
+		return "", errors.WrapError(errors.ErrGit, "not a linked worktree", err).
+			WithDetail("path", path)
+	}
+
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return "", errors.NewError(errors.ErrGit, "malformed .git file").
+			WithDetail("path", path)
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(path, gitDir)
+	}
+
+	return filepath.Clean(gitDir), nil
+}
+
 // GetStatus returns the git status for a path
 func (r *repo) GetStatus(path string) (Status, error) {
-	cmd := exec.Command("git", "-C", path, "status", "--porcelain=v2", "--branch")
+	// --no-optional-locks keeps status from rewriting the index, which
+	// would otherwise look like activity to LastActivity
+	cmd := exec.Command("git", "--no-optional-locks", "-C", path, "status", "--porcelain=v2", "--branch")
 	output, err := cmd.Output()
 	if err != nil {
 		return Status{}, errors.WrapError(errors.ErrGit, "failed to get status", err).
@@ -294,6 +667,7 @@ func (r *repo) GetStatus(path string) (Status, error) {
 // parseStatusV2 parses git status --porcelain=v2 output
 func parseStatusV2(output []byte) (Status, error) {
 	var status Status
+	filesDirty := false
 
 	scanner := bufio.NewScanner(bytes.NewReader(output))
 	for scanner.Scan() {
@@ -321,15 +695,23 @@ func parseStatusV2(output []byte) (Status, error) {
 				}
 			}
 		} else if strings.HasPrefix(line, "1 ") || strings.HasPrefix(line, "2 ") {
-			// Modified file: "1 .M N... ..."
+			// Modified file: "1 .M N... ..."; the third field is "S<c><m><u>"
+			// for a submodule
 			status.Dirty = true
+			if sub, ok := parseSubmoduleEntry(line); ok {
+				status.Submodules = append(status.Submodules, sub)
+			} else {
+				filesDirty = true
+			}
 		} else if strings.HasPrefix(line, "? ") {
 			// Untracked file
 			status.Dirty = true
+			filesDirty = true
 		} else if strings.HasPrefix(line, "u ") {
 			// Unmerged (conflict)
 			status.Conflicts = true
 			status.Dirty = true
+			filesDirty = true
 		}
 	}
 
@@ -337,9 +719,105 @@ func parseStatusV2(output []byte) (Status, error) {
 		return Status{}, errors.WrapError(errors.ErrGit, "failed to parse status output", err)
 	}
 
+	status.SubmoduleOnly = status.Dirty && !filesDirty
 	return status, nil
 }
 
+// parseSubmoduleEntry parses an ordinary or renamed status v2 entry if it
+// is for a submodule. The path is the last field; spaces in it are kept.
+func parseSubmoduleEntry(line string) (SubmoduleStatus, bool) {
+	n := 9 // "1 XY sub mH mI mW hH hI path"
+	if line[0] == '2' {
+		n = 10 // "2 XY sub mH mI mW hH hI Xscore path<TAB>origPath"
+	}
+	fields := strings.SplitN(line, " ", n)
+	if len(fields) < n || len(fields[2]) != 4 || fields[2][0] != 'S' {
+		return SubmoduleStatus{}, false
+	}
+
+	path, _, _ := strings.Cut(fields[n-1], "\t")
+	sub := fields[2]
+	return SubmoduleStatus{
+		Path:          path,
+		CommitChanged: sub[1] == 'C',
+		Modified:      sub[2] == 'M',
+		Untracked:     sub[3] == 'U',
+	}, true
+}
+
+// LastActivity returns the time of the most recent activity in a worktree:
+// the newest HEAD reflog entry, the index mtime or the HEAD commit time,
+// whichever is latest. A zero time means no signal was found.
+func (r *repo) LastActivity(path string) (time.Time, error) {
+	cmd := exec.Command("git", "-C", path, "rev-parse", "--git-path", "index", "--git-path", "logs/HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return time.Time{}, errors.WrapError(errors.ErrGit, "failed to locate worktree git files", err).
+			WithDetail("path", path)
+	}
+
+	var latest time.Time
+	observe := func(t time.Time) {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+
+	paths := strings.Split(strings.TrimSpace(string(output)), "\n")
+	for i, p := range paths {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(path, p)
+		}
+		if i == 0 {
+			if info, err := os.Stat(p); err == nil {
+				observe(info.ModTime())
+			}
+			continue
+		}
+		if t, ok := lastReflogTime(p); ok {
+			observe(t)
+		}
+	}
+
+	// Unborn branches have no commit; that is not an error
+	cmd = exec.Command("git", "-C", path, "log", "-1", "--format=%ct")
+	if output, err := cmd.Output(); err == nil {
+		if secs, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64); err == nil {
+			observe(time.Unix(secs, 0))
+		}
+	}
+
+	return latest, nil
+}
+
+// lastReflogTime reads the timestamp of the last entry in a reflog file
+func lastReflogTime(path string) (time.Time, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	return parseReflogTime(lines[len(lines)-1])
+}
+
+// parseReflogTime extracts the timestamp from a reflog line:
+// "<old> <new> Name <email> <unix-time> <tz>\t<message>"
+func parseReflogTime(line string) (time.Time, bool) {
+	header, _, _ := strings.Cut(line, "\t")
+	fields := strings.Fields(header)
+	if len(fields) < 4 {
+		return time.Time{}, false
+	}
+
+	secs, err := strconv.ParseInt(fields[len(fields)-2], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(secs, 0), true
+}
+
 // ResolveRef resolves a ref to a full SHA
 func (r *repo) ResolveRef(ref string) (string, error) {
 	cmd := exec.Command("git", "-C", r.root, "rev-parse", "--verify", ref)
@@ -402,21 +880,119 @@ func (r *repo) GetBranch(ref string) (Branch, error) {
 	return branch, nil
 }
 
-// Stash creates a stash with a message
-func (r *repo) Stash(path, message string) error {
+// IsMerged reports whether every commit on branch is reachable from into
+func (r *repo) IsMerged(branch, into string) (bool, error) {
+	cmd := exec.Command("git", "-C", r.root, "merge-base", "--is-ancestor", branch, into)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, errors.WrapError(errors.ErrGit, "failed to check merge status", err).
+			WithDetail("branch", branch).
+			WithDetail("into", into).
+			WithDetail("stderr", stderr.String())
+	}
+
+	return true, nil
+}
+
+// DeleteBranch deletes a local branch. Without force, git refuses to delete
+// a branch that is not merged into its upstream or HEAD.
+func (r *repo) DeleteBranch(name string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+
+	cmd := exec.Command("git", "-C", r.root, "branch", flag, name)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		errMsg := stderr.String()
+
+		if strings.Contains(errMsg, "not found") {
+			return errors.NewError(errors.ErrNotFound, "branch not found").
+				WithDetail("branch", name)
+		}
+
+		if strings.Contains(errMsg, "checked out at") || strings.Contains(errMsg, "used by worktree") {
+			return errors.NewError(errors.ErrConflict, "branch is checked out in another worktree").
+				WithDetail("branch", name).
+				WithDetail("stderr", errMsg)
+		}
+
+		if strings.Contains(errMsg, "not fully merged") {
+			return errors.NewError(errors.ErrPolicy, "branch is not fully merged").
+				WithDetail("branch", name).
+				WithHint("Force deletion to discard its commits", "git branch -D "+name)
+		}
+
+		return errors.WrapError(errors.ErrGit, "failed to delete branch", err).
+			WithDetail("branch", name).
+			WithDetail("stderr", errMsg)
+	}
+
+	return nil
+}
+
+// UpdateRef points ref at sha, creating it if needed
+func (r *repo) UpdateRef(ref, sha string) error {
+	cmd := exec.Command("git", "-C", r.root, "update-ref", ref, sha)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return errors.WrapError(errors.ErrGit, "failed to update ref", err).
+			WithDetail("ref", ref).
+			WithDetail("sha", sha).
+			WithDetail("stderr", stderr.String())
+	}
+
+	return nil
+}
+
+// DeleteRef deletes ref
+func (r *repo) DeleteRef(ref string) error {
+	cmd := exec.Command("git", "-C", r.root, "update-ref", "-d", ref)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return errors.WrapError(errors.ErrGit, "failed to delete ref", err).
+			WithDetail("ref", ref).
+			WithDetail("stderr", stderr.String())
+	}
+
+	return nil
+}
+
+// Stash creates a stash with a message and returns the stash commit SHA,
+// or "" if there was nothing to stash
+func (r *repo) Stash(path, message string) (string, error) {
+	before, _ := headOf(path, "refs/stash")
+
 	cmd := exec.Command("git", "-C", path, "stash", "push", "-m", message)
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
 		errMsg := stderr.String()
-		return errors.WrapError(errors.ErrGit, "failed to stash changes", err).
+		return "", errors.WrapError(errors.ErrGit, "failed to stash changes", err).
 			WithDetail("path", path).
 			WithDetail("message", message).
 			WithDetail("stderr", errMsg)
 	}
 
-	return nil
+	after, err := headOf(path, "refs/stash")
+	if err != nil || after == before {
+		return "", nil
+	}
+
+	return after, nil
 }
 
 // CreatePatch creates a patch file with all uncommitted changes
@@ -452,8 +1028,8 @@ func (r *repo) CreatePatch(path, patchFile string) error {
 	return nil
 }
 
-// CreateWIPCommit creates a WIP commit with all changes
-func (r *repo) CreateWIPCommit(path, message string) error {
+// CreateWIPCommit creates a WIP commit with all changes and returns its SHA
+func (r *repo) CreateWIPCommit(path, message string) (string, error) {
 	// Add all changes
 	cmd := exec.Command("git", "-C", path, "add", "-A")
 	var stderr bytes.Buffer
@@ -461,7 +1037,7 @@ func (r *repo) CreateWIPCommit(path, message string) error {
 
 	if err := cmd.Run(); err != nil {
 		errMsg := stderr.String()
-		return errors.WrapError(errors.ErrGit, "failed to add changes", err).
+		return "", errors.WrapError(errors.ErrGit, "failed to add changes", err).
 			WithDetail("path", path).
 			WithDetail("stderr", errMsg)
 	}
@@ -473,21 +1049,94 @@ func (r *repo) CreateWIPCommit(path, message string) error {
 
 	if err := cmd.Run(); err != nil {
 		errMsg := stderr.String()
-		return errors.WrapError(errors.ErrGit, "failed to create WIP commit", err).
+		return "", errors.WrapError(errors.ErrGit, "failed to create WIP commit", err).
 			WithDetail("path", path).
 			WithDetail("message", message).
 			WithDetail("stderr", errMsg)
 	}
 
+	return headOf(path, "HEAD")
+}
+
+// ApplyStash applies a stash commit to the worktree at path without
+// dropping it from the stash list
+func (r *repo) ApplyStash(path, stash string) error {
+	cmd := exec.Command("git", "-C", path, "stash", "apply", stash)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return errors.WrapError(errors.ErrGit, "failed to apply stash", err).
+			WithDetail("path", path).
+			WithDetail("stash", stash).
+			WithDetail("stderr", stderr.String())
+	}
+
+	return nil
+}
+
+// ApplyPatch applies a patch file to the worktree at path
+func (r *repo) ApplyPatch(path, patchFile string) error {
+	cmd := exec.Command("git", "-C", path, "apply", patchFile)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return errors.WrapError(errors.ErrGit, "failed to apply patch", err).
+			WithDetail("path", path).
+			WithDetail("patchFile", patchFile).
+			WithDetail("stderr", stderr.String())
+	}
+
+	return nil
+}
+
+// ResetMixed moves the worktree's HEAD to ref, keeping changes in the
+// working tree
+func (r *repo) ResetMixed(path, ref string) error {
+	cmd := exec.Command("git", "-C", path, "reset", "--mixed", "-q", ref)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return errors.WrapError(errors.ErrGit, "failed to reset worktree", err).
+			WithDetail("path", path).
+			WithDetail("ref", ref).
+			WithDetail("stderr", stderr.String())
+	}
+
 	return nil
 }
 
+// headOf resolves ref in the worktree at path
+func headOf(path, ref string) (string, error) {
+	cmd := exec.Command("git", "-C", path, "rev-parse", "--verify", "-q", ref)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", errors.WrapError(errors.ErrGit, "failed to resolve ref", err).
+			WithDetail("path", path).
+			WithDetail("ref", ref)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
 // Root returns the repository root
 func (r *repo) Root() string {
 	return r.root
 }
 
-// GitDir returns the git directory
+// GitDir returns the git directory of the current worktree
 func (r *repo) GitDir() string {
 	return r.gitDir
 }
+
+// CommonDir returns the git directory shared by all worktrees
+func (r *repo) CommonDir() string {
+	return r.commonDir
+}
+
+// IsBare reports whether the repository was opened from a bare git dir
+func (r *repo) IsBare() bool {
+	return r.bare
+}
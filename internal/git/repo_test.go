@@ -1,6 +1,7 @@
 package git
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -97,6 +98,21 @@ branch refs/heads/feature-b
 				{Path: "/path/to/feature-b", HEAD: "789ghi", Branch: "feature-b"},
 			},
 		},
+		{
+			name: "bare repository with worktrees",
+			input: `worktree /path/to/repo.git
+bare
+
+worktree /path/to/feature
+HEAD abc123
+branch refs/heads/feature
+
+`,
+			expected: []Worktree{
+				{Path: "/path/to/repo.git", Bare: true},
+				{Path: "/path/to/feature", HEAD: "abc123", Branch: "feature"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -127,6 +143,9 @@ branch refs/heads/feature-b
 				if actual.Prunable != expected.Prunable {
 					t.Errorf("Worktree %d: expected prunable=%v, got %v", i, expected.Prunable, actual.Prunable)
 				}
+				if actual.Bare != expected.Bare {
+					t.Errorf("Worktree %d: expected bare=%v, got %v", i, expected.Bare, actual.Bare)
+				}
 			}
 		})
 	}
@@ -212,6 +231,36 @@ u UU N... 100644 100644 100644 100644 abc def ghi conflicted.txt
 				Dirty:  true,
 			},
 		},
+		{
+			name: "submodule changes only",
+			input: `# branch.oid abc123
+# branch.head main
+1 .M S.MU 160000 160000 160000 abc abc libs/vendored lib
+1 M. SC.. 160000 160000 160000 abc def proto
+`,
+			expected: Status{
+				Branch:        "main",
+				Dirty:         true,
+				SubmoduleOnly: true,
+				Submodules: []SubmoduleStatus{
+					{Path: "libs/vendored lib", Modified: true, Untracked: true},
+					{Path: "proto", CommitChanged: true},
+				},
+			},
+		},
+		{
+			name: "submodule and file changes",
+			input: `# branch.oid abc123
+# branch.head main
+1 .M SC.. 160000 160000 160000 abc abc proto
+1 .M N... 100644 100644 100644 abc abc README.md
+`,
+			expected: Status{
+				Branch:     "main",
+				Dirty:      true,
+				Submodules: []SubmoduleStatus{{Path: "proto", CommitChanged: true}},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -239,6 +288,56 @@ u UU N... 100644 100644 100644 100644 abc def ghi conflicted.txt
 			if result.Behind != tt.expected.Behind {
 				t.Errorf("Expected behind=%d, got %d", tt.expected.Behind, result.Behind)
 			}
+			if result.SubmoduleOnly != tt.expected.SubmoduleOnly {
+				t.Errorf("Expected submoduleOnly=%v, got %v", tt.expected.SubmoduleOnly, result.SubmoduleOnly)
+			}
+			if !reflect.DeepEqual(result.Submodules, tt.expected.Submodules) {
+				t.Errorf("Expected submodules %+v, got %+v", tt.expected.Submodules, result.Submodules)
+			}
+		})
+	}
+}
+
+func TestParseReflogTime(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   int64
+		wantOK bool
+	}{
+		{
+			name:   "commit entry",
+			line:   "0000000000000000000000000000000000000000 abc123def456abc123def456abc123def456abcd Test User <test@example.com> 1700000000 +0100\tcommit (initial): Initial commit",
+			want:   1700000000,
+			wantOK: true,
+		},
+		{
+			name:   "name with spaces",
+			line:   "abc123 def456 Jane Q Public <jane@example.com> 1700000500 -0700\tcheckout: moving from main to feature",
+			want:   1700000500,
+			wantOK: true,
+		},
+		{
+			name:   "empty line",
+			line:   "",
+			wantOK: false,
+		},
+		{
+			name:   "malformed timestamp",
+			line:   "abc123 def456 Test <t@example.com> notatime +0000\tcommit: x",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseReflogTime(tt.line)
+			if ok != tt.wantOK {
+				t.Fatalf("parseReflogTime() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && got.Unix() != tt.want {
+				t.Errorf("parseReflogTime() = %d, want %d", got.Unix(), tt.want)
+			}
 		})
 	}
 }
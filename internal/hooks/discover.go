@@ -0,0 +1,40 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// discover returns the command configured for a hook.
+//
+// Discovery order (first match wins):
+//  1. Config file setting (e.g., hooks.postCreate = ".yagwt/hooks/setup.sh")
+//  2. <repoRoot>/.yagwt/hooks/<hook-name> (executable file)
+func (e *executor) discover(hook Type) string {
+	if command := e.configured(hook); command != "" {
+		return command
+	}
+
+	script := filepath.Join(e.repoRoot, ".yagwt", "hooks", string(hook))
+	info, err := os.Stat(script)
+	if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+		return ""
+	}
+	return script
+}
+
+// configured returns the config setting for a hook
+func (e *executor) configured(hook Type) string {
+	switch hook {
+	case PostCreate:
+		return e.config.PostCreate
+	case PreRemove:
+		return e.config.PreRemove
+	case PostRemove:
+		return e.config.PostRemove
+	case PostOpen:
+		return e.config.PostOpen
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,178 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/bmf/yagwt/internal/config"
+	"github.com/bmf/yagwt/internal/errors"
+)
+
+// DefaultTimeout is used when no hook timeout is configured
+const DefaultTimeout = 5 * time.Minute
+
+// Type identifies a lifecycle hook
+type Type string
+
+const (
+	PostCreate Type = "post-create"
+	PreRemove  Type = "pre-remove"
+	PostRemove Type = "post-remove"
+	PostOpen   Type = "post-open"
+)
+
+// Context provides data to hook scripts
+type Context struct {
+	RepoRoot      string
+	WorkspaceID   string
+	WorkspacePath string
+	WorkspaceName string
+	TargetRef     string
+	Operation     string
+}
+
+// Result describes a single hook execution
+type Result struct {
+	Hook     Type
+	Command  string
+	ExitCode int
+	Stdout   string
+	Stderr   string
+	Duration time.Duration
+	TimedOut bool
+}
+
+// Executor executes lifecycle hooks
+type Executor interface {
+	// Execute runs the hook if one is configured. It returns a nil Result
+	// when nothing is configured for the hook, and an error when the hook
+	// could not be started, exited non-zero or timed out.
+	Execute(hook Type, ctx Context) (*Result, error)
+}
+
+// executor implements Executor interface
+type executor struct {
+	repoRoot string
+	config   config.HooksConfig
+	timeout  time.Duration
+}
+
+// NewExecutor creates a hook executor for a repository
+func NewExecutor(repoRoot string, cfg config.HooksConfig) Executor {
+	timeout := time.Duration(cfg.Timeout)
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &executor{
+		repoRoot: repoRoot,
+		config:   cfg,
+		timeout:  timeout,
+	}
+}
+
+// Execute runs a hook with the documented YAGWT_* environment
+func (e *executor) Execute(hook Type, hctx Context) (*Result, error) {
+	command := e.discover(hook)
+	if command == "" {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	cmd := e.buildCommand(ctx, command)
+	cmd.Env = append(os.Environ(), environment(hook, hctx)...)
+
+	// Run inside the workspace when it exists (it won't after removal)
+	cmd.Dir = e.repoRoot
+	if hctx.WorkspacePath != "" {
+		if info, err := os.Stat(hctx.WorkspacePath); err == nil && info.IsDir() {
+			cmd.Dir = hctx.WorkspacePath
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait forever on grandchildren that inherited the output pipes
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	runErr := cmd.Run()
+
+	result := &Result{
+		Hook:     hook,
+		Command:  command,
+		ExitCode: cmd.ProcessState.ExitCode(),
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Duration: time.Since(start),
+		TimedOut: ctx.Err() == context.DeadlineExceeded,
+	}
+
+	if result.TimedOut {
+		return result, errors.NewError(errors.ErrHook, "hook timed out").
+			WithDetail("hook", string(hook)).
+			WithDetail("command", command).
+			WithDetail("timeout", e.timeout.String()).
+			WithHint("Increase hooks.timeout in config", "")
+	}
+
+	if runErr != nil {
+		if _, ok := runErr.(*exec.ExitError); !ok {
+			// The hook never ran (missing interpreter, permissions, ...)
+			result.ExitCode = -1
+			return result, errors.WrapError(errors.ErrHook, "failed to run hook", runErr).
+				WithDetail("hook", string(hook)).
+				WithDetail("command", command)
+		}
+
+		return result, errors.NewError(errors.ErrHook, "hook exited with non-zero status").
+			WithDetail("hook", string(hook)).
+			WithDetail("command", command).
+			WithDetail("exitCode", result.ExitCode).
+			WithDetail("stderr", result.Stderr)
+	}
+
+	return result, nil
+}
+
+// buildCommand runs script paths directly and everything else through the shell
+func (e *executor) buildCommand(ctx context.Context, command string) *exec.Cmd {
+	if script := e.scriptPath(command); script != "" {
+		return exec.CommandContext(ctx, script)
+	}
+	return exec.CommandContext(ctx, "/bin/sh", "-c", command)
+}
+
+// scriptPath returns the absolute path if command names an existing file
+func (e *executor) scriptPath(command string) string {
+	path := command
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(e.repoRoot, path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return ""
+	}
+	return path
+}
+
+// environment builds the YAGWT_* variables passed to hooks
+func environment(hook Type, ctx Context) []string {
+	return []string{
+		"YAGWT_HOOK=" + string(hook),
+		"YAGWT_REPO_ROOT=" + ctx.RepoRoot,
+		"YAGWT_WORKSPACE_ID=" + ctx.WorkspaceID,
+		"YAGWT_WORKSPACE_PATH=" + ctx.WorkspacePath,
+		"YAGWT_WORKSPACE_NAME=" + ctx.WorkspaceName,
+		"YAGWT_TARGET_REF=" + ctx.TargetRef,
+		"YAGWT_OPERATION=" + ctx.Operation,
+	}
+}
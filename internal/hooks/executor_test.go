@@ -0,0 +1,169 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bmf/yagwt/internal/config"
+	"github.com/bmf/yagwt/internal/errors"
+)
+
+func writeScript(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create script dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+}
+
+func TestExecuteNoHookConfigured(t *testing.T) {
+	exec := NewExecutor(t.TempDir(), config.HooksConfig{})
+
+	result, err := exec.Execute(PostCreate, Context{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result != nil {
+		t.Errorf("Expected nil result when no hook is configured, got %+v", result)
+	}
+}
+
+func TestExecuteEnvironment(t *testing.T) {
+	repoRoot := t.TempDir()
+	wsPath := t.TempDir()
+
+	exec := NewExecutor(repoRoot, config.HooksConfig{
+		PostCreate: `echo "$YAGWT_OPERATION|$YAGWT_WORKSPACE_NAME|$YAGWT_WORKSPACE_ID|$YAGWT_TARGET_REF|$YAGWT_REPO_ROOT|$(pwd)"`,
+	})
+
+	result, err := exec.Execute(PostCreate, Context{
+		RepoRoot:      repoRoot,
+		WorkspaceID:   "ws-123",
+		WorkspacePath: wsPath,
+		WorkspaceName: "auth",
+		TargetRef:     "refs/heads/feature/auth",
+		Operation:     "create",
+	})
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+
+	resolvedWs, _ := filepath.EvalSymlinks(wsPath)
+	want := "create|auth|ws-123|refs/heads/feature/auth|" + repoRoot + "|" + resolvedWs
+	if got := strings.TrimSpace(result.Stdout); got != want {
+		t.Errorf("Stdout = %q, want %q", got, want)
+	}
+
+	if result.ExitCode != 0 {
+		t.Errorf("ExitCode = %d, want 0", result.ExitCode)
+	}
+
+	if result.Hook != PostCreate {
+		t.Errorf("Hook = %q, want %q", result.Hook, PostCreate)
+	}
+}
+
+func TestExecuteNonZeroExit(t *testing.T) {
+	exec := NewExecutor(t.TempDir(), config.HooksConfig{
+		PreRemove: "echo refusing >&2; exit 7",
+	})
+
+	result, err := exec.Execute(PreRemove, Context{})
+	if err == nil {
+		t.Fatal("Expected error for non-zero exit")
+	}
+
+	coreErr, ok := err.(*errors.Error)
+	if !ok {
+		t.Fatalf("Expected *errors.Error, got %T", err)
+	}
+
+	if coreErr.Code != errors.ErrHook {
+		t.Errorf("Expected error code %s, got %s", errors.ErrHook, coreErr.Code)
+	}
+
+	if result == nil {
+		t.Fatal("Expected result even when hook fails")
+	}
+
+	if result.ExitCode != 7 {
+		t.Errorf("ExitCode = %d, want 7", result.ExitCode)
+	}
+
+	if strings.TrimSpace(result.Stderr) != "refusing" {
+		t.Errorf("Stderr = %q, want %q", result.Stderr, "refusing")
+	}
+}
+
+func TestExecuteTimeout(t *testing.T) {
+	exec := NewExecutor(t.TempDir(), config.HooksConfig{
+		PostCreate: "sleep 5",
+		Timeout:    config.Duration(100 * time.Millisecond),
+	})
+
+	start := time.Now()
+	result, err := exec.Execute(PostCreate, Context{})
+	if err == nil {
+		t.Fatal("Expected timeout error")
+	}
+
+	if !result.TimedOut {
+		t.Error("Expected result to be marked as timed out")
+	}
+
+	if time.Since(start) > 3*time.Second {
+		t.Errorf("Timeout not enforced, took %v", time.Since(start))
+	}
+}
+
+func TestExecuteRelativeScriptPath(t *testing.T) {
+	repoRoot := t.TempDir()
+	writeScript(t, filepath.Join(repoRoot, "scripts", "setup.sh"), "#!/bin/sh\necho from-script\n")
+
+	exec := NewExecutor(repoRoot, config.HooksConfig{
+		PostCreate: "scripts/setup.sh",
+	})
+
+	result, err := exec.Execute(PostCreate, Context{WorkspacePath: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+
+	if strings.TrimSpace(result.Stdout) != "from-script" {
+		t.Errorf("Stdout = %q, want %q", result.Stdout, "from-script")
+	}
+}
+
+func TestDiscoverConventionalHookDir(t *testing.T) {
+	repoRoot := t.TempDir()
+	script := filepath.Join(repoRoot, ".yagwt", "hooks", "post-remove")
+	writeScript(t, script, "#!/bin/sh\necho removed\n")
+
+	exec := NewExecutor(repoRoot, config.HooksConfig{})
+
+	result, err := exec.Execute(PostRemove, Context{})
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+
+	if result == nil {
+		t.Fatal("Expected hook from .yagwt/hooks to run")
+	}
+
+	if result.Command != script {
+		t.Errorf("Command = %q, want %q", result.Command, script)
+	}
+
+	// Config setting takes precedence over the conventional location
+	exec = NewExecutor(repoRoot, config.HooksConfig{PostRemove: "echo configured"})
+	result, _ = exec.Execute(PostRemove, Context{})
+	if strings.TrimSpace(result.Stdout) != "configured" {
+		t.Errorf("Expected configured hook to win, got %q", result.Stdout)
+	}
+}
@@ -0,0 +1,239 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bmf/yagwt/internal/errors"
+	"github.com/bmf/yagwt/internal/metadata"
+)
+
+// Operations recorded in the journal
+const (
+	OpRemove        = "remove"
+	OpCleanup       = "cleanup"
+	OpRename        = "rename"
+	OpForgetMissing = "forget-missing"
+	OpPurge         = "purge"
+	OpUndo          = "undo"
+)
+
+// Journal is an append-only record of destructive operations
+type Journal interface {
+	// Append assigns the next sequence number and time to entry and
+	// writes it. Existing entries are never modified.
+	Append(entry Entry) (Entry, error)
+
+	// List returns all entries, oldest first, with UndoneBy filled in
+	List() ([]Entry, error)
+
+	// Get returns the entry with the given sequence number
+	Get(seq int) (Entry, error)
+}
+
+// Entry records one operation and what is needed to reverse it
+type Entry struct {
+	Seq         int       `json:"seq"`
+	Time        time.Time `json:"time"`
+	Operation   string    `json:"operation"`
+	WorkspaceID string    `json:"workspaceId,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	Path        string    `json:"path,omitempty"`
+
+	// Metadata as it was before the operation; nil for untracked worktrees
+	Previous *metadata.WorkspaceMetadata `json:"previous,omitempty"`
+
+	// Git state at removal time
+	Branch        string `json:"branch,omitempty"`
+	HeadSHA       string `json:"headSha,omitempty"`
+	DeletedBranch bool   `json:"deletedBranch,omitempty"`
+
+	// Changes saved by the on-dirty strategy
+	OnDirty   string `json:"onDirty,omitempty"`
+	StashRef  string `json:"stashRef,omitempty"`
+	PatchFile string `json:"patchFile,omitempty"`
+	WIPCommit string `json:"wipCommit,omitempty"`
+
+	// Set when the workspace went to the trash instead of being deleted
+	TrashRef string `json:"trashRef,omitempty"`
+
+	NewName string `json:"newName,omitempty"` // rename only
+	Undoes  int    `json:"undoes,omitempty"`  // undo only
+
+	// UndoneBy is the sequence number of the undo entry that reversed this
+	// one. It is derived by List and Get, never stored.
+	UndoneBy int `json:"-"`
+}
+
+// Undoable reports whether the entry can still be reversed
+func (e Entry) Undoable() bool {
+	return e.Operation != OpUndo && e.Operation != OpPurge && e.UndoneBy == 0
+}
+
+// journal implements Journal as one JSON file per entry
+type journal struct {
+	dir string
+}
+
+// New returns the journal stored under <gitDir>/yagwt/journal
+func New(gitDir string) Journal {
+	return &journal{dir: filepath.Join(gitDir, "yagwt", "journal")}
+}
+
+// Append writes entry to the next free sequence number. Files are created
+// exclusively, so concurrent writers never overwrite each other.
+func (j *journal) Append(entry Entry) (Entry, error) {
+	if err := os.MkdirAll(j.dir, 0755); err != nil {
+		return entry, errors.WrapError(errors.ErrConfig, "failed to create journal directory", err).
+			WithDetail("path", j.dir)
+	}
+
+	seqs, err := j.sequences()
+	if err != nil {
+		return entry, err
+	}
+	next := 1
+	if len(seqs) > 0 {
+		next = seqs[len(seqs)-1] + 1
+	}
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	for {
+		entry.Seq = next
+		data, err := json.MarshalIndent(entry, "", "  ")
+		if err != nil {
+			return entry, errors.WrapError(errors.ErrConfig, "failed to marshal journal entry", err)
+		}
+
+		path := j.entryPath(next)
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			next++
+			continue
+		}
+		if err != nil {
+			return entry, errors.WrapError(errors.ErrConfig, "failed to create journal entry", err).
+				WithDetail("path", path)
+		}
+
+		_, err = file.Write(data)
+		if err == nil {
+			err = file.Sync()
+		}
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return entry, errors.WrapError(errors.ErrConfig, "failed to write journal entry", err).
+				WithDetail("path", path)
+		}
+
+		return entry, nil
+	}
+}
+
+// List reads every entry. Unreadable files, such as one left half-written
+// by a crash, are skipped.
+func (j *journal) List() ([]Entry, error) {
+	seqs, err := j.sequences()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(seqs))
+	index := make(map[int]int, len(seqs))
+	for _, seq := range seqs {
+		entry, err := j.read(seq)
+		if err != nil {
+			continue
+		}
+		index[entry.Seq] = len(entries)
+		entries = append(entries, entry)
+	}
+
+	for _, entry := range entries {
+		if entry.Operation != OpUndo {
+			continue
+		}
+		if i, ok := index[entry.Undoes]; ok {
+			entries[i].UndoneBy = entry.Seq
+		}
+	}
+
+	return entries, nil
+}
+
+// Get returns a single entry
+func (j *journal) Get(seq int) (Entry, error) {
+	entries, err := j.List()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	for _, entry := range entries {
+		if entry.Seq == seq {
+			return entry, nil
+		}
+	}
+
+	return Entry{}, errors.NewError(errors.ErrNotFound, "journal entry not found").
+		WithDetail("entry", seq).
+		WithHint("List journal entries", "yagwt log")
+}
+
+// read parses the entry file for seq
+func (j *journal) read(seq int) (Entry, error) {
+	data, err := os.ReadFile(j.entryPath(seq))
+	if err != nil {
+		return Entry{}, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// sequences returns the sequence numbers present on disk in ascending order
+func (j *journal) sequences() ([]int, error) {
+	files, err := os.ReadDir(j.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WrapError(errors.ErrConfig, "failed to read journal directory", err).
+			WithDetail("path", j.dir)
+	}
+
+	var seqs []int
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		seq, err := strconv.Atoi(strings.TrimSuffix(name, ".json"))
+		if err != nil || seq <= 0 {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+
+	sort.Ints(seqs)
+	return seqs, nil
+}
+
+// entryPath returns the file path for seq; zero padding keeps directory
+// listings in order
+func (j *journal) entryPath(seq int) string {
+	return filepath.Join(j.dir, fmt.Sprintf("%06d.json", seq))
+}
@@ -0,0 +1,121 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bmf/yagwt/internal/errors"
+	"github.com/bmf/yagwt/internal/metadata"
+)
+
+func TestAppendAndList(t *testing.T) {
+	gitDir := filepath.Join(t.TempDir(), ".git")
+	j := New(gitDir)
+
+	// An empty journal lists nothing
+	entries, err := j.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("Expected no entries, got %d", len(entries))
+	}
+
+	first, err := j.Append(Entry{
+		Operation: OpRemove,
+		Name:      "feature-a",
+		Previous:  &metadata.WorkspaceMetadata{ID: "ws1", Name: "feature-a"},
+		StashRef:  "abc123",
+	})
+	if err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+	if first.Seq != 1 || first.Time.IsZero() {
+		t.Errorf("Append() = seq %d time %v, want seq 1 and a timestamp", first.Seq, first.Time)
+	}
+
+	second, err := j.Append(Entry{Operation: OpRename, Name: "feature-b", NewName: "feature-c"})
+	if err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+	if second.Seq != 2 {
+		t.Errorf("Second entry seq = %d, want 2", second.Seq)
+	}
+
+	entries, err = j.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Seq != 1 || entries[1].Seq != 2 {
+		t.Fatalf("List() returned %+v", entries)
+	}
+	if entries[0].Previous == nil || entries[0].Previous.ID != "ws1" || entries[0].StashRef != "abc123" {
+		t.Errorf("Entry fields not round-tripped: %+v", entries[0])
+	}
+}
+
+func TestUndoneBy(t *testing.T) {
+	j := New(filepath.Join(t.TempDir(), ".git"))
+
+	removed, _ := j.Append(Entry{Operation: OpRemove, Name: "feature-a"})
+	if _, err := j.Append(Entry{Operation: OpUndo, Undoes: removed.Seq}); err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+
+	entry, err := j.Get(removed.Seq)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if entry.UndoneBy != 2 {
+		t.Errorf("UndoneBy = %d, want 2", entry.UndoneBy)
+	}
+	if entry.Undoable() {
+		t.Error("Undone entry should not be undoable")
+	}
+
+	undo, _ := j.Get(2)
+	if undo.Undoable() {
+		t.Error("Undo entries should not be undoable")
+	}
+}
+
+func TestGetMissing(t *testing.T) {
+	j := New(filepath.Join(t.TempDir(), ".git"))
+
+	_, err := j.Get(42)
+	if yerr, ok := err.(*errors.Error); !ok || yerr.Code != errors.ErrNotFound {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestListSkipsCorruptEntries(t *testing.T) {
+	gitDir := filepath.Join(t.TempDir(), ".git")
+	j := New(gitDir)
+
+	if _, err := j.Append(Entry{Operation: OpRemove}); err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+
+	// A half-written entry must not hide the others or block appends
+	dir := filepath.Join(gitDir, "yagwt", "journal")
+	if err := os.WriteFile(filepath.Join(dir, "000002.json"), []byte("{\"seq\":"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := j.Append(Entry{Operation: OpRename})
+	if err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+	if next.Seq != 3 {
+		t.Errorf("Append() seq = %d, want 3", next.Seq)
+	}
+
+	entries, err := j.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 readable entries, got %d", len(entries))
+	}
+}
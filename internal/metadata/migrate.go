@@ -0,0 +1,183 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/bmf/yagwt/internal/errors"
+)
+
+// CurrentSchemaVersion is the meta.json schema this build reads and writes
+const CurrentSchemaVersion = 2
+
+// WriterVersion is the yagwt version recorded in files this build writes,
+// so an older build can say which release it needs. The CLI sets it at
+// startup.
+var WriterVersion = "dev"
+
+// Migration upgrades a decoded meta.json document by one schema version
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(doc map[string]interface{}) error
+}
+
+// migrations holds one entry per schema version, in order. Add an entry
+// and bump CurrentSchemaVersion whenever older builds would misread files
+// written by this one or drop data when they rewrite them.
+var migrations = []Migration{
+	{
+		From:        1,
+		Description: "Add trash, branch, label, field, seed and sparse state to workspaces",
+		// The new fields are optional, so no data changes. The version bump
+		// makes older builds refuse the file: they would list trashed
+		// workspaces as live ones and drop the new fields on every write.
+		Apply: func(doc map[string]interface{}) error { return nil },
+	},
+}
+
+// MigrationPlan describes the upgrade of a meta.json file to the current
+// schema
+type MigrationPlan struct {
+	Path        string
+	FromVersion int
+	ToVersion   int
+	Steps       []string // migration descriptions, oldest first
+	BackupPath  string   // where the original file is kept
+}
+
+// Needed reports whether the file is older than the current schema
+func (p MigrationPlan) Needed() bool {
+	return p.FromVersion < p.ToVersion
+}
+
+// PlanMigration reports how meta.json would be upgraded without changing it
+func (s *store) PlanMigration() (MigrationPlan, error) {
+	_, version, err := s.load()
+	if err != nil {
+		return MigrationPlan{}, err
+	}
+	return s.plan(version), nil
+}
+
+// Migrate upgrades meta.json in place, keeping the original file at the
+// plan's backup path
+func (s *store) Migrate() (MigrationPlan, error) {
+	plan, err := s.PlanMigration()
+	if err != nil || !plan.Needed() {
+		return plan, err
+	}
+
+	// Update backs up the old file and saves it in the current schema
+	return plan, s.Update(func(*Metadata) error { return nil })
+}
+
+// plan builds the migration plan for a file at the given schema version;
+// 0 means there is no file yet
+func (s *store) plan(version int) MigrationPlan {
+	if version == 0 {
+		version = CurrentSchemaVersion
+	}
+
+	plan := MigrationPlan{
+		Path:        s.path,
+		FromVersion: version,
+		ToVersion:   CurrentSchemaVersion,
+		Steps:       []string{},
+	}
+	if !plan.Needed() {
+		return plan
+	}
+
+	plan.BackupPath = s.backupPath(version)
+	for _, m := range migrations {
+		if m.From >= version {
+			plan.Steps = append(plan.Steps, fmt.Sprintf("v%d → v%d: %s", m.From, m.From+1, m.Description))
+		}
+	}
+	return plan
+}
+
+// backupPath returns where the pre-migration file of a schema version is kept
+func (s *store) backupPath(version int) string {
+	return fmt.Sprintf("%s.v%d.bak", s.path, version)
+}
+
+// backup copies meta.json, still at the given schema version, to its
+// backup path
+func (s *store) backup(version int) error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return errors.WrapError(errors.ErrConfig, "failed to read metadata file", err).
+			WithDetail("path", s.path)
+	}
+
+	path := s.backupPath(version)
+	if err := writeFileSync(path, data); err != nil {
+		return errors.WrapError(errors.ErrConfig, "failed to back up metadata before migration", err).
+			WithDetail("path", path)
+	}
+	return nil
+}
+
+// decode parses a metadata file, upgrading older schemas in memory. It
+// returns the schema version the data was written with.
+func decode(path string, data []byte) (Metadata, int, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return Metadata{}, 0, corruptedError(path, err)
+	}
+
+	version := 0
+	if v, ok := doc["schemaVersion"].(float64); ok {
+		version = int(v)
+	}
+
+	if version > CurrentSchemaVersion {
+		writtenBy, _ := doc["writtenBy"].(string)
+		return Metadata{}, version, newerSchemaError(path, version, writtenBy)
+	}
+	if version < 1 {
+		return Metadata{}, version, corruptedError(path, fmt.Errorf("missing schema version"))
+	}
+
+	for _, m := range migrations {
+		if m.From < version {
+			continue
+		}
+		if err := m.Apply(doc); err != nil {
+			return Metadata{}, version, errors.WrapError(errors.ErrConfig, "metadata migration failed", err).
+				WithDetail("path", path).
+				WithDetail("from", m.From).
+				WithDetail("to", m.From+1)
+		}
+		doc["schemaVersion"] = m.From + 1
+	}
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return Metadata{}, version, corruptedError(path, err)
+	}
+
+	var metadata Metadata
+	if err := json.Unmarshal(migrated, &metadata); err != nil {
+		return Metadata{}, version, corruptedError(path, err)
+	}
+
+	return metadata, version, nil
+}
+
+// newerSchemaError reports a meta.json written by a newer yagwt
+func newerSchemaError(path string, version int, writtenBy string) error {
+	err := errors.NewError(errors.ErrConfig, "metadata was written by a newer version of yagwt").
+		WithDetail("path", path).
+		WithDetail("version", version).
+		WithDetail("supported", CurrentSchemaVersion)
+
+	if writtenBy != "" {
+		return err.WithDetail("writtenBy", writtenBy).
+			WithHint("Upgrade yagwt to "+writtenBy+" or later", "")
+	}
+	return err.WithHint(fmt.Sprintf("Upgrade yagwt to a release that supports metadata schema %d", version), "")
+}
@@ -0,0 +1,105 @@
+package metadata
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bmf/yagwt/internal/errors"
+)
+
+const v1Metadata = `{
+  "schemaVersion": 1,
+  "workspaces": {
+    "ws1": {"id": "ws1", "name": "feature-a", "path": "/path/to/feature-a", "flags": {"pinned": true}}
+  },
+  "index": {"byPath": {"/path/to/feature-a": "ws1"}, "byName": {"feature-a": "ws1"}}
+}`
+
+func TestMigrateV1(t *testing.T) {
+	gitDir := filepath.Join(t.TempDir(), ".git")
+	store, _ := NewStore(gitDir)
+	metaPath := filepath.Join(gitDir, "yagwt", "meta.json")
+	if err := os.WriteFile(metaPath, []byte(v1Metadata), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Older files are readable before they are migrated
+	ws, err := store.FindByName("feature-a")
+	if err != nil {
+		t.Fatalf("FindByName() on v1 metadata failed: %v", err)
+	}
+	if !ws.Flags["pinned"] {
+		t.Errorf("Flags not preserved: %+v", ws.Flags)
+	}
+
+	plan, err := store.PlanMigration()
+	if err != nil {
+		t.Fatalf("PlanMigration() failed: %v", err)
+	}
+	if !plan.Needed() || plan.FromVersion != 1 || plan.ToVersion != CurrentSchemaVersion || len(plan.Steps) == 0 {
+		t.Fatalf("PlanMigration() = %+v", plan)
+	}
+
+	// Planning must not touch the file
+	data, _ := os.ReadFile(metaPath)
+	if string(data) != v1Metadata {
+		t.Error("PlanMigration() modified meta.json")
+	}
+
+	WriterVersion = "1.2.3"
+	defer func() { WriterVersion = "dev" }()
+
+	if _, err := store.Migrate(); err != nil {
+		t.Fatalf("Migrate() failed: %v", err)
+	}
+
+	backup, err := os.ReadFile(plan.BackupPath)
+	if err != nil || string(backup) != v1Metadata {
+		t.Errorf("Backup %s = %q, %v; want the original file", plan.BackupPath, backup, err)
+	}
+
+	var migrated Metadata
+	data, _ = os.ReadFile(metaPath)
+	if err := json.Unmarshal(data, &migrated); err != nil {
+		t.Fatalf("Migrated file is not valid JSON: %v", err)
+	}
+	if migrated.SchemaVersion != CurrentSchemaVersion || migrated.WrittenBy != "1.2.3" {
+		t.Errorf("Migrated file has version %d written by %q", migrated.SchemaVersion, migrated.WrittenBy)
+	}
+
+	plan, _ = store.PlanMigration()
+	if plan.Needed() {
+		t.Errorf("PlanMigration() after Migrate() = %+v", plan)
+	}
+}
+
+func TestNewerSchemaVersion(t *testing.T) {
+	gitDir := filepath.Join(t.TempDir(), ".git")
+	store, _ := NewStore(gitDir)
+	metaPath := filepath.Join(gitDir, "yagwt", "meta.json")
+	newer := `{"schemaVersion": 99, "writtenBy": "9.0.0", "workspaces": {}}`
+	if err := os.WriteFile(metaPath, []byte(newer), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := store.Load()
+	yerr, ok := err.(*errors.Error)
+	if !ok || yerr.Code != errors.ErrConfig {
+		t.Fatalf("Load() error = %v, want ErrConfig", err)
+	}
+	if len(yerr.Hints) == 0 || !strings.Contains(yerr.Hints[0].Message, "9.0.0") {
+		t.Errorf("Expected a hint naming yagwt 9.0.0, got %+v", yerr.Hints)
+	}
+
+	// Writers must refuse rather than downgrade the file
+	if err := store.Update(func(*Metadata) error { return nil }); err == nil {
+		t.Error("Update() should fail on newer metadata")
+	}
+	data, _ := os.ReadFile(metaPath)
+	if string(data) != newer {
+		t.Error("Newer metadata file was modified")
+	}
+}
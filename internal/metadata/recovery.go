@@ -0,0 +1,207 @@
+package metadata
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bmf/yagwt/internal/errors"
+)
+
+// generations is how many previous versions of meta.json Save keeps
+const generations = 5
+
+// Warn reports problems the store worked around, such as reading a backup
+// generation because meta.json is damaged. The CLI prints these to stderr.
+var Warn = func(message string) {}
+
+// corruptErr marks metadata that cannot be parsed, as opposed to metadata
+// this build refuses to read
+type corruptErr struct {
+	error
+}
+
+// corruptedError reports a metadata file that cannot be parsed
+func corruptedError(path string, err error) error {
+	return errors.WrapError(errors.ErrConfig, "corrupted metadata file", corruptErr{err}).
+		WithDetail("path", path).
+		WithHint("Rebuild it from backups and git worktree list", "yagwt doctor")
+}
+
+// isCorrupt reports whether err came from an unparseable metadata file
+func isCorrupt(err error) bool {
+	yerr, ok := err.(*errors.Error)
+	if !ok {
+		return false
+	}
+	_, ok = yerr.Wrapped.(corruptErr)
+	return ok
+}
+
+// generationPath returns the path of backup generation n; 0 is meta.json
+func (s *store) generationPath(n int) string {
+	if n == 0 {
+		return s.path
+	}
+	return fmt.Sprintf("%s.%d", s.path, n)
+}
+
+// rotate shifts the backup generations up by one and makes the current
+// meta.json the newest backup. The current file is linked rather than
+// moved, so meta.json exists at every point.
+func (s *store) rotate() error {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return nil
+	}
+
+	for n := generations - 1; n >= 1; n-- {
+		err := os.Rename(s.generationPath(n), s.generationPath(n+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	newest := s.generationPath(1)
+	os.Remove(newest)
+	if err := os.Link(s.path, newest); err == nil {
+		return nil
+	}
+	return copyFileSync(s.path, newest)
+}
+
+// Verify reports whether meta.json itself is damaged, even when Load can
+// fall back to a backup. Files this build refuses to read, such as ones
+// from a newer schema, are not damaged.
+func (s *store) Verify() error {
+	if _, _, err := s.readGeneration(s.path); isCorrupt(err) {
+		return err
+	}
+	return nil
+}
+
+// Rebuild replaces meta.json with workspaces merged from every readable
+// generation, newest first, keeping only those keep accepts. A workspace
+// whose name or path is already taken by a newer entry is dropped. It
+// returns the number of workspaces recovered.
+func (s *store) Rebuild(keep func(WorkspaceMetadata) bool) (int, error) {
+	lck, err := s.lockMgr.NewLock(s.path + ".lock")
+	if err != nil {
+		return 0, err
+	}
+	if err := lck.Acquire(storeLockTimeout); err != nil {
+		return 0, err
+	}
+	defer lck.Release()
+
+	merged := emptyMetadata()
+	for n := 0; n <= generations; n++ {
+		gen, version, err := s.readGeneration(s.generationPath(n))
+		if err != nil || version == 0 {
+			continue
+		}
+
+		for id, ws := range gen.Workspaces {
+			if _, seen := merged.Workspaces[id]; seen || !keep(ws) {
+				continue
+			}
+			if ws.Trash == nil {
+				if _, taken := merged.Index.ByPath[ws.Path]; taken {
+					continue
+				}
+				if _, taken := merged.Index.ByName[ws.Name]; taken {
+					continue
+				}
+			}
+			if ws.Flags == nil {
+				ws.Flags = make(map[string]bool)
+			}
+			merged.SetWorkspace(ws)
+		}
+	}
+
+	if err := s.Save(merged); err != nil {
+		return 0, err
+	}
+	return len(merged.Workspaces), nil
+}
+
+// MergeFile adds the workspaces from another metadata file, such as one
+// written into a linked worktree's git dir by an older yagwt, then renames
+// that file to <path>.merged. Workspaces whose ID, name or path the store
+// already has are skipped.
+func (s *store) MergeFile(path string) (merged, skipped int, err error) {
+	stray, version, err := s.readGeneration(path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if version != 0 {
+		err = s.Update(func(m *Metadata) error {
+			merged, skipped = 0, 0
+			for id, ws := range stray.Workspaces {
+				if _, exists := m.Workspaces[id]; exists {
+					skipped++
+					continue
+				}
+				if ws.Trash == nil {
+					_, pathTaken := m.Index.ByPath[ws.Path]
+					_, nameTaken := m.Index.ByName[ws.Name]
+					if pathTaken || nameTaken {
+						skipped++
+						continue
+					}
+				}
+				if ws.Flags == nil {
+					ws.Flags = make(map[string]bool)
+				}
+				m.SetWorkspace(ws)
+				merged++
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if err := os.Rename(path, path+".merged"); err != nil {
+		return merged, skipped, errors.WrapError(errors.ErrConfig, "failed to retire merged metadata file", err).
+			WithDetail("path", path)
+	}
+	return merged, skipped, nil
+}
+
+// writeFileSync writes data to path and flushes it to disk
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// copyFileSync copies src to dst and flushes dst to disk
+func copyFileSync(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return writeFileSync(dst, data)
+}
+
+// syncDir flushes directory entries, making a completed rename durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
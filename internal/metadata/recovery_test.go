@@ -0,0 +1,150 @@
+package metadata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveRotatesGenerations(t *testing.T) {
+	gitDir := filepath.Join(t.TempDir(), ".git")
+	store, _ := NewStore(gitDir)
+	metaPath := filepath.Join(gitDir, "yagwt", "meta.json")
+
+	for i := 0; i < generations+3; i++ {
+		name := fmt.Sprintf("ws%d", i)
+		if err := store.Set(name, WorkspaceMetadata{Name: name, Path: "/path/" + name}); err != nil {
+			t.Fatalf("Set() failed: %v", err)
+		}
+	}
+
+	// meta.json.1 holds the save before the last one
+	gen1, _, err := readGeneration(metaPath + ".1")
+	if err != nil {
+		t.Fatalf("Reading generation 1 failed: %v", err)
+	}
+	if len(gen1.Workspaces) != generations+2 {
+		t.Errorf("Generation 1 has %d workspaces, want %d", len(gen1.Workspaces), generations+2)
+	}
+
+	if _, err := os.Stat(fmt.Sprintf("%s.%d", metaPath, generations)); err != nil {
+		t.Errorf("Oldest generation missing: %v", err)
+	}
+	if _, err := os.Stat(fmt.Sprintf("%s.%d", metaPath, generations+1)); !os.IsNotExist(err) {
+		t.Errorf("Expected at most %d generations", generations)
+	}
+	if _, err := os.Stat(metaPath + ".tmp"); !os.IsNotExist(err) {
+		t.Error("Temporary file left behind")
+	}
+}
+
+func TestRefreshSkipsRotation(t *testing.T) {
+	gitDir := filepath.Join(t.TempDir(), ".git")
+	store, _ := NewStore(gitDir)
+	metaPath := filepath.Join(gitDir, "yagwt", "meta.json")
+
+	store.Set("ws1", WorkspaceMetadata{Name: "one", Path: "/path/one"})
+	store.Set("ws1", WorkspaceMetadata{Name: "one", Path: "/path/one", Branch: "main"})
+	gen1, _ := os.ReadFile(metaPath + ".1")
+
+	err := store.Refresh(func(m *Metadata) error {
+		ws := m.Workspaces["ws1"]
+		ws.HeadSHA = "abc123"
+		m.SetWorkspace(ws)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Refresh() failed: %v", err)
+	}
+
+	if ws, _ := store.Get("ws1"); ws.HeadSHA != "abc123" {
+		t.Errorf("HeadSHA = %q, want the refreshed value", ws.HeadSHA)
+	}
+	if after, _ := os.ReadFile(metaPath + ".1"); string(after) != string(gen1) {
+		t.Error("Refresh() rotated the backup generations")
+	}
+
+	// Files from an older schema are left for a real write to upgrade
+	if err := os.WriteFile(metaPath, []byte(`{"schemaVersion": 1, "workspaces": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store.Refresh(func(m *Metadata) error { return nil })
+	if data, _ := os.ReadFile(metaPath); !strings.Contains(string(data), `"schemaVersion": 1`) {
+		t.Errorf("Refresh() upgraded an older file: %s", data)
+	}
+}
+
+// readGeneration decodes a single metadata file
+func readGeneration(path string) (Metadata, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Metadata{}, 0, err
+	}
+	return decode(path, data)
+}
+
+func TestLoadFallsBackToBackup(t *testing.T) {
+	gitDir := filepath.Join(t.TempDir(), ".git")
+	store, _ := NewStore(gitDir)
+	metaPath := filepath.Join(gitDir, "yagwt", "meta.json")
+
+	store.Set("ws1", WorkspaceMetadata{Name: "feature-a", Path: "/path/to/feature-a"})
+	store.Set("ws2", WorkspaceMetadata{Name: "feature-b", Path: "/path/to/feature-b"})
+
+	// Simulate a torn write
+	if err := os.WriteFile(metaPath, []byte(`{"schemaVersion": 2, "workspa`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var warnings []string
+	Warn = func(message string) { warnings = append(warnings, message) }
+	defer func() { Warn = func(string) {} }()
+
+	metadata, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() should fall back to a backup: %v", err)
+	}
+	if _, ok := metadata.Workspaces["ws1"]; !ok {
+		t.Errorf("Backup generation not used: %+v", metadata.Workspaces)
+	}
+	store.Load()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], metaPath+".1") {
+		t.Errorf("Expected one warning naming the backup, got %q", warnings)
+	}
+
+	if err := store.Verify(); !isCorrupt(err) {
+		t.Errorf("Verify() = %v, want a corruption error", err)
+	}
+}
+
+func TestRebuild(t *testing.T) {
+	gitDir := filepath.Join(t.TempDir(), ".git")
+	store, _ := NewStore(gitDir)
+	metaPath := filepath.Join(gitDir, "yagwt", "meta.json")
+
+	store.Set("ws1", WorkspaceMetadata{Name: "feature-a", Path: "/path/to/feature-a", Flags: map[string]bool{"pinned": true}})
+	store.Set("ws2", WorkspaceMetadata{Name: "feature-b", Path: "/path/to/feature-b"})
+	store.Delete("ws1")
+	os.WriteFile(metaPath, []byte("garbage"), 0644)
+
+	// ws1 only survives in an older generation; ws2 is no longer a worktree
+	count, err := store.Rebuild(func(ws WorkspaceMetadata) bool {
+		return ws.Path != "/path/to/feature-b"
+	})
+	if err != nil {
+		t.Fatalf("Rebuild() failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Rebuild() recovered %d workspaces, want 1", count)
+	}
+
+	if err := store.Verify(); err != nil {
+		t.Errorf("Verify() after Rebuild() = %v", err)
+	}
+	ws, err := store.FindByName("feature-a")
+	if err != nil || !ws.Flags["pinned"] {
+		t.Errorf("FindByName() = %+v, %v; want the pinned workspace", ws, err)
+	}
+}
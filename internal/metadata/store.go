@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/bmf/yagwt/internal/errors"
+	"github.com/bmf/yagwt/internal/lock"
 )
 
 // Store persists workspace metadata
@@ -16,39 +18,60 @@ type Store interface {
 	Get(id string) (WorkspaceMetadata, error)
 	FindByName(name string) (WorkspaceMetadata, error)
 	FindByPath(path string) (WorkspaceMetadata, error)
+	FindByBranch(branch string) ([]WorkspaceMetadata, error)
 
-	// Write operations (requires lock)
+	// Write operations (take the store lock)
 	Save(metadata Metadata) error
 	Set(id string, meta WorkspaceMetadata) error
 	Delete(id string) error
+	Update(fn func(*Metadata) error) error
+	Refresh(fn func(*Metadata) error) error
 
 	// Index operations
 	RebuildIndex() error
+
+	// Recovery
+	Verify() error
+	Rebuild(keep func(WorkspaceMetadata) bool) (int, error)
+	MergeFile(path string) (merged, skipped int, err error)
+
+	// Schema migration
+	PlanMigration() (MigrationPlan, error)
+	Migrate() (MigrationPlan, error)
 }
 
 // Metadata is the top-level structure persisted to disk
 type Metadata struct {
 	SchemaVersion int                          `json:"schemaVersion"`
+	WrittenBy     string                       `json:"writtenBy,omitempty"` // yagwt version that last saved the file
 	Workspaces    map[string]WorkspaceMetadata `json:"workspaces"`
 	Index         Index                        `json:"index"`
 }
 
 // WorkspaceMetadata is per-workspace persistent data
 type WorkspaceMetadata struct {
-	ID        string             `json:"id"`
-	Name      string             `json:"name"`
-	Path      string             `json:"path"`
-	Flags     map[string]bool    `json:"flags"`
-	Ephemeral *EphemeralMetadata `json:"ephemeral,omitempty"`
-	Activity  ActivityMetadata   `json:"activity"`
-	CreatedAt time.Time          `json:"createdAt"`
-	UpdatedAt time.Time          `json:"updatedAt"`
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	Path      string                 `json:"path"`
+	Branch    string                 `json:"branch,omitempty"`  // checked-out branch; empty when detached
+	HeadSHA   string                 `json:"headSha,omitempty"` // last HEAD commit seen
+	Flags     map[string]bool        `json:"flags"`
+	Labels    []string               `json:"labels,omitempty"`
+	Fields    map[string]interface{} `json:"fields,omitempty"` // string, float64 or bool values
+	Ephemeral *EphemeralMetadata     `json:"ephemeral,omitempty"`
+	Activity  ActivityMetadata       `json:"activity"`
+	Trash     *TrashMetadata         `json:"trash,omitempty"` // set while the workspace is in the trash
+	Seed      *SeedMetadata          `json:"seed,omitempty"`  // last seed of its build caches
+	Sparse    *SparseMetadata        `json:"sparse,omitempty"`
+	CreatedAt time.Time              `json:"createdAt"`
+	UpdatedAt time.Time              `json:"updatedAt"`
 }
 
 // EphemeralMetadata contains TTL information
 type EphemeralMetadata struct {
 	TTLSeconds int       `json:"ttlSeconds"`
 	ExpiresAt  time.Time `json:"expiresAt"`
+	Sliding    bool      `json:"sliding,omitempty"` // activity extends ExpiresAt
 }
 
 // ActivityMetadata tracks usage
@@ -57,6 +80,33 @@ type ActivityMetadata struct {
 	LastGitActivityAt *time.Time `json:"lastGitActivityAt,omitempty"`
 }
 
+// TrashMetadata records where a soft-deleted workspace went and what it
+// had checked out
+type TrashMetadata struct {
+	TrashedAt time.Time `json:"trashedAt"`
+	Dir       string    `json:"dir"`              // directory holding the workspace files
+	Ref       string    `json:"ref"`              // refs/yagwt/trash/<id>, keeps HEAD reachable
+	Branch    string    `json:"branch,omitempty"` // empty for detached workspaces
+	HeadSHA   string    `json:"headSha"`
+}
+
+// SeedMetadata records which workspace build caches were last cloned from
+type SeedMetadata struct {
+	FromID   string    `json:"fromId"`
+	FromName string    `json:"fromName"`
+	Dirs     []string  `json:"dirs"` // relative to the workspace root
+	Bytes    int64     `json:"bytes"`
+	Method   string    `json:"method"` // reflink, hardlink or copy
+	SeededAt time.Time `json:"seededAt"`
+}
+
+// SparseMetadata records the sparse-checkout setup of a workspace
+type SparseMetadata struct {
+	Profile  string   `json:"profile,omitempty"` // config profile it was created from
+	Patterns []string `json:"patterns"`
+	Cone     bool     `json:"cone"`
+}
+
 // Index provides reverse lookups
 type Index struct {
 	ByPath   map[string]string   `json:"byPath"`   // path → ID
@@ -64,9 +114,15 @@ type Index struct {
 	ByBranch map[string][]string `json:"byBranch"` // branch → []ID
 }
 
+// storeLockTimeout bounds how long writers wait for another process's
+// transaction to finish
+const storeLockTimeout = 5 * time.Second
+
 // store implements Store interface
 type store struct {
-	path string
+	path     string
+	lockMgr  lock.Manager
+	warnOnce sync.Once
 }
 
 // NewStore creates a new metadata store
@@ -81,45 +137,62 @@ func NewStore(gitDir string) (Store, error) {
 	}
 
 	return &store{
-		path: storePath,
+		path:    storePath,
+		lockMgr: lock.NewManager(),
 	}, nil
 }
 
-// Load reads metadata from disk
+// Load reads metadata from disk. Files from older schemas are upgraded in
+// memory; the upgrade is written by the next save.
 func (s *store) Load() (Metadata, error) {
+	metadata, _, err := s.load()
+	return metadata, err
+}
+
+// load implements Load and also returns the schema version found on disk,
+// or 0 if there is no file yet. A damaged meta.json falls back to the
+// newest intact backup generation.
+func (s *store) load() (Metadata, int, error) {
+	metadata, version, err := s.readGeneration(s.path)
+	if err == nil || !isCorrupt(err) {
+		return metadata, version, err
+	}
+
+	for i := 1; i <= generations; i++ {
+		path := s.generationPath(i)
+		backup, backupVersion, backupErr := s.readGeneration(path)
+		if backupErr != nil || backupVersion == 0 {
+			continue
+		}
+		s.warnOnce.Do(func() {
+			Warn("metadata file " + s.path + " is damaged (" + err.Error() + "); using backup " + path +
+				". Run 'yagwt doctor' to rebuild it.")
+		})
+		return backup, backupVersion, nil
+	}
+
+	return Metadata{}, 0, err
+}
+
+// readGeneration reads and decodes one metadata file. A missing file
+// yields empty metadata and version 0.
+func (s *store) readGeneration(path string) (Metadata, int, error) {
 	// Return empty metadata if file doesn't exist
-	if _, err := os.Stat(s.path); os.IsNotExist(err) {
-		return Metadata{
-			SchemaVersion: 1,
-			Workspaces:    make(map[string]WorkspaceMetadata),
-			Index: Index{
-				ByPath:   make(map[string]string),
-				ByName:   make(map[string]string),
-				ByBranch: make(map[string][]string),
-			},
-		}, nil
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return emptyMetadata(), 0, nil
 	}
 
 	// Read file
-	data, err := os.ReadFile(s.path)
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return Metadata{}, errors.WrapError(errors.ErrConfig, "failed to read metadata file", err).
-			WithDetail("path", s.path)
-	}
-
-	// Parse JSON
-	var metadata Metadata
-	if err := json.Unmarshal(data, &metadata); err != nil {
-		return Metadata{}, errors.WrapError(errors.ErrConfig, "corrupted metadata file", err).
-			WithDetail("path", s.path).
-			WithHint("Try removing the metadata file to start fresh", "rm "+s.path)
+		return Metadata{}, 0, errors.WrapError(errors.ErrConfig, "failed to read metadata file", err).
+			WithDetail("path", path)
 	}
 
-	// Validate schema version
-	if metadata.SchemaVersion != 1 {
-		return Metadata{}, errors.NewError(errors.ErrConfig, "unsupported metadata schema version").
-			WithDetail("version", metadata.SchemaVersion).
-			WithDetail("expected", 1)
+	// Parse JSON, migrating older schemas
+	metadata, version, err := decode(path, data)
+	if err != nil {
+		return Metadata{}, version, err
 	}
 
 	// Initialize maps if nil
@@ -136,7 +209,20 @@ func (s *store) Load() (Metadata, error) {
 		metadata.Index.ByBranch = make(map[string][]string)
 	}
 
-	return metadata, nil
+	return metadata, version, nil
+}
+
+// emptyMetadata returns metadata with no workspaces
+func emptyMetadata() Metadata {
+	return Metadata{
+		SchemaVersion: CurrentSchemaVersion,
+		Workspaces:    make(map[string]WorkspaceMetadata),
+		Index: Index{
+			ByPath:   make(map[string]string),
+			ByName:   make(map[string]string),
+			ByBranch: make(map[string][]string),
+		},
+	}
 }
 
 // Get retrieves a workspace by ID
@@ -205,10 +291,86 @@ func (s *store) FindByPath(path string) (WorkspaceMetadata, error) {
 	return ws, nil
 }
 
-// Save writes metadata to disk atomically
+// FindByBranch retrieves the workspaces recorded on a branch. The result
+// reflects the branch each workspace had when its metadata was last
+// refreshed, so callers should check it against git.
+func (s *store) FindByBranch(branch string) ([]WorkspaceMetadata, error) {
+	metadata, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var workspaces []WorkspaceMetadata
+	for _, id := range metadata.Index.ByBranch[branch] {
+		if ws, ok := metadata.Workspaces[id]; ok {
+			workspaces = append(workspaces, ws)
+		}
+	}
+
+	return workspaces, nil
+}
+
+// Update runs fn on freshly loaded metadata and saves the result while
+// holding the store lock, so read-modify-write cycles from different
+// processes can't interleave. Nothing is saved if fn returns an error.
+// fn must not call other Store write methods. A file from an older schema
+// is backed up before it is first rewritten.
+func (s *store) Update(fn func(*Metadata) error) error {
+	return s.update(fn, false)
+}
+
+// Refresh is Update for values derived from git, such as activity times
+// and checked-out branches. It does not rotate backups, so the backup
+// generations keep real changes, and it leaves files from an older schema
+// alone rather than upgrading them.
+func (s *store) Refresh(fn func(*Metadata) error) error {
+	return s.update(fn, true)
+}
+
+// update implements Update and Refresh
+func (s *store) update(fn func(*Metadata) error, refresh bool) error {
+	lck, err := s.lockMgr.NewLock(s.path + ".lock")
+	if err != nil {
+		return err
+	}
+	if err := lck.Acquire(storeLockTimeout); err != nil {
+		return err
+	}
+	defer lck.Release()
+
+	metadata, version, err := s.load()
+	if err != nil {
+		return err
+	}
+	if refresh && version != CurrentSchemaVersion {
+		return nil
+	}
+
+	if err := fn(&metadata); err != nil {
+		return err
+	}
+
+	if version != 0 && version < CurrentSchemaVersion {
+		if err := s.backup(version); err != nil {
+			return err
+		}
+	}
+
+	return s.save(metadata, !refresh)
+}
+
+// Save writes metadata to disk atomically and durably, keeping the
+// previous versions as meta.json.1 (newest) to meta.json.N. It replaces the
+// whole file; use Update to modify existing metadata.
 func (s *store) Save(metadata Metadata) error {
+	return s.save(metadata, true)
+}
+
+// save implements Save, rotating the backup generations if rotate is set
+func (s *store) save(metadata Metadata, rotate bool) error {
 	// Ensure schema version is set
-	metadata.SchemaVersion = 1
+	metadata.SchemaVersion = CurrentSchemaVersion
+	metadata.WrittenBy = WriterVersion
 
 	// Marshal to JSON
 	data, err := json.MarshalIndent(metadata, "", "  ")
@@ -216,13 +378,23 @@ func (s *store) Save(metadata Metadata) error {
 		return errors.WrapError(errors.ErrConfig, "failed to marshal metadata", err)
 	}
 
-	// Write to temporary file first
+	// Write to a temporary file first and make sure it reached the disk
 	tmpPath := s.path + ".tmp"
-	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+	if err := writeFileSync(tmpPath, data); err != nil {
+		os.Remove(tmpPath)
 		return errors.WrapError(errors.ErrConfig, "failed to write metadata file", err).
 			WithDetail("path", tmpPath)
 	}
 
+	// Keep the current file as the newest backup generation
+	if rotate {
+		if err := s.rotate(); err != nil {
+			os.Remove(tmpPath)
+			return errors.WrapError(errors.ErrConfig, "failed to rotate metadata backups", err).
+				WithDetail("path", s.path)
+		}
+	}
+
 	// Atomically rename to final path
 	if err := os.Rename(tmpPath, s.path); err != nil {
 		os.Remove(tmpPath) // Clean up temp file
@@ -230,109 +402,148 @@ func (s *store) Save(metadata Metadata) error {
 			WithDetail("path", s.path)
 	}
 
+	// Persist the rename itself
+	if err := syncDir(filepath.Dir(s.path)); err != nil {
+		return errors.WrapError(errors.ErrConfig, "failed to sync metadata directory", err).
+			WithDetail("path", filepath.Dir(s.path))
+	}
+
 	return nil
 }
 
 // Set updates a single workspace
 func (s *store) Set(id string, meta WorkspaceMetadata) error {
-	metadata, err := s.Load()
-	if err != nil {
-		return err
-	}
-
-	// Update workspace
-	meta.ID = id
-	meta.UpdatedAt = time.Now()
-	metadata.Workspaces[id] = meta
-
-	// Update indexes
-	s.updateIndexesForWorkspace(&metadata.Index, meta)
-
-	return s.Save(metadata)
+	return s.Update(func(metadata *Metadata) error {
+		meta.ID = id
+		meta.UpdatedAt = time.Now()
+		metadata.SetWorkspace(meta)
+		return nil
+	})
 }
 
 // Delete removes a workspace
 func (s *store) Delete(id string) error {
-	metadata, err := s.Load()
-	if err != nil {
-		return err
+	return s.Update(func(metadata *Metadata) error {
+		return metadata.DeleteWorkspace(id)
+	})
+}
+
+// SetWorkspace adds or replaces a workspace and updates the indexes
+func (m *Metadata) SetWorkspace(ws WorkspaceMetadata) {
+	// Drop index entries for the previous path/name so renames and moves
+	// don't leave stale lookups behind
+	if prev, ok := m.Workspaces[ws.ID]; ok {
+		if m.Index.ByPath[prev.Path] == ws.ID {
+			delete(m.Index.ByPath, prev.Path)
+		}
+		if m.Index.ByName[prev.Name] == ws.ID {
+			delete(m.Index.ByName, prev.Name)
+		}
+		removeFromBranchIndex(&m.Index, prev.Branch, ws.ID)
 	}
 
-	ws, ok := metadata.Workspaces[id]
+	m.Workspaces[ws.ID] = ws
+	updateIndexesForWorkspace(&m.Index, ws)
+}
+
+// DeleteWorkspace removes a workspace and its index entries
+func (m *Metadata) DeleteWorkspace(id string) error {
+	ws, ok := m.Workspaces[id]
 	if !ok {
 		return errors.NewError(errors.ErrNotFound, "workspace not found").
 			WithDetail("id", id)
 	}
 
 	// Remove from workspaces
-	delete(metadata.Workspaces, id)
+	delete(m.Workspaces, id)
 
 	// Clean up indexes
-	delete(metadata.Index.ByPath, ws.Path)
-	delete(metadata.Index.ByName, ws.Name)
+	if m.Index.ByPath[ws.Path] == id {
+		delete(m.Index.ByPath, ws.Path)
+	}
+	if m.Index.ByName[ws.Name] == id {
+		delete(m.Index.ByName, ws.Name)
+	}
 
-	// Remove from branch index (need to rebuild to clean properly)
-	s.rebuildBranchIndex(&metadata)
+	removeFromBranchIndex(&m.Index, ws.Branch, id)
 
-	return s.Save(metadata)
+	return nil
 }
 
 // RebuildIndex rebuilds all indexes from workspace data
 func (s *store) RebuildIndex() error {
-	metadata, err := s.Load()
-	if err != nil {
-		return err
-	}
-
-	// Clear existing indexes
-	metadata.Index = Index{
-		ByPath:   make(map[string]string),
-		ByName:   make(map[string]string),
-		ByBranch: make(map[string][]string),
-	}
-
-	// Rebuild from workspaces
-	for id, ws := range metadata.Workspaces {
-		s.updateIndexesForWorkspace(&metadata.Index, ws)
-
-		// Detect duplicates
-		if existingID, ok := metadata.Index.ByPath[ws.Path]; ok && existingID != id {
-			// Log warning but continue
-			_ = errors.NewError(errors.ErrBroken, "duplicate path in metadata").
-				WithDetail("path", ws.Path).
-				WithDetail("id1", existingID).
-				WithDetail("id2", id)
+	return s.Update(func(metadata *Metadata) error {
+		// Clear existing indexes
+		metadata.Index = Index{
+			ByPath:   make(map[string]string),
+			ByName:   make(map[string]string),
+			ByBranch: make(map[string][]string),
 		}
 
-		if existingID, ok := metadata.Index.ByName[ws.Name]; ok && existingID != id {
-			// Log warning but continue
-			_ = errors.NewError(errors.ErrBroken, "duplicate name in metadata").
-				WithDetail("name", ws.Name).
-				WithDetail("id1", existingID).
-				WithDetail("id2", id)
+		// Rebuild from workspaces
+		for id, ws := range metadata.Workspaces {
+			updateIndexesForWorkspace(&metadata.Index, ws)
+
+			// Detect duplicates
+			if existingID, ok := metadata.Index.ByPath[ws.Path]; ok && existingID != id {
+				// Log warning but continue
+				_ = errors.NewError(errors.ErrBroken, "duplicate path in metadata").
+					WithDetail("path", ws.Path).
+					WithDetail("id1", existingID).
+					WithDetail("id2", id)
+			}
+
+			if existingID, ok := metadata.Index.ByName[ws.Name]; ok && existingID != id {
+				// Log warning but continue
+				_ = errors.NewError(errors.ErrBroken, "duplicate name in metadata").
+					WithDetail("name", ws.Name).
+					WithDetail("id1", existingID).
+					WithDetail("id2", id)
+			}
 		}
-	}
 
-	return s.Save(metadata)
+		return nil
+	})
 }
 
 // updateIndexesForWorkspace updates indexes for a single workspace
-func (s *store) updateIndexesForWorkspace(index *Index, ws WorkspaceMetadata) {
+func updateIndexesForWorkspace(index *Index, ws WorkspaceMetadata) {
+	// Trashed workspaces free their path and name for reuse
+	if ws.Trash != nil {
+		return
+	}
+
 	// Update path index
 	index.ByPath[ws.Path] = ws.ID
 
 	// Update name index
 	index.ByName[ws.Name] = ws.ID
 
-	// Note: Branch index would need branch information from workspace
-	// For now, we don't have branch info in WorkspaceMetadata
-	// This would be populated when integrating with the git wrapper
+	// Update branch index
+	if ws.Branch == "" {
+		return
+	}
+	for _, id := range index.ByBranch[ws.Branch] {
+		if id == ws.ID {
+			return
+		}
+	}
+	index.ByBranch[ws.Branch] = append(index.ByBranch[ws.Branch], ws.ID)
 }
 
-// rebuildBranchIndex rebuilds only the branch index
-func (s *store) rebuildBranchIndex(metadata *Metadata) {
-	metadata.Index.ByBranch = make(map[string][]string)
+// removeFromBranchIndex drops id from the workspaces listed for branch
+func removeFromBranchIndex(index *Index, branch, id string) {
+	ids := index.ByBranch[branch]
+	for i, existing := range ids {
+		if existing == id {
+			ids = append(ids[:i:i], ids[i+1:]...)
+			break
+		}
+	}
 
-	// This would be populated when we integrate with git wrapper
-	// to get actual branch information for each workspace
+	if len(ids) == 0 {
+		delete(index.ByBranch, branch)
+	} else {
+		index.ByBranch[branch] = ids
+	}
 }
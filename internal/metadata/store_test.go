@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -31,8 +32,8 @@ func TestNewStore(t *testing.T) {
 		t.Fatalf("Failed to load empty metadata: %v", err)
 	}
 
-	if metadata.SchemaVersion != 1 {
-		t.Errorf("Expected schema version 1, got %d", metadata.SchemaVersion)
+	if metadata.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", CurrentSchemaVersion, metadata.SchemaVersion)
 	}
 
 	if len(metadata.Workspaces) != 0 {
@@ -83,8 +84,8 @@ func TestSaveLoad(t *testing.T) {
 	}
 
 	// Verify
-	if loaded.SchemaVersion != 1 {
-		t.Errorf("Expected schema version 1, got %d", loaded.SchemaVersion)
+	if loaded.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", CurrentSchemaVersion, loaded.SchemaVersion)
 	}
 
 	if len(loaded.Workspaces) != 1 {
@@ -190,6 +191,43 @@ func TestFindByName(t *testing.T) {
 	}
 }
 
+func TestSetReplacesStaleIndexEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	gitDir := filepath.Join(tmpDir, ".git")
+	store, _ := NewStore(gitDir)
+
+	id := uuid.New().String()
+
+	workspace := WorkspaceMetadata{
+		ID:        id,
+		Name:      "before",
+		Path:      "/tmp/before",
+		Flags:     make(map[string]bool),
+		CreatedAt: time.Now(),
+	}
+	store.Set(id, workspace)
+
+	// Move and rename
+	workspace.Name = "after"
+	workspace.Path = "/tmp/after"
+	store.Set(id, workspace)
+
+	if _, err := store.FindByPath("/tmp/before"); err == nil {
+		t.Error("Old path should no longer be indexed")
+	}
+	if _, err := store.FindByName("before"); err == nil {
+		t.Error("Old name should no longer be indexed")
+	}
+
+	found, err := store.FindByPath("/tmp/after")
+	if err != nil {
+		t.Fatalf("Failed to find workspace by new path: %v", err)
+	}
+	if found.ID != id {
+		t.Errorf("Expected ID %q, got %q", id, found.ID)
+	}
+}
+
 func TestFindByPath(t *testing.T) {
 	tmpDir := t.TempDir()
 	gitDir := filepath.Join(tmpDir, ".git")
@@ -281,6 +319,46 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestFindByBranch(t *testing.T) {
+	tmpDir := t.TempDir()
+	gitDir := filepath.Join(tmpDir, ".git")
+	store, _ := NewStore(gitDir)
+
+	workspace := WorkspaceMetadata{
+		ID:      "ws1",
+		Name:    "feature",
+		Path:    "/tmp/feature",
+		Branch:  "feature/a",
+		HeadSHA: "abc123",
+		Flags:   make(map[string]bool),
+	}
+	store.Set(workspace.ID, workspace)
+
+	found, err := store.FindByBranch("feature/a")
+	if err != nil {
+		t.Fatalf("FindByBranch() failed: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != "ws1" || found[0].HeadSHA != "abc123" {
+		t.Fatalf("FindByBranch() = %+v", found)
+	}
+
+	// Switching branches moves the index entry
+	workspace.Branch = "feature/b"
+	store.Set(workspace.ID, workspace)
+	if found, _ := store.FindByBranch("feature/a"); len(found) != 0 {
+		t.Errorf("Stale branch index entry: %+v", found)
+	}
+	if found, _ := store.FindByBranch("feature/b"); len(found) != 1 {
+		t.Errorf("FindByBranch(feature/b) = %+v", found)
+	}
+
+	store.Delete(workspace.ID)
+	metadata, _ := store.Load()
+	if len(metadata.Index.ByBranch) != 0 {
+		t.Errorf("Branch index should be empty after delete, got %v", metadata.Index.ByBranch)
+	}
+}
+
 func TestAtomicWrite(t *testing.T) {
 	tmpDir := t.TempDir()
 	gitDir := filepath.Join(tmpDir, ".git")
@@ -314,8 +392,8 @@ func TestAtomicWrite(t *testing.T) {
 		t.Fatalf("Failed to reload saved metadata: %v", err)
 	}
 
-	if loaded.SchemaVersion != 1 {
-		t.Errorf("Expected schema version 1 after reload, got %d", loaded.SchemaVersion)
+	if loaded.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("Expected schema version %d after reload, got %d", CurrentSchemaVersion, loaded.SchemaVersion)
 	}
 }
 
@@ -486,3 +564,63 @@ func TestCorruptedMetadata(t *testing.T) {
 		t.Errorf("Expected error code %s, got %s", errors.ErrConfig, coreErr.Code)
 	}
 }
+
+func TestUpdateAbortsOnError(t *testing.T) {
+	tmpDir := t.TempDir()
+	gitDir := filepath.Join(tmpDir, ".git")
+	store, _ := NewStore(gitDir)
+
+	err := store.Update(func(metadata *Metadata) error {
+		metadata.SetWorkspace(WorkspaceMetadata{ID: "ws1", Name: "discarded", Path: "/tmp/discarded"})
+		return errors.NewError(errors.ErrPolicy, "refused")
+	})
+	if err == nil {
+		t.Fatal("Expected error from Update")
+	}
+
+	if _, err := store.Get("ws1"); err == nil {
+		t.Error("Changes should not be saved when fn fails")
+	}
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	tmpDir := t.TempDir()
+	gitDir := filepath.Join(tmpDir, ".git")
+
+	// Separate store instances stand in for separate processes
+	const writers = 20
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		go func(i int) {
+			store, err := NewStore(gitDir)
+			if err != nil {
+				errs <- err
+				return
+			}
+			id := uuid.New().String()
+			errs <- store.Set(id, WorkspaceMetadata{
+				Name:  fmt.Sprintf("ws-%d", i),
+				Path:  fmt.Sprintf("/tmp/ws-%d", i),
+				Flags: make(map[string]bool),
+			})
+		}(i)
+	}
+
+	for i := 0; i < writers; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("Set() failed: %v", err)
+		}
+	}
+
+	store, _ := NewStore(gitDir)
+	metadata, err := store.Load()
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if len(metadata.Workspaces) != writers {
+		t.Errorf("Expected %d workspaces, got %d (lost updates)", writers, len(metadata.Workspaces))
+	}
+	if len(metadata.Index.ByName) != writers {
+		t.Errorf("Expected %d name index entries, got %d", writers, len(metadata.Index.ByName))
+	}
+}
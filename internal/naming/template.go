@@ -0,0 +1,242 @@
+package naming
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bmf/yagwt/internal/errors"
+)
+
+// DefaultTicketPattern matches tracker keys such as "ABC-123"
+const DefaultTicketPattern = `[A-Z][A-Z0-9]+-[0-9]+`
+
+// Vars holds the values available to name and path templates
+type Vars struct {
+	Name   string // resolved workspace name (path templates only)
+	Branch string // branch without refs/heads/
+	Slug   string // last branch segment without ticket, slugified
+	Ticket string // ticket key extracted from the branch
+	Repo   string // repository directory name
+	User   string // current user
+	Date   string // creation date, YYYY-MM-DD
+}
+
+// NewVars builds template variables for a branch. If ticketPattern has a
+// capture group, the first group is used as the ticket.
+func NewVars(branch, repoRoot string, ticketPattern *regexp.Regexp) Vars {
+	branch = strings.TrimPrefix(branch, "refs/heads/")
+
+	vars := Vars{
+		Branch: branch,
+		Repo:   filepath.Base(repoRoot),
+		User:   currentUser(),
+		Date:   time.Now().Format("2006-01-02"),
+	}
+
+	segment := branch
+	if i := strings.LastIndex(segment, "/"); i >= 0 {
+		segment = segment[i+1:]
+	}
+
+	if ticketPattern != nil {
+		if m := ticketPattern.FindStringSubmatch(branch); m != nil {
+			vars.Ticket = m[0]
+			if len(m) > 1 && m[1] != "" {
+				vars.Ticket = m[1]
+			}
+			segment = strings.Replace(segment, m[0], "", 1)
+		}
+	}
+
+	vars.Slug = Slugify(segment)
+	if vars.Slug == "" {
+		vars.Slug = Slugify(branch)
+	}
+
+	return vars
+}
+
+// Slugify lowercases s and collapses every run of characters other than
+// letters and digits into a single "-"
+func Slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// RenderName renders a workspace name template. Slashes become "-" and
+// leading/trailing separators left by empty variables are trimmed.
+func RenderName(tmpl string, vars Vars) (string, error) {
+	out, err := render(tmpl, vars)
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.Trim(strings.ReplaceAll(out, "/", "-"), "-_. ")
+	if name == "" {
+		return "", errors.NewError(errors.ErrConfig, "name template rendered an empty name").
+			WithDetail("template", tmpl).
+			WithHint("Pass an explicit name", "yagwt new <target> --name <name>")
+	}
+
+	return name, nil
+}
+
+// RenderPath renders a workspace directory template into a relative path.
+// Slashes create subdirectories; absolute paths and ".." are rejected.
+func RenderPath(tmpl string, vars Vars) (string, error) {
+	out, err := render(tmpl, vars)
+	if err != nil {
+		return "", err
+	}
+
+	var parts []string
+	for _, part := range strings.Split(out, "/") {
+		part = strings.Trim(part, "-_ ")
+		if part == "" || part == "." {
+			continue
+		}
+		if part == ".." {
+			return "", errors.NewError(errors.ErrConfig, "path template must not escape the workspace root").
+				WithDetail("template", tmpl).
+				WithDetail("rendered", out)
+		}
+		parts = append(parts, part)
+	}
+
+	if len(parts) == 0 {
+		return "", errors.NewError(errors.ErrConfig, "path template rendered an empty path").
+			WithDetail("template", tmpl)
+	}
+
+	return filepath.Join(parts...), nil
+}
+
+// Validate checks that a template parses and only uses known variables
+// and filters
+func Validate(tmpl string) error {
+	_, err := render(tmpl, Vars{})
+	return err
+}
+
+// render expands {var} and {var|filter|filter:arg} placeholders
+func render(tmpl string, vars Vars) (string, error) {
+	var b strings.Builder
+	rest := tmpl
+
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			b.WriteString(rest)
+			break
+		}
+		b.WriteString(rest[:start])
+
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", errors.NewError(errors.ErrConfig, "unterminated placeholder in template").
+				WithDetail("template", tmpl)
+		}
+
+		value, err := expand(rest[start+1:start+end], vars)
+		if err != nil {
+			return "", err.WithDetail("template", tmpl)
+		}
+		b.WriteString(value)
+
+		rest = rest[start+end+1:]
+	}
+
+	return b.String(), nil
+}
+
+// expand evaluates a single placeholder body such as "branch|lower"
+func expand(expr string, vars Vars) (string, *errors.Error) {
+	parts := strings.Split(expr, "|")
+
+	value, ok := lookup(strings.TrimSpace(parts[0]), vars)
+	if !ok {
+		return "", errors.NewError(errors.ErrConfig, "unknown template variable").
+			WithDetail("variable", parts[0]).
+			WithDetail("valid", "name, branch, slug, ticket, repo, user, date")
+	}
+
+	for _, f := range parts[1:] {
+		name, arg, _ := strings.Cut(strings.TrimSpace(f), ":")
+
+		switch name {
+		case "lower", "lowercase":
+			value = strings.ToLower(value)
+		case "upper", "uppercase":
+			value = strings.ToUpper(value)
+		case "slug":
+			value = Slugify(value)
+		case "truncate":
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 0 {
+				return "", errors.NewError(errors.ErrConfig, "truncate filter requires a length").
+					WithDetail("filter", f)
+			}
+			if r := []rune(value); len(r) > n {
+				value = strings.TrimRight(string(r[:n]), "-_.")
+			}
+		case "default":
+			if value == "" {
+				value = arg
+			}
+		default:
+			return "", errors.NewError(errors.ErrConfig, "unknown template filter").
+				WithDetail("filter", name).
+				WithDetail("valid", "lower, upper, slug, truncate:N, default:VALUE")
+		}
+	}
+
+	return value, nil
+}
+
+func lookup(name string, vars Vars) (string, bool) {
+	switch name {
+	case "name":
+		return vars.Name, true
+	case "branch":
+		return vars.Branch, true
+	case "slug":
+		return vars.Slug, true
+	case "ticket":
+		return vars.Ticket, true
+	case "repo":
+		return vars.Repo, true
+	case "user":
+		return vars.User, true
+	case "date":
+		return vars.Date, true
+	default:
+		return "", false
+	}
+}
+
+func currentUser() string {
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
@@ -0,0 +1,187 @@
+package naming
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/bmf/yagwt/internal/errors"
+)
+
+func TestNewVars(t *testing.T) {
+	ticketRe := regexp.MustCompile(DefaultTicketPattern)
+
+	tests := []struct {
+		name       string
+		branch     string
+		wantBranch string
+		wantTicket string
+		wantSlug   string
+	}{
+		{
+			name:       "ticket and description",
+			branch:     "feature/ABC-123-Login-Page",
+			wantBranch: "feature/ABC-123-Login-Page",
+			wantTicket: "ABC-123",
+			wantSlug:   "login-page",
+		},
+		{
+			name:       "no ticket",
+			branch:     "fix/flaky_tests",
+			wantBranch: "fix/flaky_tests",
+			wantTicket: "",
+			wantSlug:   "flaky-tests",
+		},
+		{
+			name:       "full ref",
+			branch:     "refs/heads/main",
+			wantBranch: "main",
+			wantTicket: "",
+			wantSlug:   "main",
+		},
+		{
+			name:       "ticket only",
+			branch:     "PROJ-42",
+			wantBranch: "PROJ-42",
+			wantTicket: "PROJ-42",
+			wantSlug:   "proj-42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := NewVars(tt.branch, "/src/myrepo", ticketRe)
+			if vars.Branch != tt.wantBranch {
+				t.Errorf("Branch = %q, want %q", vars.Branch, tt.wantBranch)
+			}
+			if vars.Ticket != tt.wantTicket {
+				t.Errorf("Ticket = %q, want %q", vars.Ticket, tt.wantTicket)
+			}
+			if vars.Slug != tt.wantSlug {
+				t.Errorf("Slug = %q, want %q", vars.Slug, tt.wantSlug)
+			}
+			if vars.Repo != "myrepo" {
+				t.Errorf("Repo = %q, want %q", vars.Repo, "myrepo")
+			}
+		})
+	}
+}
+
+func TestNewVarsTicketCaptureGroup(t *testing.T) {
+	vars := NewVars("bug/gh-512-crash", "/src/repo", regexp.MustCompile(`gh-([0-9]+)`))
+
+	if vars.Ticket != "512" {
+		t.Errorf("Ticket = %q, want %q", vars.Ticket, "512")
+	}
+
+	if vars.Slug != "crash" {
+		t.Errorf("Slug = %q, want %q", vars.Slug, "crash")
+	}
+}
+
+func TestRenderName(t *testing.T) {
+	vars := Vars{
+		Branch: "feature/ABC-123-login-page",
+		Slug:   "login-page",
+		Ticket: "ABC-123",
+		Repo:   "myrepo",
+		User:   "alex",
+		Date:   "2025-01-02",
+	}
+
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{"{branch}", "feature-ABC-123-login-page"},
+		{"{ticket}-{slug}", "ABC-123-login-page"},
+		{"{ticket|lower}-{slug}", "abc-123-login-page"},
+		{"{user}/{slug|upper}", "alex-LOGIN-PAGE"},
+		{"{repo}-{date}", "myrepo-2025-01-02"},
+		{"{slug|truncate:5}", "login"},
+		{"{slug|truncate:6}", "login"},
+		{"{branch|slug}", "feature-abc-123-login-page"},
+		{"wip-{slug}", "wip-login-page"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			got, err := RenderName(tt.tmpl, vars)
+			if err != nil {
+				t.Fatalf("RenderName(%q) failed: %v", tt.tmpl, err)
+			}
+			if got != tt.want {
+				t.Errorf("RenderName(%q) = %q, want %q", tt.tmpl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderNameMissingTicket(t *testing.T) {
+	vars := Vars{Slug: "cleanup"}
+
+	got, err := RenderName("{ticket}-{slug}", vars)
+	if err != nil {
+		t.Fatalf("RenderName() failed: %v", err)
+	}
+	if got != "cleanup" {
+		t.Errorf("RenderName() = %q, want %q", got, "cleanup")
+	}
+
+	got, err = RenderName("{ticket|default:NOTICKET}-{slug}", vars)
+	if err != nil {
+		t.Fatalf("RenderName() failed: %v", err)
+	}
+	if got != "NOTICKET-cleanup" {
+		t.Errorf("RenderName() = %q, want %q", got, "NOTICKET-cleanup")
+	}
+
+	if _, err := RenderName("{ticket}", vars); err == nil {
+		t.Error("Expected error for empty rendered name")
+	}
+}
+
+func TestRenderPath(t *testing.T) {
+	vars := Vars{Name: "auth", User: "alex", Repo: "myrepo"}
+
+	got, err := RenderPath("{user}/{repo}-{name}", vars)
+	if err != nil {
+		t.Fatalf("RenderPath() failed: %v", err)
+	}
+	if want := filepath.Join("alex", "myrepo-auth"); got != want {
+		t.Errorf("RenderPath() = %q, want %q", got, want)
+	}
+
+	if _, err := RenderPath("../{name}", vars); err == nil {
+		t.Error("Expected error for path escaping the root")
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		tmpl string
+	}{
+		{"unknown variable", "{nope}"},
+		{"unknown filter", "{branch|reverse}"},
+		{"truncate without length", "{branch|truncate}"},
+		{"unterminated placeholder", "{branch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.tmpl)
+			if err == nil {
+				t.Fatalf("Validate(%q) expected error", tt.tmpl)
+			}
+
+			coreErr, ok := err.(*errors.Error)
+			if !ok {
+				t.Fatalf("Expected *errors.Error, got %T", err)
+			}
+			if coreErr.Code != errors.ErrConfig {
+				t.Errorf("Expected error code %s, got %s", errors.ErrConfig, coreErr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package seed
+
+import "os"
+
+// cloneFile is only implemented on Linux; elsewhere files are copied
+func cloneFile(src, dst string, perm os.FileMode) error {
+	return errCloneUnsupported
+}
@@ -0,0 +1,180 @@
+package seed
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Methods used to bring a file into the destination
+const (
+	MethodReflink  = "reflink"
+	MethodHardlink = "hardlink"
+	MethodCopy     = "copy"
+)
+
+// errCloneUnsupported reports that the filesystem cannot share extents
+var errCloneUnsupported = errors.New("reflinks are not supported")
+
+// Progress reports how far a seed has got
+type Progress struct {
+	Path  string // file just written, relative to the tree root
+	Bytes int64  // bytes done so far
+	Total int64  // bytes in the whole tree
+}
+
+// Options controls how a tree is seeded
+type Options struct {
+	// Fallback is used for files that cannot be reflinked: "copy" (the
+	// default) or "hardlink". Hardlinks share the file with the source,
+	// so writes in either tree show up in the other.
+	Fallback string
+
+	// Progress is called after each file, if set
+	Progress func(Progress)
+}
+
+// Stats summarizes a seeded tree
+type Stats struct {
+	Files      int
+	Bytes      int64
+	Reflinked  int
+	Hardlinked int
+	Copied     int
+}
+
+// Method returns the method used for most files
+func (s Stats) Method() string {
+	switch {
+	case s.Reflinked >= s.Hardlinked && s.Reflinked >= s.Copied && s.Reflinked > 0:
+		return MethodReflink
+	case s.Hardlinked >= s.Copied && s.Hardlinked > 0:
+		return MethodHardlink
+	default:
+		return MethodCopy
+	}
+}
+
+// Tree clones the directory tree at src into dst, which must not exist.
+// Files are reflinked when the filesystem supports it, otherwise they are
+// hardlinked or copied according to opts.Fallback. Symlinks are recreated
+// as they are.
+func Tree(src, dst string, opts Options) (Stats, error) {
+	var stats Stats
+
+	total, err := treeSize(src)
+	if err != nil {
+		return stats, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return stats, err
+	}
+
+	// Once a clone fails the filesystem is not going to start supporting it
+	canClone := true
+
+	err = filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.Mkdir(target, info.Mode().Perm()|0700)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case !info.Mode().IsRegular():
+			// Sockets, fifos and devices have no place in a build cache
+			return nil
+		}
+
+		method := ""
+		if canClone {
+			switch err := cloneFile(path, target, info.Mode().Perm()); {
+			case err == nil:
+				method = MethodReflink
+			case errors.Is(err, errCloneUnsupported):
+				canClone = false
+			default:
+				return err
+			}
+		}
+		if method == "" && opts.Fallback == MethodHardlink {
+			if err := os.Link(path, target); err == nil {
+				method = MethodHardlink
+			}
+		}
+		if method == "" {
+			if err := CopyFile(path, target, info.Mode().Perm()); err != nil {
+				return err
+			}
+			method = MethodCopy
+		}
+
+		switch method {
+		case MethodReflink:
+			stats.Reflinked++
+		case MethodHardlink:
+			stats.Hardlinked++
+		default:
+			stats.Copied++
+		}
+		stats.Files++
+		stats.Bytes += info.Size()
+
+		if opts.Progress != nil {
+			opts.Progress(Progress{Path: rel, Bytes: stats.Bytes, Total: total})
+		}
+		return nil
+	})
+
+	return stats, err
+}
+
+// treeSize returns the total size of the regular files under root
+func treeSize(root string) (int64, error) {
+	var total int64
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
+
+// CopyFile copies a regular file, creating dst with perm. dst must not
+// exist.
+func CopyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
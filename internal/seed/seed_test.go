@@ -0,0 +1,112 @@
+package seed
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTree(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "node_modules")
+	files := map[string]string{
+		"a/index.js":      "module.exports = 1\n",
+		"a/package.json":  "{}\n",
+		"b/lib/deep.js":   "x\n",
+		".bin/.gitignore": "",
+	}
+	writeTree(t, src, files)
+	if err := os.Symlink("../a/index.js", filepath.Join(src, ".bin", "a")); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "ws", "node_modules")
+	var last Progress
+	calls := 0
+	stats, err := Tree(src, dst, Options{Progress: func(p Progress) {
+		calls++
+		last = p
+	}})
+	if err != nil {
+		t.Fatalf("Tree() failed: %v", err)
+	}
+
+	if stats.Files != len(files) || calls != len(files) {
+		t.Errorf("Files = %d, progress calls = %d, want %d", stats.Files, calls, len(files))
+	}
+	if stats.Reflinked+stats.Copied != stats.Files {
+		t.Errorf("Stats = %+v, want only reflinks or copies", stats)
+	}
+	if last.Bytes != last.Total || last.Total != stats.Bytes {
+		t.Errorf("Last progress = %+v, want all %d bytes", last, stats.Bytes)
+	}
+
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil || string(data) != content {
+			t.Errorf("%s = %q, %v; want %q", name, data, err, content)
+		}
+	}
+	if link, err := os.Readlink(filepath.Join(dst, ".bin", "a")); err != nil || link != "../a/index.js" {
+		t.Errorf("Symlink = %q, %v", link, err)
+	}
+
+	// Copies never write through to the source
+	if err := os.WriteFile(filepath.Join(dst, "a", "index.js"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(src, "a", "index.js")); string(data) != files["a/index.js"] {
+		t.Error("Writing the seeded copy changed the source")
+	}
+}
+
+func TestTreeHardlinkFallback(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "target")
+	writeTree(t, src, map[string]string{"debug/app": "binary"})
+
+	dst := filepath.Join(dir, "ws", "target")
+	stats, err := Tree(src, dst, Options{Fallback: MethodHardlink})
+	if err != nil {
+		t.Fatalf("Tree() failed: %v", err)
+	}
+	if stats.Reflinked > 0 {
+		t.Skip("filesystem supports reflinks; fallback not exercised")
+	}
+
+	if stats.Hardlinked != 1 || stats.Method() != MethodHardlink {
+		t.Errorf("Stats = %+v, want one hardlink", stats)
+	}
+	srcInfo, _ := os.Stat(filepath.Join(src, "debug", "app"))
+	dstInfo, _ := os.Stat(filepath.Join(dst, "debug", "app"))
+	if !os.SameFile(srcInfo, dstInfo) {
+		t.Error("Expected the seeded file to be a hardlink")
+	}
+}
+
+func TestTreeRefusesExistingDestination(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	writeTree(t, src, map[string]string{"f": "x"})
+
+	dst := filepath.Join(dir, "dst")
+	if err := os.Mkdir(dst, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Tree(src, dst, Options{}); err == nil {
+		t.Error("Tree() should fail when the destination exists")
+	}
+}